@@ -0,0 +1,38 @@
+package bootstrap
+
+import (
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+// removeStaleLeaseRecords 删除上一轮由 DHCP 租约批量记录展开、本轮已不存在的主机记录（租约过期或主机下线）。
+// groups 为展开前的配置组；读取租约失败的配置组沿用上一轮的主机，不做删除
+func (r *Runner) removeStaleLeaseRecords(groups, expanded []config.DNSGroup, unreadable map[string]bool) {
+	parents := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		parents[group.ID] = true
+	}
+
+	// 展开生成的主机配置组 ID 为 <配置组 ID>/<主机名>，其余配置组的域名不会被当作租约记录删除
+	var hosts, others []config.DNSGroup
+	current := make(map[string]bool)
+	for _, group := range expanded {
+		if parents[group.ID] {
+			others = append(others, group)
+			continue
+		}
+		hosts = append(hosts, group)
+		current[group.ID] = true
+	}
+	for _, group := range r.leaseRecords.Groups {
+		if current[group.ID] {
+			continue
+		}
+		if i := strings.LastIndex(group.ID, "/"); i >= 0 && unreadable[group.ID[:i]] {
+			hosts = append(hosts, group)
+		}
+	}
+
+	r.removeStale(&r.leaseRecords, hosts, configuredDomains(others), "DHCP 租约已过期或主机已下线")
+}
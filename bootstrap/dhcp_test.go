@@ -0,0 +1,84 @@
+package bootstrap
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/history"
+)
+
+func TestProcessDDNSServices_RemovesExpiredLeaseHosts(t *testing.T) {
+	prevForceCompare := ddns.ForceCompareGlobal
+	defer func() {
+		ddns.ForceCompareGlobal = prevForceCompare
+	}()
+
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	file := filepath.Join(t.TempDir(), "dhcp.leases")
+	writeLeases := func(content string) {
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeLeases(expires + " aa:bb:cc:dd:ee:01 192.168.1.10 nas *\n" +
+		expires + " aa:bb:cc:dd:ee:02 192.168.1.11 tv *\n")
+
+	store, err := history.NewStore("", 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	runner := NewRunner(nil)
+	runner.UseHistory(store)
+	conf := &config.Config{
+		DDNSConfig: config.DDNSConfig{
+			DDNSEnabled: true,
+			DDNS: []config.DNSGroup{{
+				ID: "lan", Domain: "lan.example.com", Service: ddns.ProviderMock,
+				Records: []config.DNSRecord{{Type: ddns.RecordTypeA, IPType: helper.DynamicIPv4DHCP, Value: "*@" + file}},
+			}},
+		},
+	}
+	removed := func() []string {
+		var domains []string
+		for _, entry := range store.Query(history.Query{Status: ddns.RemovedSuccess}).Items {
+			domains = append(domains, entry.Domain)
+		}
+		return domains
+	}
+
+	ddns.ForceCompareGlobal = true
+	runner.processDDNSServices(conf, nil)
+	if got := removed(); len(got) != 0 {
+		t.Fatalf("expected no removals on first run, got %v", got)
+	}
+
+	// tv 的租约过期后删除其记录
+	writeLeases(expires + " aa:bb:cc:dd:ee:01 192.168.1.10 nas *\n")
+	runner.processDDNSServices(conf, nil)
+	if got := removed(); len(got) != 1 || got[0] != "tv.lan.example.com" {
+		t.Fatalf("expected record of expired lease to be removed, got %v", got)
+	}
+
+	// 租约文件读取失败时不删除
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	runner.processDDNSServices(conf, nil)
+	if got := removed(); len(got) != 1 {
+		t.Fatalf("expected no removals while leases are unreadable, got %v", got)
+	}
+
+	// 主机名改由配置文件管理时不删除
+	writeLeases("")
+	conf.DDNSConfig.DDNS = append(conf.DDNSConfig.DDNS, config.DNSGroup{ID: "nas", Domain: "nas.lan.example.com", Service: ddns.ProviderMock})
+	runner.processDDNSServices(conf, nil)
+	if got := removed(); len(got) != 1 {
+		t.Fatalf("record of configured domain should not be removed, got %v", got)
+	}
+}
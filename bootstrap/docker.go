@@ -0,0 +1,68 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+const (
+	// dockerDebounce 容器事件后的等待时间，合并 compose 同时启动多个容器产生的连续事件
+	dockerDebounce = 3 * time.Second
+	// dockerRetryInterval 未启用发现或连接断开后的重试间隔
+	dockerRetryInterval = 30 * time.Second
+)
+
+// discoverDockerGroups 列出带 dnet.ddns.domain 标签的容器并生成配置组；
+// 开启 remove_stopped 时删除上一轮存在、本轮已消失的容器记录（配置文件接管的域名除外），
+// 删除失败的记录下一轮重试。Docker 不可用时沿用上一轮结果，不做删除
+func (r *Runner) discoverDockerGroups(conf *config.Config) []config.DNSGroup {
+	discovery := conf.DDNSConfig.Docker
+	if discovery == nil || !discovery.Enabled {
+		r.dockerRecords = config.GeneratedRecords{}
+		return nil
+	}
+
+	containers, err := helper.ListDockerContainers(discovery.Socket, ddns.DockerLabelDomain)
+	if err != nil {
+		helper.Warn(helper.LogTypeDDNS, "Docker 容器发现失败，沿用上次结果: %v", err)
+		return r.dockerRecords.Groups
+	}
+	discovered := ddns.DockerGroups(*discovery, conf.DDNSConfig.DDNS, containers)
+	if discovery.RemoveStopped {
+		r.removeStale(&r.dockerRecords, discovered, configuredDomains(conf.DDNSConfig.DDNS), "容器已停止或不再声明该域名")
+	} else {
+		r.dockerRecords = config.GeneratedRecords{Groups: discovered}
+	}
+	return discovered
+}
+
+// WatchDocker 启用 Docker 容器发现时订阅容器启动 / 停止事件，事件发生后立即同步 DDNS；
+// 定时同步仍然保留，订阅失败时按间隔重试
+func (r *Runner) WatchDocker() {
+	go func() {
+		var timer *time.Timer
+		for {
+			conf, err := r.repo.Load()
+			discovery := conf.DDNSConfig.Docker
+			if err != nil || !conf.DDNSConfig.DDNSEnabled || discovery == nil || !discovery.Enabled {
+				time.Sleep(dockerRetryInterval)
+				continue
+			}
+
+			helper.Info(helper.LogTypeSystem, "已启用 Docker 容器事件监听")
+			err = helper.WatchDockerEvents(context.Background(), discovery.Socket, ddns.DockerLabelDomain, func(event helper.DockerEvent) {
+				helper.Debug(helper.LogTypeDDNS, "收到容器事件 [动作=%s, 容器=%s]", event.Action, event.Actor.Attributes["name"])
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(dockerDebounce, r.SyncDDNSOnce)
+			})
+			helper.Warn(helper.LogTypeSystem, "Docker 容器事件监听已停止，%s 后重试: %v", dockerRetryInterval, err)
+			time.Sleep(dockerRetryInterval)
+		}
+	}()
+}
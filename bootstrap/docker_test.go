@@ -0,0 +1,204 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/ddns"
+)
+
+func TestProcessDDNSServices_DockerDiscovery(t *testing.T) {
+	prevForceCompare := ddns.ForceCompareGlobal
+	defer func() {
+		ddns.ForceCompareGlobal = prevForceCompare
+	}()
+
+	// 模拟 Docker Engine API：running 为 false 时容器已停止，列表为空
+	var mu sync.Mutex
+	running := true
+	dir, err := os.MkdirTemp("", "dnet-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix socket unavailable: %v", err)
+	}
+	docker := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !running {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"Id":"c1","Names":["/web"],"Labels":{"dnet.ddns.domain":"app.example.com"},
+			"NetworkSettings":{"Networks":{"bridge":{"IPAddress":"172.17.0.2"}}}}]`))
+	})}
+	go docker.Serve(listener)
+	defer docker.Close()
+
+	// 模拟 Cloudflare API，记录创建与删除请求；failDelete 为 true 时删除失败
+	var created, deleted []string
+	failDelete := false
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/zones":
+			w.Write([]byte(`{"success":true,"result":[{"id":"zone1"}]}`))
+		case r.Method == http.MethodGet:
+			if r.URL.Query().Get("name") == "app.example.com" && len(created) > 0 {
+				w.Write([]byte(`{"success":true,"result":[{"id":"rec1","type":"A","name":"app.example.com","content":"172.17.0.2"}]}`))
+				return
+			}
+			w.Write([]byte(`{"success":true,"result":[]}`))
+		case r.Method == http.MethodPost:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body["name"].(string)+"="+body["content"].(string))
+			w.Write([]byte(`{"success":true,"result":{"id":"rec1"}}`))
+		case r.Method == http.MethodDelete && failDelete:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"internal error"}]}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/zones/zone1/dns_records/"))
+			w.Write([]byte(`{"success":true,"result":{"id":"rec1"}}`))
+		}
+	}))
+	defer provider.Close()
+
+	conf := &config.Config{
+		DDNSConfig: config.DDNSConfig{
+			DDNSEnabled: true,
+			DDNS: []config.DNSGroup{
+				{ID: "cf", Domain: "home.example.com", Service: ddns.ProviderCloudflare, AccessKey: "token", Endpoint: provider.URL},
+			},
+			Docker: &config.DockerDiscovery{Enabled: true, Socket: socket, Group: "cf", RemoveStopped: true},
+		},
+	}
+
+	runner := NewRunner(nil)
+	ddns.ForceCompareGlobal = true
+	runner.processDDNSServices(conf, nil)
+	if len(created) != 1 || created[0] != "app.example.com=172.17.0.2" {
+		t.Fatalf("expected record for running container to be created, got %v", created)
+	}
+	if len(conf.DDNSConfig.DDNS) != 1 {
+		t.Fatalf("discovered groups should not be written back to config, got %d groups", len(conf.DDNSConfig.DDNS))
+	}
+
+	// 配置文件接管该域名后，容器停止也不删除记录
+	configured := conf.DDNSConfig.DDNS
+	conf.DDNSConfig.DDNS = append(configured, config.DNSGroup{ID: "app", Domain: "App.example.com", Service: ddns.ProviderCloudflare, AccessKey: "token", Endpoint: provider.URL})
+	mu.Lock()
+	running = false
+	mu.Unlock()
+	runner.processDDNSServices(conf, nil)
+	if len(deleted) != 0 {
+		t.Fatalf("record of configured domain should not be deleted, got %v", deleted)
+	}
+	conf.DDNSConfig.DDNS = configured
+	mu.Lock()
+	running = true
+	mu.Unlock()
+	runner.processDDNSServices(conf, nil)
+
+	// 删除失败的记录在下一轮重试
+	mu.Lock()
+	running = false
+	failDelete = true
+	mu.Unlock()
+	runner.processDDNSServices(conf, nil)
+	if len(deleted) != 0 {
+		t.Fatalf("expected failed delete, got %v", deleted)
+	}
+	mu.Lock()
+	failDelete = false
+	mu.Unlock()
+	runner.processDDNSServices(conf, nil)
+	if len(deleted) != 1 || deleted[0] != "rec1" {
+		t.Fatalf("expected record of stopped container to be deleted on retry, got %v", deleted)
+	}
+
+	// 已删除的记录不会重复删除
+	runner.processDDNSServices(conf, nil)
+	if len(deleted) != 1 {
+		t.Errorf("expected no further deletes, got %v", deleted)
+	}
+}
+
+func TestRemoveStale_DropsUnsupportedProvider(t *testing.T) {
+	runner := NewRunner(nil)
+	tracked := config.GeneratedRecords{Groups: []config.DNSGroup{{
+		ID:      "lan/nas",
+		Domain:  "nas.lan.example.com",
+		Service: ddns.ProviderCallback,
+		Records: []config.DNSRecord{{Type: ddns.RecordTypeA}},
+	}}}
+
+	// 服务商不支持删除时提示一次即可，不应留在待删除列表中反复重试
+	runner.removeStale(&tracked, nil, nil, "test")
+	if len(tracked.Pending) != 0 || len(tracked.Groups) != 0 {
+		t.Errorf("unsupported provider should not be retried, got pending %+v", tracked.Pending)
+	}
+}
+
+func TestUseStateStore_RestoresGeneratedRecords(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/zones":
+			w.Write([]byte(`{"success":true,"result":[{"id":"zone1"}]}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"success":true,"result":[{"id":"rec1","type":"A","name":"app.example.com","content":"172.17.0.2"}]}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/zones/zone1/dns_records/"))
+			w.Write([]byte(`{"success":true,"result":{"id":"rec1"}}`))
+		}
+	}))
+	defer provider.Close()
+	statePath := filepath.Join(t.TempDir(), "dnet.state.json")
+
+	first := NewRunner(nil)
+	first.UseStateStore(config.NewFileStateStore(statePath))
+	first.dockerRecords = config.GeneratedRecords{Groups: []config.DNSGroup{{
+		ID:        "docker/web",
+		Domain:    "app.example.com",
+		Service:   ddns.ProviderCloudflare,
+		AccessKey: "token",
+		Endpoint:  provider.URL,
+		Records:   []config.DNSRecord{{Type: ddns.RecordTypeA}},
+	}}}
+	first.saveDDNSState()
+
+	// 模拟重启：D-NET 未运行期间容器已停止，重启后仍能删除上次生成的记录
+	second := NewRunner(nil)
+	second.UseStateStore(config.NewFileStateStore(statePath))
+	if len(second.dockerRecords.Groups) != 1 {
+		t.Fatalf("expected generated groups restored, got %+v", second.dockerRecords)
+	}
+	second.removeStale(&second.dockerRecords, nil, nil, "test")
+	if len(deleted) != 1 || deleted[0] != "rec1" {
+		t.Fatalf("expected restored record to be deleted, got %v", deleted)
+	}
+	second.saveDDNSState()
+
+	third := NewRunner(nil)
+	third.UseStateStore(config.NewFileStateStore(statePath))
+	if len(third.dockerRecords.Groups) != 0 || len(third.dockerRecords.Pending) != 0 {
+		t.Errorf("deleted records should not be tracked after restart, got %+v", third.dockerRecords)
+	}
+}
@@ -0,0 +1,120 @@
+package bootstrap
+
+import (
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// removeStale 删除上一轮生成、本轮已不存在的记录，并记下本轮生成的配置组。
+// 本轮仍以任意配置组生成的域名和类型、以及 claimed 中由配置文件管理的域名不会删除；删除失败的记录下一轮重试
+func (r *Runner) removeStale(tracked *config.GeneratedRecords, current []config.DNSGroup, claimed map[string]bool, reason string) {
+	alive := make(map[string]bool)
+	for _, group := range current {
+		for recordType := range recordTypes(group) {
+			alive[recordKey(group.Domain, recordType)] = true
+		}
+	}
+
+	// 先重试上一轮失败的记录，再处理本轮新消失的记录，同一域名和类型只处理一次
+	seen := make(map[string]bool)
+	var pending []config.DNSGroup
+	candidates := append(append([]config.DNSGroup(nil), tracked.Pending...), tracked.Groups...)
+	for i, group := range candidates {
+		retry := i < len(tracked.Pending)
+		if claimed[strings.ToLower(group.Domain)] {
+			continue
+		}
+		var stale []string
+		for _, record := range group.Records {
+			key := recordKey(group.Domain, record.Type)
+			if alive[key] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			stale = append(stale, record.Type)
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		if failed := r.removeRecords(group, stale, reason, retry); len(failed) > 0 {
+			group.Records = make([]config.DNSRecord, 0, len(failed))
+			for _, recordType := range failed {
+				group.Records = append(group.Records, config.DNSRecord{Type: recordType})
+			}
+			pending = append(pending, group)
+		}
+	}
+
+	tracked.Groups = current
+	tracked.Pending = pending
+}
+
+// generatedState 返回写入状态文件的副本，没有需要跟踪的配置组时为空
+func generatedState(tracked config.GeneratedRecords) *config.GeneratedRecords {
+	if len(tracked.Groups) == 0 && len(tracked.Pending) == 0 {
+		return nil
+	}
+	return &tracked
+}
+
+// removeRecords 通过服务商删除配置组中指定类型的记录，返回删除失败、需要重试的类型；
+// 服务商未注册或不支持删除时提示手动删除，不再重试
+func (r *Runner) removeRecords(group config.DNSGroup, stale []string, reason string, retry bool) []string {
+	dnsSelected, err := ddns.NewProvider(group.Service)
+	if err != nil {
+		helper.Warn(helper.LogTypeDDNS, "%v，请手动删除 [域名=%s, 类型=%v]", err, group.Domain, stale)
+		return nil
+	}
+	remover, ok := dnsSelected.(ddns.RecordRemover)
+	if !ok {
+		// 服务商不支持删除时重试也不会成功，提示一次后不再跟踪
+		helper.Warn(helper.LogTypeDDNS, "[%s] 不支持自动删除记录，请手动删除 [域名=%s, 类型=%v]", dnsSelected.GetServiceName(), group.Domain, stale)
+		return nil
+	}
+
+	if retry {
+		helper.Info(helper.LogTypeDDNS, "重试删除记录 [域名=%s, 类型=%v]", group.Domain, stale)
+	} else {
+		helper.Info(helper.LogTypeDDNS, "%s，删除记录 [域名=%s, 类型=%v]", reason, group.Domain, stale)
+	}
+	dnsSelected.Init(&group, nil)
+	results := remover.RemoveRecords(stale)
+	r.recordDDNSHistory(&group, dnsSelected.GetServiceName(), results)
+
+	var failed []string
+	for _, result := range results {
+		if result.Status != ddns.RemovedSuccess && result.Status != ddns.UpdatedNothing {
+			failed = append(failed, result.RecordType)
+		}
+	}
+	if len(failed) > 0 {
+		helper.Warn(helper.LogTypeDDNS, "删除记录失败，将在下一轮重试 [域名=%s, 类型=%v]", group.Domain, failed)
+	}
+	return failed
+}
+
+// recordTypes 配置组中的记录类型集合
+func recordTypes(group config.DNSGroup) map[string]bool {
+	types := make(map[string]bool, len(group.Records))
+	for _, record := range group.Records {
+		types[record.Type] = true
+	}
+	return types
+}
+
+// recordKey 域名（不区分大小写）与记录类型组成的键
+func recordKey(domain, recordType string) string {
+	return strings.ToLower(domain) + "\x1f" + recordType
+}
+
+// configuredDomains 配置文件中各配置组的域名（小写）
+func configuredDomains(groups []config.DNSGroup) map[string]bool {
+	domains := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		domains[strings.ToLower(group.Domain)] = true
+	}
+	return domains
+}
@@ -12,14 +12,22 @@ import (
 	"github.com/cxbdasheng/dnet/dcdn"
 	"github.com/cxbdasheng/dnet/ddns"
 	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/history"
 )
 
 // Runner serializes sync work and keeps cache state local to the instance.
 type Runner struct {
 	repo       config.Repository
+	store      config.StateStore // 同步状态存储，为空时仅在内存中保留缓存
+	state      config.State      // 最近一次加载/保存的同步状态
+	history    *history.Store    // 同步历史，为空时不记录
 	mu         sync.Mutex
 	dcdnCaches []dcdn.Cache
 	ddnsCaches map[string]*ddns.Cache
+	// 由 Docker 容器标签生成的配置组，用于容器停止后删除记录
+	dockerRecords config.GeneratedRecords
+	// 由 DHCP 租约批量记录展开的配置组，用于租约过期或主机下线后删除记录
+	leaseRecords config.GeneratedRecords
 }
 
 func NewRunner(repo config.Repository) *Runner {
@@ -29,6 +37,47 @@ func NewRunner(repo config.Repository) *Runner {
 	}
 }
 
+// UseStateStore 设置同步状态存储并恢复上次运行的缓存，
+// 使重启后 IP 未变化的记录不再请求服务商、也不触发 webhook
+func (r *Runner) UseStateStore(store config.StateStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store = store
+	state, err := store.Load()
+	if err != nil {
+		helper.Warn(helper.LogTypeSystem, "读取同步状态失败，将重新与服务商比对: %v", err)
+		return
+	}
+	r.state = state
+	// 恢复推送地址，避免重启后在路由器再次推送前 pushed_* 记录一直获取 IP 失败
+	helper.RestorePushedIPs(state.Pushed)
+	// 恢复自动生成的配置组，D-NET 未运行期间停止的容器、过期的租约在本次启动后也能删除
+	if state.Docker != nil {
+		r.dockerRecords = *state.Docker
+	}
+	if state.DHCP != nil {
+		r.leaseRecords = *state.DHCP
+	}
+	if len(state.DDNS) > 0 {
+		ddns.ForceCompareGlobal = false
+	}
+	if len(state.DCDN) > 0 {
+		dcdn.ForceCompareGlobal = false
+	}
+	generated := len(r.dockerRecords.Groups) + len(r.dockerRecords.Pending) + len(r.leaseRecords.Groups) + len(r.leaseRecords.Pending)
+	if len(state.DDNS) > 0 || len(state.DCDN) > 0 || len(state.Pushed) > 0 || generated > 0 {
+		helper.Info(helper.LogTypeSystem, "已恢复同步状态 [DDNS 记录=%d, DCDN 配置=%d, 推送地址=%d, 自动生成配置组=%d]", len(state.DDNS), len(state.DCDN), len(state.Pushed), generated)
+	}
+}
+
+// UseHistory 设置同步历史存储，每轮同步结果都会写入其中
+func (r *Runner) UseHistory(store *history.Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = store
+}
+
 func (r *Runner) RunTimer(nextInterval func() time.Duration) {
 	for {
 		r.RunOnce()
@@ -37,6 +86,41 @@ func (r *Runner) RunTimer(nextInterval func() time.Duration) {
 }
 
 func (r *Runner) RunOnce() {
+	r.syncMatching(nil)
+}
+
+// sourceMatch 判断地址来源（DDNS 记录的 IPType / DCDN 源站的 Type 及其值）是否与触发同步的事件相关，
+// 为空时匹配全部。事件触发的同步只处理相关配置，不消耗其它配置与服务商强制比对前的剩余次数
+type sourceMatch func(sourceType, value string) bool
+
+// matchesGroup 判断配置组是否有记录使用了匹配的地址来源
+func (m sourceMatch) matchesGroup(group *config.DNSGroup) bool {
+	if m == nil {
+		return true
+	}
+	for _, record := range group.Records {
+		if m(record.IPType, record.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCDN 判断 DCDN 配置是否有源站使用了匹配的地址来源
+func (m sourceMatch) matchesCDN(cdn *config.CDN) bool {
+	if m == nil {
+		return true
+	}
+	for _, source := range cdn.Sources {
+		if m(source.Type, source.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncMatching 同步使用了匹配地址来源的 DDNS 配置组和 DCDN 配置，match 为空时同步全部
+func (r *Runner) syncMatching(match sourceMatch) {
 	conf, err := r.repo.Load()
 	if err != nil {
 		return
@@ -48,8 +132,8 @@ func (r *Runner) RunOnce() {
 	applyCacheTimesFromConfig(&conf)
 
 	helper.ClearGlobalIPCache()
-	r.processDCDNServices(&conf)
-	r.processDDNSServices(&conf)
+	r.processDCDNServices(&conf, match)
+	r.processDDNSServices(&conf, match)
 }
 
 // applyCacheTimesFromConfig 将配置中的 CacheTimes 同步到环境变量，
@@ -75,7 +159,7 @@ func (r *Runner) SyncDCDNOnce() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	applyCacheTimesFromConfig(&conf)
-	r.processDCDNServices(&conf)
+	r.processDCDNServices(&conf, nil)
 }
 
 func (r *Runner) SyncDDNSOnce() {
@@ -88,7 +172,7 @@ func (r *Runner) SyncDDNSOnce() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	applyCacheTimesFromConfig(&conf)
-	r.processDDNSServices(&conf)
+	r.processDDNSServices(&conf, nil)
 }
 
 func (r *Runner) TriggerDCDNSyncAsync() {
@@ -99,14 +183,37 @@ func (r *Runner) TriggerDDNSSyncAsync() {
 	go r.SyncDDNSOnce()
 }
 
-func (r *Runner) processDCDNServices(conf *config.Config) {
+// TriggerPushedSyncAsync 仅同步使用了这些推送主机名（pushed_*）的 DDNS 配置组和 DCDN 配置
+func (r *Runner) TriggerPushedSyncAsync(hostnames []string) {
+	go r.syncMatching(pushedHostMatch(hostnames))
+}
+
+// pushedHostMatch 匹配使用了这些推送主机名的地址来源
+func pushedHostMatch(hostnames []string) sourceMatch {
+	return func(sourceType, value string) bool {
+		if !helper.IsPushedType(sourceType) {
+			return false
+		}
+		for _, hostname := range hostnames {
+			if strings.EqualFold(value, hostname) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (r *Runner) processDCDNServices(conf *config.Config, match sourceMatch) {
 	if !conf.DCDNConfig.DCDNEnabled {
 		return
 	}
 	if dcdn.ForceCompareGlobal || len(conf.DCDNConfig.DCDN) != len(r.dcdnCaches) {
 		r.dcdnCaches = []dcdn.Cache{}
-		for range conf.DCDNConfig.DCDN {
+		for i := range conf.DCDNConfig.DCDN {
 			r.dcdnCaches = append(r.dcdnCaches, dcdn.NewCache())
+			if st, ok := r.state.DCDN[buildDCDNCacheKey(&conf.DCDNConfig.DCDN[i])]; ok && !dcdn.ForceCompareGlobal {
+				r.dcdnCaches[i].RestoreState(st)
+			}
 		}
 	}
 	configChanged := false
@@ -121,31 +228,18 @@ func (r *Runner) processDCDNServices(conf *config.Config) {
 				break
 			}
 		}
-		if !hasValidSource {
+		if !hasValidSource || !match.matchesCDN(&conf.DCDNConfig.DCDN[i]) {
 			continue
 		}
 
-		var cdnSelected dcdn.CDN
-		switch conf.DCDNConfig.DCDN[i].Service {
-		case "aliyun":
-			cdnSelected = &dcdn.Aliyun{}
-		case "baiducloud":
-			cdnSelected = &dcdn.Baidu{}
-		case "tencent":
-			cdnSelected = &dcdn.Tencent{}
-		case "cloudflare":
-			cdnSelected = &dcdn.Cloudflare{}
-		case "upyun":
-			cdnSelected = &dcdn.Upyun{}
-		case "callback":
-			cdnSelected = &dcdn.Callback{}
-		case "mock":
-			cdnSelected = &dcdn.Mock{}
-		default:
-			cdnSelected = &dcdn.Aliyun{}
+		cdnSelected, err := dcdn.NewProvider(conf.DCDNConfig.DCDN[i].Service)
+		if err != nil {
+			helper.Error(helper.LogTypeDCDN, "%v，跳过 [域名=%s]", err, conf.DCDNConfig.DCDN[i].Domain)
+			continue
 		}
 		cdnSelected.Init(&conf.DCDNConfig.DCDN[i], &r.dcdnCaches[i])
 		cdnSelected.UpdateOrCreateSources()
+		r.recordDCDNHistory(&conf.DCDNConfig.DCDN[i], cdnSelected)
 		if conf.WebhookEnabled && cdnSelected.ShouldSendWebhook() {
 			config.ExecWebhook(&conf.Webhook, string(helper.LogTypeDCDN), cdnSelected.GetServiceName(), cdnSelected.GetServiceStatus(), formatDCDNChanges(cdnSelected.GetUpdateDetails()))
 		}
@@ -160,19 +254,28 @@ func (r *Runner) processDCDNServices(conf *config.Config) {
 			helper.Info(helper.LogTypeDCDN, "配置文件已保存（CNAME 已更新）")
 		}
 	}
-	dcdn.ForceCompareGlobal = false
+	// 仅处理部分配置时保留首轮强制比对，未处理的配置在下次完整同步时仍会比对
+	if match == nil {
+		dcdn.ForceCompareGlobal = false
+	}
+	r.saveDCDNState(conf)
 }
 
-func (r *Runner) processDDNSServices(conf *config.Config) {
+func (r *Runner) processDDNSServices(conf *config.Config, match sourceMatch) {
 	if !conf.DDNSConfig.DDNSEnabled {
 		return
 	}
 
-	r.rebuildDDNSCaches(conf)
+	// 合并 Docker 容器标签生成的配置组，DHCP 租约批量记录按当前租约展开为每台主机一个配置组
+	groups := append(append([]config.DNSGroup{}, conf.DDNSConfig.DDNS...), r.discoverDockerGroups(conf)...)
+	expanded, unreadable := ddns.ExpandLeaseGroups(groups)
+	r.removeStaleLeaseRecords(groups, expanded, unreadable)
+	groups = expanded
+	r.rebuildDDNSCaches(groups)
 
-	for groupIdx := range conf.DDNSConfig.DDNS {
-		group := &conf.DDNSConfig.DDNS[groupIdx]
-		if group.Domain == "" {
+	for groupIdx := range groups {
+		group := &groups[groupIdx]
+		if group.Domain == "" || !match.matchesGroup(group) {
 			continue
 		}
 
@@ -181,35 +284,15 @@ func (r *Runner) processDDNSServices(conf *config.Config) {
 			continue
 		}
 
-		var dnsSelected ddns.DNS
-		switch group.Service {
-		case ddns.ProviderAliDNS:
-			dnsSelected = &ddns.Aliyun{}
-		case ddns.ProviderTencent:
-			dnsSelected = &ddns.TencentCloud{}
-		case ddns.ProviderCloudflare:
-			dnsSelected = &ddns.Cloudflare{}
-		case ddns.ProviderHuawei:
-			dnsSelected = &ddns.Huawei{}
-		case ddns.ProviderBaiduCloud:
-			dnsSelected = &ddns.Baidu{}
-		case ddns.ProviderDnspod:
-			dnsSelected = &ddns.Dnspod{}
-		case ddns.ProviderNameSilo:
-			dnsSelected = &ddns.NameSilo{}
-		case ddns.ProviderGoDaddy:
-			dnsSelected = &ddns.GoDaddy{}
-		case ddns.ProviderCallback:
-			dnsSelected = &ddns.Callback{}
-		case ddns.ProviderMock:
-			dnsSelected = &ddns.Mock{}
-		default:
-			helper.Warn(helper.LogTypeDDNS, "不支持的 DNS 提供商: %s，跳过", group.Service)
+		dnsSelected, err := ddns.NewProvider(group.Service)
+		if err != nil {
+			helper.Warn(helper.LogTypeDDNS, "%v，跳过", err)
 			continue
 		}
 
 		dnsSelected.Init(group, groupCaches)
 		results := dnsSelected.UpdateOrCreateRecords()
+		r.recordDDNSHistory(group, dnsSelected.GetServiceName(), results)
 
 		if conf.WebhookEnabled {
 			needWebhook := false
@@ -249,14 +332,17 @@ func (r *Runner) processDDNSServices(conf *config.Config) {
 		}
 	}
 
-	ddns.ForceCompareGlobal = false
+	if match == nil {
+		ddns.ForceCompareGlobal = false
+	}
+	r.saveDDNSState()
 }
 
-func (r *Runner) rebuildDDNSCaches(conf *config.Config) {
+func (r *Runner) rebuildDDNSCaches(groups []config.DNSGroup) {
 	next := make(map[string]*ddns.Cache)
 
-	for groupIdx := range conf.DDNSConfig.DDNS {
-		group := &conf.DDNSConfig.DDNS[groupIdx]
+	for groupIdx := range groups {
+		group := &groups[groupIdx]
 		if group.Domain == "" {
 			continue
 		}
@@ -275,8 +361,7 @@ func (r *Runner) rebuildDDNSCaches(conf *config.Config) {
 				}
 			}
 
-			cache := ddns.NewCache()
-			next[key] = &cache
+			next[key] = r.newDDNSCache(key)
 		}
 	}
 
@@ -295,8 +380,7 @@ func (r *Runner) groupDDNSCaches(group *config.DNSGroup) []*ddns.Cache {
 		key := buildDDNSCacheKey(group, record)
 		cache, ok := r.ddnsCaches[key]
 		if !ok {
-			newCache := ddns.NewCache()
-			cache = &newCache
+			cache = r.newDDNSCache(key)
 			r.ddnsCaches[key] = cache
 		}
 		groupCaches = append(groupCaches, cache)
@@ -305,8 +389,72 @@ func (r *Runner) groupDDNSCaches(group *config.DNSGroup) []*ddns.Cache {
 	return groupCaches
 }
 
+// newDDNSCache 创建记录缓存，状态存储中有该记录时恢复上次的同步状态
+func (r *Runner) newDDNSCache(key string) *ddns.Cache {
+	cache := ddns.NewCache()
+	if st, ok := r.state.DDNS[key]; ok && !ddns.ForceCompareGlobal {
+		cache.RestoreState(st)
+	}
+	return &cache
+}
+
+// saveDDNSState 将当前 DDNS 缓存写入状态存储
+func (r *Runner) saveDDNSState() {
+	if r.store == nil {
+		return
+	}
+	r.state.DDNS = make(map[string]config.CacheState, len(r.ddnsCaches))
+	for key, cache := range r.ddnsCaches {
+		r.state.DDNS[key] = cache.State()
+	}
+	r.saveState(helper.LogTypeDDNS)
+}
+
+// saveDCDNState 将当前 DCDN 缓存写入状态存储
+func (r *Runner) saveDCDNState(conf *config.Config) {
+	if r.store == nil {
+		return
+	}
+	r.state.DCDN = make(map[string]config.CacheState, len(r.dcdnCaches))
+	for i := range conf.DCDNConfig.DCDN {
+		if i >= len(r.dcdnCaches) || conf.DCDNConfig.DCDN[i].Domain == "" {
+			continue
+		}
+		r.state.DCDN[buildDCDNCacheKey(&conf.DCDNConfig.DCDN[i])] = r.dcdnCaches[i].State()
+	}
+	r.saveState(helper.LogTypeDCDN)
+}
+
+func (r *Runner) saveState(logType helper.LogType) {
+	r.state.Pushed = helper.PushedIPs()
+	r.state.Docker = generatedState(r.dockerRecords)
+	r.state.DHCP = generatedState(r.leaseRecords)
+	if err := r.store.Save(r.state); err != nil {
+		helper.Warn(logType, "保存同步状态失败: %v", err)
+	}
+}
+
+// buildDCDNCacheKey 生成 DCDN 缓存键，源站配置变化后视为新的配置
+func buildDCDNCacheKey(cdn *config.CDN) string {
+	parts := []string{cdn.ID, cdn.Service, cdn.Domain, cdn.CDNType}
+	for _, source := range cdn.Sources {
+		parts = append(parts, source.Type, source.Value, source.Regex)
+		if source.IPv6Policy != "" {
+			parts = append(parts, source.IPv6Policy)
+		}
+		if source.IPv6Suffix != "" {
+			parts = append(parts, source.IPv6Suffix, strconv.Itoa(source.PrefixLength))
+		}
+		// 仅在配置了提取方式时加入，已有配置的缓存键保持不变
+		if extract := source.AddrExtract(); !extract.IsZero() {
+			parts = append(parts, extract.String())
+		}
+	}
+	return strings.Join(parts, "\x1f")
+}
+
 func buildDDNSCacheKey(group *config.DNSGroup, record *config.DNSRecord) string {
-	return strings.Join([]string{
+	parts := []string{
 		group.ID,
 		group.Service,
 		group.Domain,
@@ -315,7 +463,88 @@ func buildDDNSCacheKey(group *config.DNSGroup, record *config.DNSRecord) string
 		record.IPType,
 		record.Value,
 		record.Regex,
-	}, "\x1f")
+	}
+	if record.IPv6Policy != "" {
+		parts = append(parts, record.IPv6Policy)
+	}
+	if record.IPv6Suffix != "" {
+		parts = append(parts, record.IPv6Suffix, strconv.Itoa(record.PrefixLength))
+	}
+	// 仅在配置了提取方式时加入，已有配置的缓存键保持不变
+	if extract := record.AddrExtract(); !extract.IsZero() {
+		parts = append(parts, extract.String())
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// recordDDNSHistory 将本轮每条记录的处理结果写入同步历史；
+// 与 DCDN 一致，未改变的记录不写入，避免无变化的轮次挤掉真实变更
+func (r *Runner) recordDDNSHistory(group *config.DNSGroup, serviceName string, results []ddns.RecordResult) {
+	if r.history == nil || len(results) == 0 {
+		return
+	}
+	now := time.Now()
+	entries := make([]history.Entry, 0, len(results))
+	for _, result := range results {
+		if result.Status == ddns.UpdatedNothing {
+			continue
+		}
+		entries = append(entries, history.Entry{
+			Time:       now,
+			Kind:       history.KindDDNS,
+			Provider:   group.Service,
+			Name:       serviceName,
+			Domain:     group.Domain,
+			RecordType: result.RecordType,
+			OldValue:   result.OldValue,
+			NewValue:   result.NewValue,
+			Status:     string(result.Status),
+			Error:      result.ErrorMessage,
+		})
+	}
+	if err := r.history.Add(entries...); err != nil {
+		helper.Warn(helper.LogTypeDDNS, "写入同步历史失败: %v", err)
+	}
+}
+
+// recordDCDNHistory 将本轮源站 IP 变更写入同步历史；
+// 无变更明细但发生了推送（首次运行、强制更新、失败）时记录一条汇总
+func (r *Runner) recordDCDNHistory(cdn *config.CDN, cdnSelected dcdn.CDN) {
+	if r.history == nil {
+		return
+	}
+	status := cdnSelected.GetServiceStatus()
+	now := time.Now()
+	details := cdnSelected.GetUpdateDetails()
+	entries := make([]history.Entry, 0, len(details)+1)
+	for _, d := range details {
+		entries = append(entries, history.Entry{
+			Time:       now,
+			Kind:       history.KindDCDN,
+			Provider:   cdn.Service,
+			Name:       cdnSelected.GetServiceName(),
+			Domain:     cdn.Domain,
+			RecordType: d.SourceType,
+			Source:     d.SourceValue,
+			OldValue:   d.OldIP,
+			NewValue:   d.NewIP,
+			Status:     status,
+		})
+	}
+	if len(entries) == 0 && status != "" && status != string(dcdn.UpdatedNothing) {
+		entries = append(entries, history.Entry{
+			Time:       now,
+			Kind:       history.KindDCDN,
+			Provider:   cdn.Service,
+			Name:       cdnSelected.GetServiceName(),
+			Domain:     cdn.Domain,
+			RecordType: cdn.CDNType,
+			Status:     status,
+		})
+	}
+	if err := r.history.Add(entries...); err != nil {
+		helper.Warn(helper.LogTypeDCDN, "写入同步历史失败: %v", err)
+	}
 }
 
 // formatDCDNChanges 将 DCDN 变更明细格式化为单行字符串供 webhook 模板替换
@@ -1,10 +1,16 @@
 package bootstrap
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/cxbdasheng/dnet/config"
 	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/history"
 )
 
 func TestProcessDDNSServices_ReusesCachesWhenGroupOrderChanges(t *testing.T) {
@@ -39,7 +45,7 @@ func TestProcessDDNSServices_ReusesCachesWhenGroupOrderChanges(t *testing.T) {
 	}
 
 	ddns.ForceCompareGlobal = true
-	runner.processDDNSServices(firstConf)
+	runner.processDDNSServices(firstConf, nil)
 
 	group1Key := buildDDNSCacheKey(&firstConf.DDNSConfig.DDNS[0], &firstConf.DDNSConfig.DDNS[0].Records[0])
 	group2Key := buildDDNSCacheKey(&firstConf.DDNSConfig.DDNS[1], &firstConf.DDNSConfig.DDNS[1].Records[0])
@@ -64,7 +70,7 @@ func TestProcessDDNSServices_ReusesCachesWhenGroupOrderChanges(t *testing.T) {
 	}
 
 	ddns.ForceCompareGlobal = false
-	runner.processDDNSServices(secondConf)
+	runner.processDDNSServices(secondConf, nil)
 
 	if got := runner.ddnsCaches[group1Key]; got != group1Cache {
 		t.Fatal("group-1 cache should be reused after group reorder")
@@ -106,7 +112,7 @@ func TestProcessDDNSServices_RebindsCachesWhenValidRecordsChange(t *testing.T) {
 	}
 
 	ddns.ForceCompareGlobal = true
-	runner.processDDNSServices(firstConf)
+	runner.processDDNSServices(firstConf, nil)
 
 	aKey := buildDDNSCacheKey(&firstConf.DDNSConfig.DDNS[0], &firstConf.DDNSConfig.DDNS[0].Records[0])
 	txtKey := buildDDNSCacheKey(&firstConf.DDNSConfig.DDNS[0], &firstConf.DDNSConfig.DDNS[0].Records[2])
@@ -139,7 +145,7 @@ func TestProcessDDNSServices_RebindsCachesWhenValidRecordsChange(t *testing.T) {
 	}
 
 	ddns.ForceCompareGlobal = false
-	runner.processDDNSServices(secondConf)
+	runner.processDDNSServices(secondConf, nil)
 
 	aaaaKey := buildDDNSCacheKey(&secondConf.DDNSConfig.DDNS[0], &secondConf.DDNSConfig.DDNS[0].Records[1])
 	aaaaCache := runner.ddnsCaches[aaaaKey]
@@ -163,3 +169,187 @@ func TestProcessDDNSServices_RebindsCachesWhenValidRecordsChange(t *testing.T) {
 		t.Fatal("cache for inactive A record should be removed")
 	}
 }
+
+func TestProcessDDNSServices_RestartWithUnchangedIPSkipsProvider(t *testing.T) {
+	prevForceCompare := ddns.ForceCompareGlobal
+	defer func() {
+		ddns.ForceCompareGlobal = prevForceCompare
+	}()
+
+	var providerCalls, webhookCalls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&providerCalls, 1)
+	}))
+	defer provider.Close()
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&webhookCalls, 1)
+	}))
+	defer webhook.Close()
+
+	newConf := func() *config.Config {
+		return &config.Config{
+			Webhook: config.Webhook{WebhookEnabled: true, WebhookURL: webhook.URL},
+			DDNSConfig: config.DDNSConfig{
+				DDNSEnabled: true,
+				DDNS: []config.DNSGroup{
+					{
+						ID:        "group-1",
+						Domain:    "home.example.com",
+						Service:   ddns.ProviderCallback,
+						AccessKey: provider.URL + "/?ip=#{ip}",
+						Records: []config.DNSRecord{
+							{Type: ddns.RecordTypeA, IPType: helper.DynamicIPv4Command, Value: "echo 1.2.3.4"},
+						},
+					},
+				},
+			},
+		}
+	}
+	statePath := filepath.Join(t.TempDir(), "dnet.state.json")
+
+	// 首次运行：推送并写入状态
+	ddns.ForceCompareGlobal = true
+	first := NewRunner(nil)
+	first.UseStateStore(config.NewFileStateStore(statePath))
+	helper.ClearGlobalIPCache()
+	first.processDDNSServices(newConf(), nil)
+	if providerCalls != 1 || webhookCalls != 1 {
+		t.Fatalf("first run should push once and notify once, got provider=%d webhook=%d", providerCalls, webhookCalls)
+	}
+
+	// 模拟重启：新的 Runner 从状态文件恢复，IP 未变化
+	ddns.ForceCompareGlobal = true
+	second := NewRunner(nil)
+	second.UseStateStore(config.NewFileStateStore(statePath))
+	helper.ClearGlobalIPCache()
+	second.processDDNSServices(newConf(), nil)
+	if providerCalls != 1 || webhookCalls != 1 {
+		t.Fatalf("restart with unchanged IP should not call provider or webhook, got provider=%d webhook=%d", providerCalls, webhookCalls)
+	}
+}
+
+func TestRecordDDNSHistory_SkipsUnchanged(t *testing.T) {
+	store, err := history.NewStore("", 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	runner := NewRunner(nil)
+	runner.UseHistory(store)
+
+	group := &config.DNSGroup{Domain: "home.example.com", Service: ddns.ProviderMock}
+	runner.recordDDNSHistory(group, "mock", []ddns.RecordResult{
+		{RecordType: ddns.RecordTypeA, Status: ddns.UpdatedNothing},
+		{RecordType: ddns.RecordTypeAAAA, Status: ddns.UpdatedSuccess, NewValue: "2001:db8::1"},
+	})
+	runner.recordDDNSHistory(group, "mock", []ddns.RecordResult{{RecordType: ddns.RecordTypeA, Status: ddns.UpdatedNothing}})
+
+	page := store.Query(history.Query{})
+	if page.Total != 1 || page.Items[0].RecordType != ddns.RecordTypeAAAA {
+		t.Fatalf("expected only the changed record in history, got %+v", page)
+	}
+}
+
+func TestUseStateStore_RestoresPushedIPs(t *testing.T) {
+	prevForceCompare := ddns.ForceCompareGlobal
+	defer func() {
+		ddns.ForceCompareGlobal = prevForceCompare
+		helper.ClearPushedIPs()
+	}()
+
+	var providerCalls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&providerCalls, 1)
+	}))
+	defer provider.Close()
+	conf := &config.Config{
+		DDNSConfig: config.DDNSConfig{
+			DDNSEnabled: true,
+			DDNS: []config.DNSGroup{{
+				ID:        "group-1",
+				Domain:    "home.example.com",
+				Service:   ddns.ProviderCallback,
+				AccessKey: provider.URL + "/?ip=#{ip}",
+				Records:   []config.DNSRecord{{Type: ddns.RecordTypeA, IPType: helper.PushedIPv4, Value: "router"}},
+			}},
+		},
+	}
+	statePath := filepath.Join(t.TempDir(), "dnet.state.json")
+
+	helper.ClearPushedIPs()
+	helper.SetPushedIP("router", helper.IPv4, "1.2.3.4", "192.168.1.1")
+	ddns.ForceCompareGlobal = true
+	first := NewRunner(nil)
+	first.UseStateStore(config.NewFileStateStore(statePath))
+	first.processDDNSServices(conf, nil)
+	if providerCalls != 1 {
+		t.Fatalf("first run should push once, got %d", providerCalls)
+	}
+
+	// 模拟重启：内存中的推送地址丢失，从状态文件恢复
+	helper.ClearPushedIPs()
+	second := NewRunner(nil)
+	second.UseStateStore(config.NewFileStateStore(statePath))
+	if ip, ok := helper.GetPushedIP("router", helper.IPv4); !ok || ip.Addr != "1.2.3.4" || ip.ClientIP != "192.168.1.1" {
+		t.Fatalf("expected pushed IP restored, got %+v %v", ip, ok)
+	}
+	second.processDDNSServices(conf, nil)
+	if providerCalls != 1 {
+		t.Fatalf("restored pushed IP is unchanged, provider should not be called again, got %d", providerCalls)
+	}
+}
+
+func TestProcessDDNSServices_PushedSyncSkipsUnrelatedGroups(t *testing.T) {
+	prevForceCompare := ddns.ForceCompareGlobal
+	defer func() {
+		ddns.ForceCompareGlobal = prevForceCompare
+		helper.ClearPushedIPs()
+	}()
+
+	var routerCalls, nasCalls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("host") == "nas" {
+			atomic.AddInt32(&nasCalls, 1)
+		} else {
+			atomic.AddInt32(&routerCalls, 1)
+		}
+	}))
+	defer provider.Close()
+	newGroup := func(id, host string) config.DNSGroup {
+		return config.DNSGroup{
+			ID:        id,
+			Domain:    host + ".example.com",
+			Service:   ddns.ProviderCallback,
+			AccessKey: provider.URL + "/?host=" + host + "&ip=#{ip}",
+			Records:   []config.DNSRecord{{Type: ddns.RecordTypeA, IPType: helper.PushedIPv4, Value: host}},
+		}
+	}
+	conf := &config.Config{
+		DDNSConfig: config.DDNSConfig{
+			DDNSEnabled: true,
+			DDNS:        []config.DNSGroup{newGroup("router", "router"), newGroup("nas", "nas")},
+		},
+	}
+
+	helper.ClearPushedIPs()
+	helper.SetPushedIP("router", helper.IPv4, "1.2.3.4", "192.168.1.1")
+	helper.SetPushedIP("nas", helper.IPv4, "5.6.7.8", "192.168.1.2")
+	ddns.ForceCompareGlobal = true
+	runner := NewRunner(nil)
+	runner.processDDNSServices(conf, nil)
+	if routerCalls != 1 || nasCalls != 1 {
+		t.Fatalf("first run should push both groups, got router=%d nas=%d", routerCalls, nasCalls)
+	}
+	nas := &conf.DDNSConfig.DDNS[1]
+	nasCache := runner.ddnsCaches[buildDDNSCacheKey(nas, &nas.Records[0])]
+	times := nasCache.Times
+
+	// 推送 router 的新地址只同步使用它的配置组，nas 的剩余次数不受影响
+	helper.SetPushedIP("router", helper.IPv4, "1.2.3.5", "192.168.1.1")
+	runner.processDDNSServices(conf, pushedHostMatch([]string{"ROUTER"}))
+	if routerCalls != 2 || nasCalls != 1 {
+		t.Fatalf("expected only the router group to be synced, got router=%d nas=%d", routerCalls, nasCalls)
+	}
+	if nasCache.Times != times {
+		t.Errorf("unrelated group should keep its remaining times, got %d want %d", nasCache.Times, times)
+	}
+}
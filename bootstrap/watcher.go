@@ -0,0 +1,169 @@
+package bootstrap
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// interfaceDebounce 网卡地址变化后的等待时间，合并 PPPoE 重拨、IPv6 重新获取前缀等产生的连续事件
+const interfaceDebounce = 3 * time.Second
+
+// WatchInterfaces 监听 dynamic_*_interface 来源所用网卡的地址变化，变化后立即同步使用这些网卡的配置；
+// 定时同步仍然保留，监听不可用时（非 Linux 等）仅按间隔同步
+func (r *Runner) WatchInterfaces() {
+	watcher, err := helper.NewAddrWatcher()
+	if err != nil {
+		helper.Info(helper.LogTypeSystem, "未启用网卡地址变化监听: %v", err)
+		return
+	}
+	w := newInterfaceWatcher(r.repo.Load, r.syncInterfaces, interfaceAddrSnapshot, interfaceDebounce)
+	w.seed()
+	helper.Info(helper.LogTypeSystem, "已启用网卡地址变化监听")
+	go func() {
+		if err := watcher.Run(w.notify); err != nil {
+			helper.Warn(helper.LogTypeSystem, "网卡地址变化监听已停止，仅按间隔同步: %v", err)
+		}
+	}()
+}
+
+// interfaceWatcher 过滤未被配置引用的网卡，并对地址变化做去抖
+type interfaceWatcher struct {
+	load     func() (config.Config, error)
+	sync     func(ifNames []string)
+	snapshot func(ifName string) string
+	debounce time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending map[string]bool
+	last    map[string]string // 网卡 -> 上次同步时的地址快照
+}
+
+func newInterfaceWatcher(load func() (config.Config, error), syncFn func([]string), snapshot func(string) string, debounce time.Duration) *interfaceWatcher {
+	return &interfaceWatcher{
+		load:     load,
+		sync:     syncFn,
+		snapshot: snapshot,
+		debounce: debounce,
+		pending:  make(map[string]bool),
+		last:     make(map[string]string),
+	}
+}
+
+// seed 记录当前地址，避免首次事件（如 IPv6 生命周期刷新）在地址未变时触发同步
+func (w *interfaceWatcher) seed() {
+	conf, err := w.load()
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for name := range watchedInterfaces(&conf) {
+		w.last[name] = w.snapshot(name)
+	}
+}
+
+// notify 处理一次地址事件，只关心配置中引用的网卡
+func (w *interfaceWatcher) notify(ifName string) {
+	conf, err := w.load()
+	if err != nil || !watchedInterfaces(&conf)[ifName] {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[ifName] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.fire)
+}
+
+// fire 去抖结束后比较地址快照，有变化才同步
+func (w *interfaceWatcher) fire() {
+	w.mu.Lock()
+	var changed []string
+	for name := range w.pending {
+		if snap := w.snapshot(name); snap != w.last[name] {
+			w.last[name] = snap
+			changed = append(changed, name)
+		}
+	}
+	w.pending = make(map[string]bool)
+	w.mu.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+	sort.Strings(changed)
+	helper.Info(helper.LogTypeSystem, "网卡地址发生变化，立即同步 [网卡=%s]", strings.Join(changed, ","))
+	w.sync(changed)
+}
+
+// syncInterfaces 仅同步使用了这些网卡的 DDNS 配置组和 DCDN 配置
+func (r *Runner) syncInterfaces(ifNames []string) {
+	r.syncMatching(interfaceMatch(ifNames))
+}
+
+// interfaceMatch 匹配使用了这些网卡的 dynamic_*_interface 地址来源
+func interfaceMatch(ifNames []string) sourceMatch {
+	return func(sourceType, value string) bool {
+		if sourceType != helper.DynamicIPv4Interface && sourceType != helper.DynamicIPv6Interface {
+			return false
+		}
+		for _, name := range ifNames {
+			if value == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// watchedInterfaces 收集已启用的 DDNS 记录与 DCDN 源站中使用的网卡
+func watchedInterfaces(conf *config.Config) map[string]bool {
+	names := make(map[string]bool)
+	add := func(sourceType, value string) {
+		if value != "" && (sourceType == helper.DynamicIPv4Interface || sourceType == helper.DynamicIPv6Interface) {
+			names[value] = true
+		}
+	}
+	if conf.DDNSConfig.DDNSEnabled {
+		for _, group := range conf.DDNSConfig.DDNS {
+			for _, record := range group.Records {
+				add(record.IPType, record.Value)
+			}
+		}
+	}
+	if conf.DCDNConfig.DCDNEnabled {
+		for _, cdn := range conf.DCDNConfig.DCDN {
+			for _, source := range cdn.Sources {
+				add(source.Type, source.Value)
+			}
+		}
+	}
+	return names
+}
+
+// interfaceAddrSnapshot 返回网卡当前地址（排序后拼接），网卡不存在时为空
+func interfaceAddrSnapshot(ifName string) string {
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return ""
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return ""
+	}
+	list := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, addr.String())
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
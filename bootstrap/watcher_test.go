@@ -0,0 +1,118 @@
+package bootstrap
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func newWatcherTestConfig() config.Config {
+	return config.Config{
+		DDNSConfig: config.DDNSConfig{
+			DDNSEnabled: true,
+			DDNS: []config.DNSGroup{{
+				Domain: "a.example.com",
+				Records: []config.DNSRecord{
+					{Type: "A", IPType: helper.DynamicIPv4Interface, Value: "ppp0"},
+					{Type: "A", IPType: helper.DynamicIPv4URL, Value: "https://ip.example.com"},
+				},
+			}},
+		},
+		DCDNConfig: config.DCDNConfig{
+			DCDNEnabled: true,
+			DCDN: []config.CDN{{
+				Domain:  "cdn.example.com",
+				Sources: []config.Source{{Type: helper.DynamicIPv6Interface, Value: "br-lan"}},
+			}},
+		},
+	}
+}
+
+func TestWatchedInterfaces(t *testing.T) {
+	conf := newWatcherTestConfig()
+	names := watchedInterfaces(&conf)
+	if len(names) != 2 || !names["ppp0"] || !names["br-lan"] {
+		t.Fatalf("expected ppp0 and br-lan to be watched, got %v", names)
+	}
+
+	conf.DCDNConfig.DCDNEnabled = false
+	if names = watchedInterfaces(&conf); names["br-lan"] {
+		t.Fatalf("disabled DCDN sources should not be watched, got %v", names)
+	}
+}
+
+func TestInterfaceWatcher_DebouncesAndSkipsUnchangedAddresses(t *testing.T) {
+	conf := newWatcherTestConfig()
+	var (
+		mu    sync.Mutex
+		addrs = map[string]string{"ppp0": "100.64.0.1/32", "br-lan": "2001:db8::1/64"}
+		syncs atomic.Int32
+		names []string
+	)
+	snapshot := func(name string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return addrs[name]
+	}
+	load := func() (config.Config, error) { return conf, nil }
+	w := newInterfaceWatcher(load, func(changed []string) {
+		mu.Lock()
+		names = changed
+		mu.Unlock()
+		syncs.Add(1)
+	}, snapshot, 20*time.Millisecond)
+	w.seed()
+
+	// 地址未变（如 IPv6 生命周期刷新）不触发同步
+	w.notify("ppp0")
+	time.Sleep(80 * time.Millisecond)
+	if got := syncs.Load(); got != 0 {
+		t.Fatalf("expected no sync when address is unchanged, got %d", got)
+	}
+
+	// PPPoE 重拨：删除与新增事件合并为一次同步
+	mu.Lock()
+	addrs["ppp0"] = "100.64.0.2/32"
+	mu.Unlock()
+	w.notify("ppp0")
+	w.notify("eth9") // 未被配置引用的网卡
+	w.notify("ppp0")
+	time.Sleep(80 * time.Millisecond)
+	if got := syncs.Load(); got != 1 {
+		t.Fatalf("expected exactly one debounced sync, got %d", got)
+	}
+	mu.Lock()
+	if len(names) != 1 || names[0] != "ppp0" {
+		t.Fatalf("expected only the changed interface to be synced, got %v", names)
+	}
+	mu.Unlock()
+
+	// 未被引用的网卡变化不触发同步
+	w.notify("eth9")
+	time.Sleep(80 * time.Millisecond)
+	if got := syncs.Load(); got != 1 {
+		t.Fatalf("expected unwatched interface to be ignored, got %d syncs", got)
+	}
+}
+
+func TestInterfaceMatch(t *testing.T) {
+	conf := newWatcherTestConfig()
+	match := interfaceMatch([]string{"ppp0"})
+	if !match.matchesGroup(&conf.DDNSConfig.DDNS[0]) {
+		t.Errorf("group using ppp0 should be synced")
+	}
+	if match.matchesCDN(&conf.DCDNConfig.DCDN[0]) {
+		t.Errorf("CDN using br-lan should not be synced when only ppp0 changed")
+	}
+	if match(helper.DynamicIPv4URL, "ppp0") {
+		t.Errorf("non-interface source should not match")
+	}
+	var all sourceMatch
+	if !all.matchesGroup(&conf.DDNSConfig.DDNS[0]) || !all.matchesCDN(&conf.DCDNConfig.DCDN[0]) {
+		t.Errorf("nil match should sync everything")
+	}
+}
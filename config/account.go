@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 账号角色
+const (
+	RoleAdmin  = "admin"  // 管理员：可查看和修改全部配置
+	RoleViewer = "viewer" // 只读：可查看控制台和日志，不能修改配置
+)
+
+// Account 控制台账号；主账号（Config.User）固定为管理员，其余账号保存在 Config.Accounts
+type Account struct {
+	Username  string    `json:"username"`
+	Password  string    `json:"-"`
+	Role      string    `json:"role"`
+	TwoFactor TwoFactor `json:"-"`
+}
+
+// IsAdmin 是否为管理员
+func (a Account) IsAdmin() bool {
+	return a.Role == RoleAdmin
+}
+
+// IsValidRole 判断角色是否合法
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleViewer
+}
+
+// FindAccount 按用户名查找账号（包含主账号）
+func (conf *Config) FindAccount(username string) (Account, bool) {
+	if username == "" {
+		return Account{}, false
+	}
+	if conf.Username != "" && conf.Username == username {
+		return Account{Username: conf.Username, Password: conf.Password, Role: RoleAdmin, TwoFactor: conf.User.TwoFactor}, true
+	}
+	for _, account := range conf.Accounts {
+		if account.Username == username {
+			return account, true
+		}
+	}
+	return Account{}, false
+}
+
+// Authenticate 校验用户名和密码，成功时返回对应账号
+func (conf *Config) Authenticate(username, password string) (Account, bool) {
+	account, ok := conf.FindAccount(username)
+	if !ok || !verifyPasswordHash(account.Password, password) {
+		return Account{}, false
+	}
+	return account, true
+}
+
+// AddAccount 新增账号，密码使用 bcrypt 加密保存
+func (conf *Config) AddAccount(username, password, role string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return errors.New("用户名不能为空")
+	}
+	if len(password) < 6 {
+		return errors.New("密码长度不能少于6位")
+	}
+	if !IsValidRole(role) {
+		return fmt.Errorf("不支持的角色: %s", role)
+	}
+	if _, exists := conf.FindAccount(username); exists {
+		return fmt.Errorf("用户名 %s 已存在", username)
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	// 新建切片，避免修改配置缓存共享的底层数组
+	accounts := make([]Account, 0, len(conf.Accounts)+1)
+	accounts = append(accounts, conf.Accounts...)
+	conf.Accounts = append(accounts, Account{Username: username, Password: hashed, Role: role})
+	return nil
+}
+
+// RemoveAccount 删除账号，主账号不能删除，返回是否存在
+func (conf *Config) RemoveAccount(username string) bool {
+	for i := range conf.Accounts {
+		if conf.Accounts[i].Username == username {
+			accounts := make([]Account, 0, len(conf.Accounts)-1)
+			accounts = append(accounts, conf.Accounts[:i]...)
+			conf.Accounts = append(accounts, conf.Accounts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetAccountPassword 更新账号的密码哈希，返回账号是否存在
+func (conf *Config) SetAccountPassword(username, hashedPassword string) bool {
+	if conf.Username != "" && conf.Username == username {
+		conf.Password = hashedPassword
+		return true
+	}
+	for i := range conf.Accounts {
+		if conf.Accounts[i].Username == username {
+			accounts := append([]Account(nil), conf.Accounts...)
+			accounts[i].Password = hashedPassword
+			conf.Accounts = accounts
+			return true
+		}
+	}
+	return false
+}
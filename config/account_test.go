@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestAccounts(t *testing.T) {
+	conf := &Config{User: User{Username: "admin", Password: mustHashPassword(t, "admin-password")}}
+
+	if err := conf.AddAccount("viewer", "viewer-password", RoleViewer); err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+	if err := conf.AddAccount("admin", "another-password", RoleViewer); err == nil {
+		t.Error("重复用户名应该失败")
+	}
+	if err := conf.AddAccount("ops", "ops-password", "root"); err == nil {
+		t.Error("不支持的角色应该失败")
+	}
+	if err := conf.AddAccount("ops", "123", RoleAdmin); err == nil {
+		t.Error("过短的密码应该失败")
+	}
+
+	if account, ok := conf.Authenticate("admin", "admin-password"); !ok || !account.IsAdmin() {
+		t.Errorf("主账号应为管理员: %+v %v", account, ok)
+	}
+	if account, ok := conf.Authenticate("viewer", "viewer-password"); !ok || account.IsAdmin() {
+		t.Errorf("只读账号验证失败: %+v %v", account, ok)
+	}
+	if _, ok := conf.Authenticate("viewer", "admin-password"); ok {
+		t.Error("错误密码验证通过")
+	}
+	if _, ok := conf.Authenticate("nobody", "admin-password"); ok {
+		t.Error("不存在的账号验证通过")
+	}
+
+	shared := conf.Accounts
+	if !conf.SetAccountPassword("viewer", mustHashPassword(t, "changed-password")) {
+		t.Fatal("SetAccountPassword() 未找到账号")
+	}
+	if _, ok := conf.Authenticate("viewer", "changed-password"); !ok {
+		t.Error("修改后的密码验证失败")
+	}
+	if verifyPasswordHash(shared[0].Password, "changed-password") {
+		t.Error("不应修改共享的底层数组")
+	}
+
+	if !conf.RemoveAccount("viewer") || conf.RemoveAccount("viewer") {
+		t.Error("RemoveAccount() 返回值错误")
+	}
+	if conf.RemoveAccount("admin") {
+		t.Error("主账号不能通过 RemoveAccount 删除")
+	}
+}
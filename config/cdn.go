@@ -2,6 +2,8 @@ package config
 
 import (
 	"strings"
+
+	"github.com/cxbdasheng/dnet/helper"
 )
 
 type DCDNConfig struct {
@@ -18,11 +20,20 @@ type CDN struct {
 	Service      string   `json:"service"`
 	AccessKey    string   `json:"access_key"`
 	AccessSecret string   `json:"access_secret"`
+	Endpoint     string   `json:"endpoint,omitempty"` // 自定义 API 地址（可选），用于国际站/私有区域/代理，为空使用提供商默认地址
 	CDNType      string   `json:"cdn_type"`
 	Sources      []Source `json:"sources"`
 	CName        string   `json:"cname"`
 }
 
+// GetEndpoint 返回自定义 API 地址（去掉末尾的 /），未配置时返回默认地址
+func (c *CDN) GetEndpoint(defaultEndpoint string) string {
+	if endpoint := strings.TrimRight(strings.TrimSpace(c.Endpoint), "/"); endpoint != "" {
+		return endpoint
+	}
+	return defaultEndpoint
+}
+
 // GetRootDomain 获取域名的根域名
 // 例如：test.example.com -> example.com
 //
@@ -46,12 +57,56 @@ func (c *CDN) GetRootDomain() string {
 type Source struct {
 	Type      string `json:"type"`
 	Value     string `json:"value"`
-	Regex     string `json:"regex"` // IPv6 正则表达式匹配（仅用于 dynamic_ipv6_interface）
+	Regex     string `json:"regex"` // IPv6 正则表达式匹配（仅用于 dynamic_ipv6_interface / dynamic_ipv6_neighbor）
 	Priority  string `json:"priority"`
 	Weight    string `json:"weight"`
 	Port      string `json:"port"`       // HTTP 端口
 	HttpsPort string `json:"https_port"` // HTTPS 端口
 	Protocol  string `json:"protocol"`   // HTTP、HTTPS、AUTO（协议跟随），默认 http
+
+	// 以下提取方式仅用于 dynamic_*_url / dynamic_*_command，均为空时取第一个匹配的地址
+	JSONPath     string `json:"json_path,omitempty" yaml:"json_path,omitempty"`         // JSON 路径，如 data.client
+	ExtractRegex string `json:"extract_regex,omitempty" yaml:"extract_regex,omitempty"` // 自定义正则，有捕获组时取第一个捕获组
+	MatchIndex   int    `json:"match_index,omitempty" yaml:"match_index,omitempty"`     // 取第 N 个匹配（从 1 开始）
+
+	IPv6Policy string `json:"ipv6_policy,omitempty" yaml:"ipv6_policy,omitempty"` // IPv6 地址选择策略（仅用于 dynamic_ipv6_interface / dynamic_ipv6_neighbor），如 auto、no_temporary,suffix=::1234
+
+	// 以下用于 dynamic_ipv6_interface / url / command：取获取到的地址的前缀，与主机后缀组合为局域网设备地址
+	IPv6Suffix   string `json:"ipv6_suffix,omitempty" yaml:"ipv6_suffix,omitempty"`     // 主机后缀，如 ::1234:5678
+	PrefixLength int    `json:"prefix_length,omitempty" yaml:"prefix_length,omitempty"` // 前缀长度，默认 64
+}
+
+// AddrExtract 返回源站的地址提取方式
+func (s *Source) AddrExtract() helper.AddrExtract {
+	return helper.AddrExtract{JSONPath: s.JSONPath, Regex: s.ExtractRegex, Index: s.MatchIndex}
+}
+
+// AddrSource 返回源站的地址来源配置
+func (s *Source) AddrSource() helper.AddrSource {
+	return helper.AddrSource{
+		Type:         s.Type,
+		Value:        s.Value,
+		Extract:      s.AddrExtract(),
+		IPv6Policy:   s.IPv6Policy,
+		IPv6Suffix:   s.IPv6Suffix,
+		PrefixLength: s.PrefixLength,
+	}
+}
+
+// MaskCDN 返回脱敏后的 CDN 配置副本
+func MaskCDN(cdn CDN) CDN {
+	return CDN{
+		ID:           cdn.ID,
+		Name:         cdn.Name,
+		Domain:       cdn.Domain,
+		CName:        cdn.CName,
+		Service:      cdn.Service,
+		AccessKey:    maskSensitiveString(cdn.AccessKey),
+		AccessSecret: maskSensitiveString(cdn.AccessSecret),
+		Endpoint:     cdn.Endpoint,
+		CDNType:      cdn.CDNType,
+		Sources:      cdn.Sources,
+	}
 }
 
 // maskSensitiveString 对敏感字符串进行脱敏处理
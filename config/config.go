@@ -2,12 +2,16 @@ package config
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/cxbdasheng/dnet/helper"
+	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
 
@@ -54,9 +58,13 @@ func GetConfigFilePath() string {
 type Config struct {
 	Settings
 	User
+	// 其他控制台账号（主账号为 User，固定为管理员）
+	Accounts []Account
 	Webhook
 	DCDNConfig
 	DDNSConfig
+	// API 令牌（仅保存哈希）
+	APITokens []APIToken
 	// 语言
 	Lang string
 }
@@ -175,9 +183,7 @@ func (conf *Config) GeneratePassword(newPassword string) (string, error) {
 	//	return "", fmt.Errorf("密码长度不能少于6位")
 	//}
 
-	// 使用SHA256加密密码
-	hashedPassword := hashPassword(newPassword)
-	return hashedPassword, nil
+	return hashPassword(newPassword)
 }
 
 // ResetPassword 重置密码
@@ -191,13 +197,14 @@ func (conf *Config) ResetPassword(newPassword string) error {
 		return fmt.Errorf("密码长度不能少于6位")
 	}
 
-	// 使用SHA256加密密码
-	hashedPassword := hashPassword(newPassword)
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
 	conf.Password = hashedPassword
 
 	// 保存配置到文件
-	err := conf.SaveConfig()
-	if err != nil {
+	if err = conf.SaveConfig(); err != nil {
 		return fmt.Errorf("保存密码失败: %v", err)
 	}
 
@@ -205,18 +212,72 @@ func (conf *Config) ResetPassword(newPassword string) error {
 	return nil
 }
 
-// VerifyPassword 验证密码
+// VerifyPassword 验证主账号密码，兼容旧版本的 SHA256 哈希
 func (conf *Config) VerifyPassword(inputPassword string) bool {
-	if inputPassword == "" || conf.Password == "" {
+	return verifyPasswordHash(conf.Password, inputPassword)
+}
+
+// NeedsPasswordRehash 主账号密码哈希是否需要升级
+func (conf *Config) NeedsPasswordRehash() bool {
+	return passwordNeedsRehash(conf.Password)
+}
+
+// NeedsPasswordRehash 账号密码哈希是否需要升级
+func (a Account) NeedsPasswordRehash() bool {
+	return passwordNeedsRehash(a.Password)
+}
+
+// verifyPasswordHash 常量时间校验密码，兼容旧版本的 SHA256 哈希
+func verifyPasswordHash(hash, inputPassword string) bool {
+	if inputPassword == "" || hash == "" {
 		return false
 	}
 
-	hashedInput := hashPassword(inputPassword)
-	return hashedInput == conf.Password
+	if isLegacyPasswordHash(hash) {
+		hashedInput := legacyHashPassword(inputPassword)
+		return subtle.ConstantTimeCompare([]byte(hashedInput), []byte(hash)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(inputPassword)) == nil
 }
 
-// hashPassword 对密码进行SHA256加密
-func hashPassword(password string) string {
+// passwordNeedsRehash 密码哈希是否需要升级（旧版 SHA256 或 bcrypt 强度低于当前值）
+func passwordNeedsRehash(hash string) bool {
+	if hash == "" {
+		return false
+	}
+	if isLegacyPasswordHash(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < passwordHashCost
+}
+
+// passwordHashCost bcrypt 计算强度
+const passwordHashCost = bcrypt.DefaultCost
+
+// hashPassword 使用 bcrypt 加密密码（自带随机盐）
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", fmt.Errorf("密码长度不能超过72字节")
+		}
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// legacyHashPassword 旧版本使用的无盐 SHA256 哈希，仅用于校验和迁移
+func legacyHashPassword(password string) string {
 	hash := sha256.Sum256([]byte(password))
 	return fmt.Sprintf("%x", hash)
 }
+
+// isLegacyPasswordHash 判断是否为旧版 SHA256 哈希（64 位十六进制）
+func isLegacyPasswordHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
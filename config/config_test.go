@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -12,38 +13,62 @@ func TestHashPassword(t *testing.T) {
 	tests := []struct {
 		name     string
 		password string
-		want     string
 	}{
-		{
-			name:     "空密码",
-			password: "",
-			want:     "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
-		},
-		{
-			name:     "简单密码",
-			password: "123456",
-			want:     "8d969eef6ecad3c29a3a629280e686cf0c3f5d5a86aff3ca12020c923adc6c92",
-		},
-		{
-			name:     "复杂密码",
-			password: "Test@123!#$%",
-			want:     "a87ff679a2f3e71d9181a67b7542122c0a05fdca30dc9fcdf995cbc0b6f4f2b9", // 需要实际计算
-		},
+		{name: "简单密码", password: "123456"},
+		{name: "复杂密码", password: "Test@123!#$%"},
+		{name: "中文密码", password: "密码123456"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := hashPassword(tt.password)
-			if len(got) != 64 { // SHA256 输出固定 64 个字符
-				t.Errorf("hashPassword() 长度 = %d, 期望 64", len(got))
+			got, err := hashPassword(tt.password)
+			if err != nil {
+				t.Fatalf("hashPassword() error = %v", err)
 			}
-			// 测试相同输入产生相同输出
-			got2 := hashPassword(tt.password)
-			if got != got2 {
-				t.Errorf("hashPassword() 不一致: %s != %s", got, got2)
+			if !strings.HasPrefix(got, "$2a$") || isLegacyPasswordHash(got) {
+				t.Errorf("hashPassword() = %s, 期望 bcrypt 格式", got)
+			}
+			// 每次随机加盐，相同输入产生不同输出
+			got2, _ := hashPassword(tt.password)
+			if got == got2 {
+				t.Errorf("hashPassword() 未加盐: %s == %s", got, got2)
 			}
 		})
 	}
+
+	if _, err := hashPassword(strings.Repeat("a", 73)); err == nil {
+		t.Error("超过 72 字节的密码应该失败")
+	}
+}
+
+// TestVerifyPassword_LegacyHash 测试兼容旧版 SHA256 哈希及升级判断
+func TestVerifyPassword_LegacyHash(t *testing.T) {
+	conf := &Config{User: User{Password: "8d969eef6ecad3c29a3a629280e686cf0c3f5d5a86aff3ca12020c923adc6c92"}}
+
+	if !isLegacyPasswordHash(conf.Password) {
+		t.Fatal("应识别为旧版哈希")
+	}
+	if !conf.VerifyPassword("123456") {
+		t.Error("旧版哈希的正确密码验证失败")
+	}
+	if conf.VerifyPassword("1234567") {
+		t.Error("旧版哈希的错误密码验证通过")
+	}
+	if !conf.NeedsPasswordRehash() {
+		t.Error("旧版哈希应需要升级")
+	}
+
+	hashed, err := conf.GeneratePassword("123456")
+	if err != nil {
+		t.Fatalf("GeneratePassword() error = %v", err)
+	}
+	conf.Password = hashed
+	if conf.NeedsPasswordRehash() {
+		t.Error("bcrypt 哈希不应需要升级")
+	}
+	if !conf.VerifyPassword("123456") {
+		t.Error("升级后的密码验证失败")
+	}
 }
 
 // TestGeneratePassword 测试密码生成
@@ -90,8 +115,8 @@ func TestGeneratePassword(t *testing.T) {
 			if got == "" {
 				t.Error("GeneratePassword() 返回空字符串")
 			}
-			if len(got) != 64 {
-				t.Errorf("GeneratePassword() 长度 = %d, 期望 64", len(got))
+			if isLegacyPasswordHash(got) || !(&Config{User: User{Password: got}}).VerifyPassword(tt.password) {
+				t.Errorf("GeneratePassword() = %s, 期望可验证的 bcrypt 哈希", got)
 			}
 		})
 	}
@@ -101,7 +126,10 @@ func TestGeneratePassword(t *testing.T) {
 func TestVerifyPassword(t *testing.T) {
 	conf := &Config{}
 	password := "test123456"
-	hashedPassword := hashPassword(password)
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		t.Fatalf("hashPassword() error = %v", err)
+	}
 	conf.Password = hashedPassword
 
 	tests := []struct {
@@ -167,7 +195,7 @@ func TestResetPassword(t *testing.T) {
 	conf := &Config{
 		User: User{
 			Username: "admin",
-			Password: hashPassword("oldpassword"),
+			Password: legacyHashPassword("oldpassword"),
 		},
 	}
 
@@ -341,7 +369,7 @@ func TestConfigCache(t *testing.T) {
 	conf := &Config{
 		User: User{
 			Username: "admin",
-			Password: hashPassword("password"),
+			Password: mustHashPassword(t, "password"),
 		},
 		Settings: Settings{
 			Port: "9877",
@@ -414,7 +442,7 @@ func TestSaveConfig(t *testing.T) {
 	conf := &Config{
 		User: User{
 			Username: "testuser",
-			Password: hashPassword("testpass"),
+			Password: mustHashPassword(t, "testpass"),
 		},
 		Lang: "zh-CN",
 	}
@@ -448,7 +476,7 @@ func TestSaveConfig(t *testing.T) {
 func BenchmarkHashPassword(b *testing.B) {
 	password := "test123456"
 	for i := 0; i < b.N; i++ {
-		hashPassword(password)
+		_, _ = hashPassword(password)
 	}
 }
 
@@ -456,10 +484,19 @@ func BenchmarkHashPassword(b *testing.B) {
 func BenchmarkVerifyPassword(b *testing.B) {
 	conf := &Config{
 		User: User{
-			Password: hashPassword("test123456"),
+			Password: mustHashPassword(b, "test123456"),
 		},
 	}
 	for i := 0; i < b.N; i++ {
 		conf.VerifyPassword("test123456")
 	}
 }
+
+func mustHashPassword(tb testing.TB, password string) string {
+	tb.Helper()
+	hash, err := hashPassword(password)
+	if err != nil {
+		tb.Fatalf("hashPassword() error = %v", err)
+	}
+	return hash
+}
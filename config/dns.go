@@ -2,6 +2,9 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/cxbdasheng/dnet/helper"
 )
@@ -11,6 +14,16 @@ type DDNSConfig struct {
 	DDNS        []DNSGroup `json:"ddns"`
 	// 强制同步计数器初始值
 	CacheTimes int `json:"ddns_cache_times,omitempty" yaml:"ddns_cache_times,omitempty"`
+	// Docker 容器标签发现，仅通过配置文件设置
+	Docker *DockerDiscovery `json:"docker,omitempty" yaml:"docker,omitempty"`
+}
+
+// DockerDiscovery Docker 容器标签发现：为带 dnet.ddns.domain 标签的运行中容器生成 DNS 配置组
+type DockerDiscovery struct {
+	Enabled       bool   `json:"enabled" yaml:"enabled"`
+	Socket        string `json:"socket,omitempty" yaml:"socket,omitempty"`                 // Engine API 的 unix socket，默认 /var/run/docker.sock
+	Group         string `json:"group,omitempty" yaml:"group,omitempty"`                   // 默认沿用其服务商与凭证的配置组（ID 或名称），可被容器的 dnet.ddns.group 标签覆盖
+	RemoveStopped bool   `json:"remove_stopped,omitempty" yaml:"remove_stopped,omitempty"` // 容器停止后删除其记录
 }
 
 // RestoreSensitiveFieldsForDDNS 恢复 DDNS 脱敏字段的原始值
@@ -58,17 +71,7 @@ func GetDCDNConfigJSON(DCDNConf DCDNConfig) string {
 
 	// 复制并脱敏每个 CDN 配置
 	for i, cdn := range DCDNConf.DCDN {
-		maskedConf.DCDN[i] = CDN{
-			ID:           cdn.ID,
-			Name:         cdn.Name,
-			Domain:       cdn.Domain,
-			CName:        cdn.CName,
-			Service:      cdn.Service,
-			AccessKey:    maskSensitiveString(cdn.AccessKey),
-			AccessSecret: maskSensitiveString(cdn.AccessSecret),
-			CDNType:      cdn.CDNType,
-			Sources:      cdn.Sources,
-		}
+		maskedConf.DCDN[i] = MaskCDN(cdn)
 	}
 
 	data, err := json.Marshal(maskedConf)
@@ -88,16 +91,69 @@ type DNSGroup struct {
 	Service      string      `json:"service"`
 	AccessKey    string      `json:"access_key"`
 	AccessSecret string      `json:"access_secret"`
+	Endpoint     string      `json:"endpoint,omitempty"` // 自定义 API 地址（可选），用于国际站/私有区域/代理，为空使用提供商默认地址
 	TTL          string      `json:"ttl"`
 	Records      []DNSRecord `json:"records"` // 该域名的多条 DNS 记录
 }
 
+// GetEndpoint 返回自定义 API 地址（去掉末尾的 /），未配置时返回默认地址
+func (g *DNSGroup) GetEndpoint(defaultEndpoint string) string {
+	if endpoint := strings.TrimRight(strings.TrimSpace(g.Endpoint), "/"); endpoint != "" {
+		return endpoint
+	}
+	return defaultEndpoint
+}
+
+// ValidateEndpoint 校验自定义 API 地址，为空表示使用默认地址
+func ValidateEndpoint(endpoint string) error {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("API 地址格式不正确，应以 http:// 或 https:// 开头: %s", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("API 地址不能包含查询参数: %s", endpoint)
+	}
+	return nil
+}
+
 // DNSRecord 表示单条 DNS 记录
 type DNSRecord struct {
 	Type   string `json:"type"`    // 记录类型：A, AAAA, CNAME, TXT
-	IPType string `json:"ip_type"` // IP 获取方式：static_ipv4, dynamic_ipv4_url, dynamic_ipv4_interface, dynamic_ipv4_command, static_ipv6, dynamic_ipv6_url, dynamic_ipv6_interface, dynamic_ipv6_command
+	IPType string `json:"ip_type"` // IP 获取方式：static_ipv4, dynamic_ipv4_url, dynamic_ipv4_interface, dynamic_ipv4_command, static_ipv6, dynamic_ipv6_url, dynamic_ipv6_interface, dynamic_ipv6_command, dynamic_ipv4_stun, dynamic_ipv6_stun, dynamic_ipv4_dns, dynamic_ipv6_dns, dynamic_ipv4_gateway, pushed_ipv4, pushed_ipv6, dynamic_ipv6_neighbor, dynamic_ipv4_dhcp, dynamic_ipv6_dhcp
 	Value  string `json:"value"`   // 值：IP地址、URL、网卡名称、命令、CNAME值、TXT 值
-	Regex  string `json:"regex"`   // IPv6 正则表达式匹配（仅用于 dynamic_ipv6_interface）
+	Regex  string `json:"regex"`   // IPv6 正则表达式匹配（仅用于 dynamic_ipv6_interface / dynamic_ipv6_neighbor）
+
+	// 以下提取方式仅用于 dynamic_*_url / dynamic_*_command，均为空时取第一个匹配的地址
+	JSONPath     string `json:"json_path,omitempty" yaml:"json_path,omitempty"`         // JSON 路径，如 data.client
+	ExtractRegex string `json:"extract_regex,omitempty" yaml:"extract_regex,omitempty"` // 自定义正则，有捕获组时取第一个捕获组
+	MatchIndex   int    `json:"match_index,omitempty" yaml:"match_index,omitempty"`     // 取第 N 个匹配（从 1 开始）
+
+	IPv6Policy string `json:"ipv6_policy,omitempty" yaml:"ipv6_policy,omitempty"` // IPv6 地址选择策略（仅用于 dynamic_ipv6_interface / dynamic_ipv6_neighbor），如 auto、no_temporary,suffix=::1234
+
+	// 以下用于 dynamic_ipv6_interface / url / command：取获取到的地址的前缀，与主机后缀组合为局域网设备地址
+	IPv6Suffix   string `json:"ipv6_suffix,omitempty" yaml:"ipv6_suffix,omitempty"`     // 主机后缀，如 ::1234:5678
+	PrefixLength int    `json:"prefix_length,omitempty" yaml:"prefix_length,omitempty"` // 前缀长度，默认 64
+}
+
+// AddrExtract 返回记录的地址提取方式
+func (r *DNSRecord) AddrExtract() helper.AddrExtract {
+	return helper.AddrExtract{JSONPath: r.JSONPath, Regex: r.ExtractRegex, Index: r.MatchIndex}
+}
+
+// AddrSource 返回记录的地址来源配置
+func (r *DNSRecord) AddrSource() helper.AddrSource {
+	return helper.AddrSource{
+		Type:         r.IPType,
+		Value:        r.Value,
+		Extract:      r.AddrExtract(),
+		IPv6Policy:   r.IPv6Policy,
+		IPv6Suffix:   r.IPv6Suffix,
+		PrefixLength: r.PrefixLength,
+	}
 }
 
 // DNSConfig DNS 完整配置（用于传递给 DDNS 处理器）
@@ -109,11 +165,18 @@ type DNSConfig struct {
 	Service      string
 	AccessKey    string
 	AccessSecret string
+	Endpoint     string
 	TTL          string
 	Type         string // 当前记录类型
 	IPType       string
 	Value        string
 	Regex        string
+	JSONPath     string
+	ExtractRegex string
+	MatchIndex   int
+	IPv6Policy   string
+	IPv6Suffix   string
+	PrefixLength int
 }
 
 // BuildDNSConfig 从 DNSGroup 和 DNSRecord 构建 DNSConfig
@@ -125,14 +188,39 @@ func (g *DNSGroup) BuildDNSConfig(record *DNSRecord) DNSConfig {
 		Service:      g.Service,
 		AccessKey:    g.AccessKey,
 		AccessSecret: g.AccessSecret,
+		Endpoint:     g.Endpoint,
 		TTL:          g.TTL,
 		Type:         record.Type,
 		IPType:       record.IPType,
 		Value:        record.Value,
 		Regex:        record.Regex,
+		JSONPath:     record.JSONPath,
+		ExtractRegex: record.ExtractRegex,
+		MatchIndex:   record.MatchIndex,
+		IPv6Policy:   record.IPv6Policy,
+		IPv6Suffix:   record.IPv6Suffix,
+		PrefixLength: record.PrefixLength,
 	}
 }
 
+// MaskDNSGroup 返回脱敏后的 DNS 配置组副本
+func MaskDNSGroup(group DNSGroup) DNSGroup {
+	masked := DNSGroup{
+		ID:           group.ID,
+		Name:         group.Name,
+		Domain:       group.Domain,
+		Service:      group.Service,
+		AccessKey:    maskSensitiveString(group.AccessKey),
+		AccessSecret: maskSensitiveString(group.AccessSecret),
+		Endpoint:     group.Endpoint,
+		TTL:          group.TTL,
+		Records:      make([]DNSRecord, len(group.Records)),
+	}
+	// 复制记录数组
+	copy(masked.Records, group.Records)
+	return masked
+}
+
 // GetDDNSConfigJSON 将 DDNS 配置转换为 JSON 字符串（敏感信息脱敏）
 func GetDDNSConfigJSON(DDNSConf DDNSConfig) string {
 	// 如果 DDNS 数组为空，初始化为空数组而不是 null
@@ -149,18 +237,7 @@ func GetDDNSConfigJSON(DDNSConf DDNSConfig) string {
 
 	// 复制并脱敏每个 DNS 配置组
 	for i, group := range DDNSConf.DDNS {
-		maskedConf.DDNS[i] = DNSGroup{
-			ID:           group.ID,
-			Name:         group.Name,
-			Domain:       group.Domain,
-			Service:      group.Service,
-			AccessKey:    maskSensitiveString(group.AccessKey),
-			AccessSecret: maskSensitiveString(group.AccessSecret),
-			TTL:          group.TTL,
-			Records:      make([]DNSRecord, len(group.Records)),
-		}
-		// 复制记录数组
-		copy(maskedConf.DDNS[i].Records, group.Records)
+		maskedConf.DDNS[i] = MaskDNSGroup(group)
 	}
 
 	data, err := json.Marshal(maskedConf)
@@ -489,3 +489,39 @@ func TestBuildDNSConfig_DifferentRecordTypes(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+// TestDNSGroupGetEndpoint 测试自定义 API 地址的回退与规范化
+func TestDNSGroupGetEndpoint(t *testing.T) {
+	const def = "https://api.example.com"
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"", def},
+		{"   ", def},
+		{"https://proxy.example.com/", "https://proxy.example.com"},
+		{" https://proxy.example.com/api ", "https://proxy.example.com/api"},
+	}
+	for _, tt := range tests {
+		g := DNSGroup{Endpoint: tt.endpoint}
+		if got := g.GetEndpoint(def); got != tt.want {
+			t.Errorf("GetEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+// TestValidateEndpoint 测试自定义 API 地址校验
+func TestValidateEndpoint(t *testing.T) {
+	valid := []string{"", "https://alidns.ap-southeast-1.aliyuncs.com", "http://127.0.0.1:8080/api/"}
+	for _, e := range valid {
+		if err := ValidateEndpoint(e); err != nil {
+			t.Errorf("ValidateEndpoint(%q) 意外错误: %v", e, err)
+		}
+	}
+	invalid := []string{"alidns.aliyuncs.com", "ftp://example.com", "https://", "https://example.com/?a=1"}
+	for _, e := range invalid {
+		if err := ValidateEndpoint(e); err == nil {
+			t.Errorf("ValidateEndpoint(%q) 应返回错误", e)
+		}
+	}
+}
@@ -5,6 +5,7 @@ type Repository interface {
 	Load() (Config, error)
 	Save(conf *Config) error
 	ResetPassword(newPassword string) error
+	DisableTwoFactor(username string) error
 }
 
 // CachedFileRepository persists configuration through the existing cached YAML file implementation.
@@ -29,3 +30,11 @@ func (r *CachedFileRepository) ResetPassword(newPassword string) error {
 	}
 	return conf.ResetPassword(newPassword)
 }
+
+func (r *CachedFileRepository) DisableTwoFactor(username string) error {
+	conf, err := r.Load()
+	if err != nil {
+		return err
+	}
+	return conf.DisableTwoFactor(username)
+}
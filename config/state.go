@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// CacheState 单条 DDNS 记录或单个 DCDN 配置的同步状态，用于重启后恢复缓存
+type CacheState struct {
+	DynamicIPs  map[string]string `json:"dynamic_ips,omitempty"` // 最后一次成功推送的动态值：key=源唯一标识, value=IP
+	HasRun      bool              `json:"has_run"`               // 是否已成功同步过
+	TimesFailed int               `json:"times_failed,omitempty"`
+	LastSuccess time.Time         `json:"last_success"`     // 最后一次成功推送的时间
+	Halted      string            `json:"halted,omitempty"` // 服务商拒绝服务时的配置指纹
+}
+
+// State 同步状态快照，key 与 bootstrap.Runner 中的缓存键一致
+type State struct {
+	DDNS   map[string]CacheState      `json:"ddns,omitempty"`
+	DCDN   map[string]CacheState      `json:"dcdn,omitempty"`
+	Pushed map[string]helper.PushedIP `json:"pushed,omitempty"` // 通过 /nic/update 推送的地址，key=主机名:地址类型
+	Docker *GeneratedRecords          `json:"docker,omitempty"` // 由 Docker 容器标签生成的配置组
+	DHCP   *GeneratedRecords          `json:"dhcp,omitempty"`   // 由 DHCP 租约批量记录展开的配置组
+}
+
+// GeneratedRecords 自动生成（不在配置文件中）的配置组，用于在其记录消失后删除。
+// 保存在状态文件中（含服务商凭证，与配置文件一样以 0600 权限写入），D-NET 未运行期间消失的记录在下次启动后仍会删除
+type GeneratedRecords struct {
+	Groups  []DNSGroup `json:"groups,omitempty"`  // 上一轮生成的配置组
+	Pending []DNSGroup `json:"pending,omitempty"` // 删除失败、等待下一轮重试的记录，Records 只包含待删除的类型
+}
+
+// StateStore 同步状态的持久化边界
+type StateStore interface {
+	Load() (State, error)
+	Save(state State) error
+}
+
+// FileStateStore 以 JSON 文件保存同步状态，内容未变化时不重复写盘
+type FileStateStore struct {
+	path     string
+	mu       sync.Mutex
+	lastData []byte
+}
+
+// NewFileStateStore 创建基于文件的状态存储
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// GetStateFilePath 获得状态文件路径：与配置文件同目录，如 .dnet_config.yaml -> .dnet_config.state.json
+func GetStateFilePath() string {
+	return siblingFilePath(".state.json")
+}
+
+// GetHistoryFilePath 获得同步历史文件路径，如 .dnet_config.yaml -> .dnet_config.history.jsonl
+func GetHistoryFilePath() string {
+	return siblingFilePath(".history.jsonl")
+}
+
+// siblingFilePath 返回与配置文件同目录、同名但后缀不同的文件路径
+func siblingFilePath(suffix string) string {
+	configFilePath := GetConfigFilePath()
+	return strings.TrimSuffix(configFilePath, filepath.Ext(configFilePath)) + suffix
+}
+
+// Path 返回状态文件路径
+func (s *FileStateStore) Path() string {
+	return s.path
+}
+
+// Load 读取状态文件，文件不存在时返回空状态
+func (s *FileStateStore) Load() (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state State
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err = json.Unmarshal(data, &state); err != nil {
+		return State{}, err
+	}
+	s.lastData = data
+	return state, nil
+}
+
+// Save 写入状态文件（先写临时文件再重命名，避免中途退出留下损坏的文件）
+func (s *FileStateStore) Save(state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, s.lastData) {
+		return nil
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.lastData = data
+	return nil
+}
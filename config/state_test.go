@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileStateStore_LoadMissing 状态文件不存在时返回空状态
+func TestFileStateStore_LoadMissing(t *testing.T) {
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "missing.state.json"))
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("意外错误: %v", err)
+	}
+	if len(state.DDNS) != 0 || len(state.DCDN) != 0 {
+		t.Errorf("期望空状态, 实际: %+v", state)
+	}
+}
+
+// TestFileStateStore_SaveAndLoad 保存后可完整读回
+func TestFileStateStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnet.state.json")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := State{
+		DDNS: map[string]CacheState{
+			"grp-1\x1fA": {DynamicIPs: map[string]string{"https://4.ipw.cn": "1.2.3.4"}, HasRun: true, LastSuccess: now},
+		},
+		DCDN: map[string]CacheState{
+			"cdn-1": {HasRun: true, TimesFailed: 2},
+		},
+	}
+	if err := NewFileStateStore(path).Save(want); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("状态文件权限应为 0600: %v %v", info, err)
+	}
+
+	got, err := NewFileStateStore(path).Load()
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	ddns := got.DDNS["grp-1\x1fA"]
+	if !ddns.HasRun || ddns.DynamicIPs["https://4.ipw.cn"] != "1.2.3.4" || !ddns.LastSuccess.Equal(now) {
+		t.Errorf("DDNS 状态不一致: %+v", ddns)
+	}
+	if got.DCDN["cdn-1"].TimesFailed != 2 {
+		t.Errorf("DCDN 状态不一致: %+v", got.DCDN["cdn-1"])
+	}
+}
+
+// TestFileStateStore_SkipUnchanged 内容未变化时不重复写盘
+func TestFileStateStore_SkipUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnet.state.json")
+	store := NewFileStateStore(path)
+	state := State{DDNS: map[string]CacheState{"k": {HasRun: true}}}
+	if err := store.Save(state); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(state); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("内容未变化时不应重新写入文件")
+	}
+}
+
+// TestGetStateFilePath 状态文件与配置文件同目录
+func TestGetStateFilePath(t *testing.T) {
+	t.Setenv(PathENV, filepath.Join("/etc", "dnet", "config.yaml"))
+	if got, want := GetStateFilePath(), filepath.Join("/etc", "dnet", "config.state.json"); got != want {
+		t.Errorf("GetStateFilePath() = %q, want %q", got, want)
+	}
+}
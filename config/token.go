@@ -0,0 +1,120 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// API 令牌权限范围
+const (
+	ScopeReadOnly     = "read-only"     // 只读：查询配置、历史等 GET 接口
+	ScopeTriggerSync  = "trigger-sync"  // 触发同步
+	ScopeManageConfig = "manage-config" // 管理配置：增删改 DDNS / DCDN 条目（包含只读）
+	ScopePushIP       = "push-ip"       // 推送 IP：路由器通过 /nic/update 上报地址
+)
+
+// APITokenPrefix 令牌明文前缀，便于识别和密钥扫描
+const APITokenPrefix = "dnet_"
+
+// AllScopes 全部可选的权限范围
+var AllScopes = []string{ScopeReadOnly, ScopeTriggerSync, ScopeManageConfig, ScopePushIP}
+
+// APIToken 用于脚本等非交互访问的具名令牌，配置中仅保存 SHA-256 哈希
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"-"`
+	Scopes    []string  `json:"scopes"` // 为空表示拥有全部权限
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasScope 判断令牌是否拥有指定权限；manage-config 隐含只读
+func (t *APIToken) HasScope(scope string) bool {
+	if len(t.Scopes) == 0 {
+		return true
+	}
+	for _, s := range t.Scopes {
+		if s == scope || (s == ScopeManageConfig && scope == ScopeReadOnly) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIToken 生成新令牌，返回明文（只在创建时展示一次）和待保存的令牌记录
+func NewAPIToken(name string, scopes []string) (string, APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", APIToken{}, errors.New("令牌名称不能为空")
+	}
+	for _, scope := range scopes {
+		if !isValidScope(scope) {
+			return "", APIToken{}, fmt.Errorf("不支持的权限范围: %s", scope)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", APIToken{}, err
+	}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", APIToken{}, err
+	}
+	plain := APITokenPrefix + hex.EncodeToString(secret)
+	return plain, APIToken{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hashAPIToken(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// FindAPIToken 按明文查找令牌（常量时间比较哈希）
+func (conf *Config) FindAPIToken(plain string) (*APIToken, bool) {
+	if plain == "" {
+		return nil, false
+	}
+	hash := []byte(hashAPIToken(plain))
+	for i := range conf.APITokens {
+		if subtle.ConstantTimeCompare(hash, []byte(conf.APITokens[i].Hash)) == 1 {
+			return &conf.APITokens[i], true
+		}
+	}
+	return nil, false
+}
+
+// RevokeAPIToken 删除指定 ID 的令牌，返回是否存在
+func (conf *Config) RevokeAPIToken(id string) bool {
+	for i := range conf.APITokens {
+		if conf.APITokens[i].ID == id {
+			// 新建切片，避免修改配置缓存共享的底层数组
+			next := make([]APIToken, 0, len(conf.APITokens)-1)
+			next = append(next, conf.APITokens[:i]...)
+			conf.APITokens = append(next, conf.APITokens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func hashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func isValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAPITokenAndFind(t *testing.T) {
+	plain, token, err := NewAPIToken("  ansible ", []string{ScopeReadOnly})
+	if err != nil {
+		t.Fatalf("NewAPIToken returned error: %v", err)
+	}
+	if !strings.HasPrefix(plain, APITokenPrefix) || token.Name != "ansible" || token.ID == "" {
+		t.Fatalf("unexpected token: plain=%q token=%+v", plain, token)
+	}
+	if token.Hash == "" || strings.Contains(token.Hash, plain) {
+		t.Fatalf("expected only hash to be stored, got %q", token.Hash)
+	}
+
+	conf := Config{APITokens: []APIToken{token}}
+	if found, ok := conf.FindAPIToken(plain); !ok || found.ID != token.ID {
+		t.Fatalf("expected token to be found, got %+v %v", found, ok)
+	}
+	if _, ok := conf.FindAPIToken(plain + "x"); ok {
+		t.Fatal("expected wrong token to be rejected")
+	}
+	if _, ok := conf.FindAPIToken(""); ok {
+		t.Fatal("expected empty token to be rejected")
+	}
+}
+
+func TestNewAPITokenValidation(t *testing.T) {
+	if _, _, err := NewAPIToken("", nil); err == nil {
+		t.Fatal("expected empty name to be rejected")
+	}
+	if _, _, err := NewAPIToken("ci", []string{"admin"}); err == nil {
+		t.Fatal("expected unknown scope to be rejected")
+	}
+}
+
+func TestAPITokenHasScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{nil, ScopeManageConfig, true},
+		{[]string{ScopeReadOnly}, ScopeReadOnly, true},
+		{[]string{ScopeReadOnly}, ScopeManageConfig, false},
+		{[]string{ScopeReadOnly}, ScopeTriggerSync, false},
+		{[]string{ScopeManageConfig}, ScopeReadOnly, true},
+		{[]string{ScopeTriggerSync}, ScopeReadOnly, false},
+	}
+	for _, tt := range tests {
+		token := APIToken{Scopes: tt.scopes}
+		if got := token.HasScope(tt.scope); got != tt.want {
+			t.Errorf("HasScope(%v, %s) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestRevokeAPITokenDoesNotMutateSharedSlice(t *testing.T) {
+	shared := []APIToken{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	conf := Config{APITokens: shared}
+	if !conf.RevokeAPIToken("b") {
+		t.Fatal("expected token b to be revoked")
+	}
+	if len(conf.APITokens) != 2 || conf.APITokens[1].ID != "c" {
+		t.Fatalf("unexpected tokens after revoke: %+v", conf.APITokens)
+	}
+	if shared[1].ID != "b" {
+		t.Fatalf("expected shared slice untouched, got %+v", shared)
+	}
+	if conf.RevokeAPIToken("missing") {
+		t.Fatal("expected missing token to report false")
+	}
+}
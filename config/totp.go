@@ -0,0 +1,210 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// TOTP 参数（RFC 6238 默认值，主流验证器 App 均支持）
+const (
+	TOTPDigits       = 6
+	TOTPPeriod       = 30 * time.Second
+	TOTPSkew         = 1 // 允许前后各 1 个时间窗口的时钟误差
+	TOTPIssuer       = "D-NET"
+	RecoveryCodeSize = 10 // 恢复码数量
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactor 两步验证配置，Secret 为空表示未启用；恢复码仅保存 SHA-256 哈希，每个只能使用一次
+type TwoFactor struct {
+	Secret        string   `json:"-"`
+	RecoveryCodes []string `json:"-"`
+	// LastCounter 最近一次通过验证的 TOTP 时间窗口，不大于它的验证码视为重放（RFC 6238 §5.2）
+	LastCounter int64 `json:"-"`
+}
+
+// Enabled 是否已启用两步验证
+func (tf TwoFactor) Enabled() bool {
+	return tf.Secret != ""
+}
+
+// GenerateTOTPSecret 生成 160 位随机密钥（Base32 编码）
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI 生成验证器 App 可识别的 otpauth URI
+func TOTPURI(username, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", TOTPIssuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(TOTPDigits))
+	values.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	label := url.PathEscape(TOTPIssuer + ":" + username)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+// TOTPCode 计算指定时间的验证码
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(t.Unix()/int64(TOTPPeriod.Seconds()))), nil
+}
+
+// VerifyTOTP 校验验证码，允许 TOTPSkew 个时间窗口的误差
+func VerifyTOTP(secret, code string, t time.Time) bool {
+	_, ok := matchTOTP(secret, code, t)
+	return ok
+}
+
+// matchTOTP 校验验证码，返回匹配的时间窗口
+func matchTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	counter := t.Unix() / int64(TOTPPeriod.Seconds())
+	var matchedCounter int64
+	matched := 0
+	for i := -TOTPSkew; i <= TOTPSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(counter+int64(i)))), []byte(code)) == 1 {
+			matched, matchedCounter = 1, counter+int64(i)
+		}
+	}
+	return matchedCounter, matched == 1
+}
+
+// GenerateRecoveryCodes 生成恢复码，返回明文（只展示一次）和待保存的哈希
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	plain := make([]string, 0, RecoveryCodeSize)
+	hashed := make([]string, 0, RecoveryCodeSize)
+	for i := 0; i < RecoveryCodeSize; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(raw)
+		code = code[:5] + "-" + code[5:]
+		plain = append(plain, code)
+		hashed = append(hashed, hashRecoveryCode(code))
+	}
+	return plain, hashed, nil
+}
+
+// VerifyTOTP 校验验证码，每个时间窗口的验证码只能使用一次；
+// 通过时返回记录了该时间窗口的新配置，调用方需保存
+func (tf TwoFactor) VerifyTOTP(code string, now time.Time) (TwoFactor, bool) {
+	counter, ok := matchTOTP(tf.Secret, code, now)
+	if !ok || counter <= tf.LastCounter {
+		return tf, false
+	}
+	tf.LastCounter = counter
+	return tf, true
+}
+
+// Verify 校验验证码或恢复码；通过时返回记录了已用验证码或移除了该恢复码的新配置
+func (tf TwoFactor) Verify(code string, now time.Time) (TwoFactor, bool) {
+	if !tf.Enabled() {
+		return tf, false
+	}
+	if next, ok := tf.VerifyTOTP(code, now); ok {
+		return next, true
+	}
+	hash := []byte(hashRecoveryCode(code))
+	for i, stored := range tf.RecoveryCodes {
+		if subtle.ConstantTimeCompare(hash, []byte(stored)) == 1 {
+			// 新建切片，避免修改配置缓存共享的底层数组
+			codes := make([]string, 0, len(tf.RecoveryCodes)-1)
+			codes = append(codes, tf.RecoveryCodes[:i]...)
+			tf.RecoveryCodes = append(codes, tf.RecoveryCodes[i+1:]...)
+			return tf, true
+		}
+	}
+	return tf, false
+}
+
+// SetAccountTwoFactor 更新账号的两步验证配置，返回账号是否存在
+func (conf *Config) SetAccountTwoFactor(username string, tf TwoFactor) bool {
+	if conf.Username != "" && conf.Username == username {
+		conf.User.TwoFactor = tf
+		return true
+	}
+	for i := range conf.Accounts {
+		if conf.Accounts[i].Username == username {
+			accounts := append([]Account(nil), conf.Accounts...)
+			accounts[i].TwoFactor = tf
+			conf.Accounts = accounts
+			return true
+		}
+	}
+	return false
+}
+
+// DisableTwoFactor 关闭指定账号的两步验证并保存（用于验证器丢失时从命令行恢复）
+func (conf *Config) DisableTwoFactor(username string) error {
+	account, ok := conf.FindAccount(username)
+	if !ok {
+		return fmt.Errorf("账号 %s 不存在", username)
+	}
+	if !account.TwoFactor.Enabled() {
+		return fmt.Errorf("账号 %s 未启用两步验证", username)
+	}
+	conf.SetAccountTwoFactor(username, TwoFactor{})
+	if err := conf.SaveConfig(); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	helper.Info(helper.LogTypeConfig, "已关闭账号 %s 的两步验证", username)
+	return nil
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	key, err := totpEncoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, errors.New("无效的 TOTP 密钥")
+	}
+	return key, nil
+}
+
+// hotp RFC 4226 HMAC-SHA1 动态截断
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000)
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTOTPCode_RFC6238 使用 RFC 6238 附录 B 的 SHA1 测试向量（取后 6 位）
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, _ := TOTPCode(secret, now)
+
+	if !VerifyTOTP(secret, code, now) {
+		t.Error("当前验证码验证失败")
+	}
+	if !VerifyTOTP(secret, code, now.Add(TOTPPeriod)) {
+		t.Error("允许一个时间窗口的误差")
+	}
+	if VerifyTOTP(secret, code, now.Add(3*TOTPPeriod)) {
+		t.Error("过期的验证码不应通过")
+	}
+	if VerifyTOTP(secret, "", now) || VerifyTOTP("not base32!", code, now) {
+		t.Error("空验证码或无效密钥不应通过")
+	}
+}
+
+func TestTwoFactorVerifyConsumesRecoveryCode(t *testing.T) {
+	secret, _ := GenerateTOTPSecret()
+	plain, hashed, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(plain) != RecoveryCodeSize || strings.Contains(strings.Join(hashed, ""), plain[0]) {
+		t.Fatalf("unexpected recovery codes: %v %v", plain, hashed)
+	}
+	tf := TwoFactor{Secret: secret, RecoveryCodes: hashed}
+
+	next, ok := tf.Verify(strings.ToUpper(plain[3]), time.Now())
+	if !ok || len(next.RecoveryCodes) != RecoveryCodeSize-1 {
+		t.Fatalf("恢复码验证失败: %v, 剩余 %d", ok, len(next.RecoveryCodes))
+	}
+	if len(tf.RecoveryCodes) != RecoveryCodeSize || tf.RecoveryCodes[3] != hashed[3] {
+		t.Error("不应修改原配置的恢复码")
+	}
+	if _, ok = next.Verify(plain[3], time.Now()); ok {
+		t.Error("恢复码只能使用一次")
+	}
+}
+
+func TestTwoFactorVerifyRejectsReplayedCode(t *testing.T) {
+	secret, _ := GenerateTOTPSecret()
+	now := time.Unix(1700000000, 0)
+	code, _ := TOTPCode(secret, now)
+	tf := TwoFactor{Secret: secret}
+
+	next, ok := tf.Verify(code, now)
+	if !ok {
+		t.Fatal("首次使用验证码应通过")
+	}
+	if _, ok = next.Verify(code, now); ok {
+		t.Error("同一验证码不应再次通过")
+	}
+	if _, ok = next.Verify(code, now.Add(TOTPPeriod)); ok {
+		t.Error("时间窗口误差范围内重放也不应通过")
+	}
+	// 更早时间窗口的验证码同样拒绝
+	previous, _ := TOTPCode(secret, now.Add(-TOTPPeriod))
+	if _, ok = next.Verify(previous, now); ok {
+		t.Error("早于已用时间窗口的验证码不应通过")
+	}
+	following, _ := TOTPCode(secret, now.Add(TOTPPeriod))
+	if _, ok = next.Verify(following, now.Add(TOTPPeriod)); !ok {
+		t.Error("下一个时间窗口的验证码应通过")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("admin", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/D-NET:admin?") || !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=D-NET") {
+		t.Errorf("TOTPURI() = %s", uri)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 type User struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	TwoFactor TwoFactor `json:"-"`
 }
@@ -13,10 +13,33 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
+func init() {
+	Register(ProviderAliyun, func() CDN { return &Aliyun{} }, ProviderSchema{
+		Name:         "阿里云",
+		IDLabel:      "AccessKey ID：",
+		SecretLabel:  "AccessKey Secret：",
+		TypeSelect:   []string{CDNTypeESA, CDNTypeCDN, CDNTypeDCDN},
+		IDHelpHTML:   "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>创建 AccessKey</a>",
+		TypeHelpHTML: "<a target='_blank' href='https://esa.console.aliyun.com'>ESA</a>、<a target='_blank' href='https://cdn.console.aliyun.com/overview'>CDN</a>、<a target='_blank' href='https://dcdn.console.aliyun.com/#/overview'>DCDN</a>",
+		MaxSources:   []int{1, 20, 20},
+		ProtocolTipHTML: []string{
+			"<tip>阿里云 ESA 类型不支持自定义端口</tip>",
+			"<tip>阿里云 CDN 类型不支持自定义 HTTPS 端口</tip>",
+			"<tip>阿里云 DCDN 类型不支持自定义 HTTPS 端口</tip>",
+		},
+		RequireKey:     true,
+		RequireSecret:  true,
+		RequireType:    true,
+		CustomEndpoint: true,
+		Order:          10,
+	})
+}
+
+// 各产品的默认 API 地址，可通过 CDN.Endpoint 覆盖（如 esa.ap-southeast-1.aliyuncs.com）
 const (
-	aliyunCDNEndpoint  string = "https://cdn.aliyuncs.com/"
-	aliyunDCDNEndpoint string = "https://dcdn.aliyuncs.com/"
-	aliyunESAEndpoint  string = "https://esa.cn-hangzhou.aliyuncs.com/"
+	aliyunCDNEndpoint  string = "https://cdn.aliyuncs.com"
+	aliyunDCDNEndpoint string = "https://dcdn.aliyuncs.com"
+	aliyunESAEndpoint  string = "https://esa.cn-hangzhou.aliyuncs.com"
 )
 
 type Aliyun struct {
@@ -685,7 +708,7 @@ func (aliyun *Aliyun) request(method string, params url.Values, result interface
 
 	req, err := http.NewRequest(
 		method,
-		endpoint,
+		aliyun.CDN.GetEndpoint(endpoint)+"/",
 		bytes.NewBuffer(nil),
 	)
 	if err != nil {
@@ -13,8 +13,28 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
+func init() {
+	Register(ProviderBaiduCloud, func() CDN { return &Baidu{} }, ProviderSchema{
+		Name:         "百度智能云",
+		IDLabel:      "AccessKey ID：",
+		SecretLabel:  "AccessKey Secret：",
+		TypeSelect:   []string{CDNTypeCDN, CDNTypeDRCDN},
+		IDHelpHTML:   "<a target='_blank' href='https://console.bce.baidu.com/iam/?_=1651763238057#/iam/accesslist'>创建 AccessKey </a>",
+		TypeHelpHTML: "<a target='_blank' href='https://console.bce.baidu.com/cdn#/cdn/list'>CDN</a>、<a target='_blank' href='https://console.bce.baidu.com/cdn#/cdn/list'>DRCDN</a>",
+		MaxSources:   []int{10, 10},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+			"<tip></tip>",
+		},
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          20,
+	})
+}
+
 const (
-	baiduCDNEndpoint string = "https://cdn.baidubce.com"
+	baiduCDNEndpoint string = "https://cdn.baidubce.com" // 默认地址，可通过 CDN.Endpoint 覆盖
 )
 
 type Baidu struct {
@@ -378,7 +398,7 @@ func (baidu *Baidu) modifyCDN() {
 
 // request 统一请求接口
 func (baidu *Baidu) request(method, path string, body interface{}, result interface{}) error {
-	endpoint := baiduCDNEndpoint + path
+	endpoint := baidu.CDN.GetEndpoint(baiduCDNEndpoint) + path
 	jsonStr := make([]byte, 0)
 	if body != nil {
 		jsonStr, _ = json.Marshal(body)
@@ -1,6 +1,8 @@
 package dcdn
 
 import (
+	"time"
+
 	"github.com/cxbdasheng/dnet/config"
 	"github.com/cxbdasheng/dnet/helper"
 )
@@ -168,6 +170,10 @@ func (b *BaseProvider) runUpdateOrCreate(providerName string, doUpdate func()) b
 		helper.Info(helper.LogTypeDCDN, "开始更新 %s 配置 [域名=%s, IP变化数=%d, 计数器=%d]",
 			providerName, b.CDN.Domain, changedIPCount, b.Cache.Times)
 		doUpdate()
+		b.Cache.pushFailed = b.Status != UpdatedSuccess
+		if !b.Cache.pushFailed {
+			b.Cache.LastSuccess = time.Now()
+		}
 		b.Cache.ResetTimes()
 		return true
 	}
@@ -49,6 +49,14 @@ func TestGetSourceCacheKey(t *testing.T) {
 		}
 	})
 
+	t.Run("同一前缀来源不同主机后缀产生不同 key", func(t *testing.T) {
+		s1 := &config.Source{Type: helper.DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "::1"}
+		s2 := &config.Source{Type: helper.DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "::2"}
+		if getSourceCacheKey(s1) == getSourceCacheKey(s2) {
+			t.Errorf("不同主机后缀的 key 应不同：%q vs %q", getSourceCacheKey(s1), getSourceCacheKey(s2))
+		}
+	})
+
 	t.Run("非 IPv6 网卡类型忽略 regex", func(t *testing.T) {
 		s1 := &config.Source{Type: helper.DynamicIPv4Interface, Value: "eth0", Regex: "@1"}
 		s2 := &config.Source{Type: helper.DynamicIPv4Interface, Value: "eth0", Regex: "@2"}
@@ -470,3 +478,33 @@ func TestIsDomainType(t *testing.T) {
 		})
 	}
 }
+
+// TestCache_StateRoundTrip 验证缓存状态导出与恢复
+func TestCache_StateRoundTrip(t *testing.T) {
+	cache := NewCache()
+	cache.UpdateDynamicIP("https://4.ipw.cn", "1.2.3.4")
+	cache.HasRun = true
+	cache.TimesFailed = 1
+
+	restored := NewCache()
+	restored.RestoreState(cache.State())
+	if !restored.HasRun || restored.TimesFailed != 1 {
+		t.Errorf("HasRun/TimesFailed 未恢复: %+v", restored.State())
+	}
+	if changed, _ := restored.CheckIPChanged("https://4.ipw.cn", "1.2.3.4"); changed {
+		t.Error("恢复后相同 IP 不应视为变化")
+	}
+	if restored.Times != cache.Times {
+		t.Errorf("计数器应保持初始值: %d", restored.Times)
+	}
+}
+
+// TestCache_StateAfterFailedPush 推送失败后导出的状态不标记为已运行
+func TestCache_StateAfterFailedPush(t *testing.T) {
+	cache := NewCache()
+	cache.HasRun = true
+	cache.pushFailed = true
+	if cache.State().HasRun {
+		t.Error("推送失败时不应标记为已运行，重启后需重新比对")
+	}
+}
@@ -11,6 +11,23 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderCallback, func() CDN { return &Callback{} }, ProviderSchema{
+		Name:         "Callback",
+		IDLabel:      "Callback URL：",
+		SecretLabel:  "请求内容 RequestBody（可选）：",
+		TypeSelect:   []string{"CALLBACK"},
+		IDHelpHTML:   "<tip><a target='_blank' href='https://github.com/cxbdasheng/dnet/wiki/DCDN-%E4%BD%BF%E7%94%A8%E6%8C%87%E5%8D%97#%E8%87%AA%E5%AE%9A%E4%B9%89%E5%9B%9E%E8%B0%83callback'>自定义回调</a>  支持变量：#{domain} #{ips}（逗号分隔地址） #{sources}（源站 JSON 数组）。RequestBody 为空发 GET，否则发 POST。</tip>",
+		TypeHelpHTML: "<tip>源站 IP 变化时向回调 URL 推送，用于对接自建 CDN 或内部接口</tip>",
+		MaxSources:   []int{20},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+		},
+		RequireKey: true,
+		Order:      60,
+	})
+}
+
 // Callback 自定义回调 CDN 提供商。
 // 不对接具体云厂商，而是在源站 IP 发生变化（或触发强制更新）时，
 // 向用户配置的 URL 发起一次 HTTP 请求，用于对接自建 CDN、负载均衡或内部接口。
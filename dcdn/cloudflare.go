@@ -11,8 +11,27 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderCloudflare, func() CDN { return &Cloudflare{} }, ProviderSchema{
+		Name:         "Cloudflare",
+		IDLabel:      "API Token：",
+		TypeSelect:   []string{CDNTypeCDN, "DNS"},
+		IDHelpHTML:   "<a target='_blank' href='https://dash.cloudflare.com/profile/api-tokens'>创建 API 令牌 -> 编辑区域 DNS (使用模板)</a>",
+		TypeHelpHTML: "<strong>CDN</strong>：开启代理，流量经过 Cloudflare 加速；<strong>DNS</strong>：仅 DNS 解析，流量不经过 Cloudflare",
+		MaxSources:   []int{1, 1},
+		ProtocolTipHTML: []string{
+			"<tip>Cloudflare 仅支持单个源站</tip>",
+			"<tip>Cloudflare 仅支持单个源站</tip>",
+		},
+		RequireKey:     true,
+		RequireType:    true,
+		CustomEndpoint: true,
+		Order:          40,
+	})
+}
+
 const (
-	cloudflareAPIEndpoint = "https://api.cloudflare.com/client/v4"
+	cloudflareAPIEndpoint = "https://api.cloudflare.com/client/v4" // 默认地址，可通过 CDN.Endpoint 覆盖（如经由出口代理）
 )
 
 type Cloudflare struct {
@@ -131,7 +150,7 @@ func (cf *Cloudflare) request(method, path string, body interface{}, result inte
 		}
 	}
 
-	req, err := http.NewRequest(method, cloudflareAPIEndpoint+path, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequest(method, cf.CDN.GetEndpoint(cloudflareAPIEndpoint)+path, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return err
 	}
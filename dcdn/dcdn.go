@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/cxbdasheng/dnet/config"
 	"github.com/cxbdasheng/dnet/helper"
@@ -11,13 +12,33 @@ import (
 
 const CacheTimesENV = "DCDN_CACHE_TIMES"
 
+// CDN 服务提供商常量
+const (
+	ProviderAliyun     = "aliyun"     // 阿里云（CDN、DCDN、ESA）
+	ProviderBaiduCloud = "baiducloud" // 百度云（CDN、DRCDN）
+	ProviderTencent    = "tencent"    // 腾讯云（CDN、EdgeOne）
+	ProviderCloudflare = "cloudflare" // Cloudflare
+	ProviderUpyun      = "upyun"      // 又拍云
+	ProviderCallback   = "callback"   // 自定义回调（HTTP GET/POST）
+	ProviderExecPlugin = "exec"       // 外部插件（JSON over stdin/stdout）
+	ProviderMock       = "mock"       // 模拟测试（不发起真实请求）
+)
+
 var dynamicTypes = map[string]bool{
 	helper.DynamicIPv4URL:       true,
 	helper.DynamicIPv4Interface: true,
 	helper.DynamicIPv4Command:   true,
 	helper.DynamicIPv6URL:       true,
 	helper.DynamicIPv6Interface: true,
+	helper.DynamicIPv6Neighbor:  true,
 	helper.DynamicIPv6Command:   true,
+	helper.DynamicIPv4Stun:      true,
+	helper.DynamicIPv6Stun:      true,
+	helper.DynamicIPv4DNS:       true,
+	helper.DynamicIPv6DNS:       true,
+	helper.DynamicIPv4Gateway:   true,
+	helper.PushedIPv4:           true,
+	helper.PushedIPv6:           true,
 }
 
 var ForceCompareGlobal = true
@@ -51,6 +72,8 @@ type Cache struct {
 	DynamicIPs  map[string]string // 动态 IP 缓存: key=source唯一标识(type:value), value=获取到的IP
 	mu          sync.RWMutex      // 保护 DynamicIPs 的读写锁
 	HasRun      bool              //
+	LastSuccess time.Time         // 最后一次成功推送的时间
+	pushFailed  bool              // 最近一次推送失败，DynamicIPs 中的值尚未生效
 }
 
 type CDN interface {
@@ -88,20 +111,31 @@ func IsDynamicType(sourceType string) bool {
 	return dynamicTypes[sourceType]
 }
 
-// getSourceCacheKey 获取源站 IP 缓存键（仅 IPv6 网卡且 regex 非空时使用 regex-aware key）
+// getSourceCacheKey 获取源站 IP 缓存键（IPv6 网卡带上 regex 和选择策略，接口 / 命令来源带上提取方式，配置主机后缀时带上后缀）
 func getSourceCacheKey(source *config.Source) string {
-	if source.Type == helper.DynamicIPv6Interface && source.Regex != "" {
-		return helper.GetIPCacheKeyWithRegex(source.Type, source.Value, source.Regex)
+	var key string
+	if helper.SupportsIPv6Policy(source.Type) && (source.Regex != "" || source.IPv6Policy != "") {
+		key = helper.GetIPCacheKeyWithPolicy(source.Type, source.Value, source.Regex, source.IPv6Policy)
+	} else {
+		key = helper.GetIPCacheKeyWithExtract(source.Type, source.Value, source.AddrExtract())
 	}
-	return helper.GetIPCacheKey(source.Type, source.Value)
+	return helper.GetIPCacheKeyWithSuffix(key, source.Type, source.IPv6Suffix, source.PrefixLength)
 }
 
-// getOrSetSourceIP 获取或设置动态 IP（仅 IPv6 网卡且 regex 非空时走带 regex 的版本）
+// getOrSetSourceIP 获取或设置动态 IP（IPv6 网卡按 regex 和选择策略获取，接口 / 命令来源按提取方式获取；
+// 配置主机后缀时以获取到的地址为前缀组合出完整地址）
 func getOrSetSourceIP(source *config.Source) (string, bool) {
-	if source.Type == helper.DynamicIPv6Interface && source.Regex != "" {
-		return helper.GetOrSetDynamicIPWithCacheAndRegex(source.Type, source.Value, source.Regex)
+	var addr string
+	var ok bool
+	if helper.SupportsIPv6Policy(source.Type) && (source.Regex != "" || source.IPv6Policy != "") {
+		addr, ok = helper.GetOrSetDynamicIPWithPolicy(source.Type, source.Value, source.Regex, source.IPv6Policy)
+	} else {
+		addr, ok = helper.GetOrSetDynamicIPWithExtract(source.Type, source.Value, source.AddrExtract())
 	}
-	return helper.GetOrSetDynamicIPWithCache(source.Type, source.Value)
+	if !ok {
+		return "", false
+	}
+	return helper.ApplyIPv6Suffix(source.Type, addr, source.IPv6Suffix, source.PrefixLength)
 }
 
 // IsDomainType 判断 sourceType 是否为域名类型
@@ -134,6 +168,30 @@ func (c *Cache) UpdateDynamicIP(sourceKey string, newIP string) {
 	c.DynamicIPs[sourceKey] = newIP
 }
 
+// State 导出可持久化的缓存状态
+// 最近一次推送失败时不标记为已运行，重启后会重新与服务商比对
+func (c *Cache) State() config.CacheState {
+	return config.CacheState{
+		DynamicIPs:  c.GetDynamicIPs(),
+		HasRun:      c.HasRun && !c.pushFailed,
+		TimesFailed: c.TimesFailed,
+		LastSuccess: c.LastSuccess,
+	}
+}
+
+// RestoreState 从持久化状态恢复缓存，计数器保持初始值
+func (c *Cache) RestoreState(state config.CacheState) {
+	c.mu.Lock()
+	c.DynamicIPs = make(map[string]string, len(state.DynamicIPs))
+	for k, v := range state.DynamicIPs {
+		c.DynamicIPs[k] = v
+	}
+	c.mu.Unlock()
+	c.HasRun = state.HasRun
+	c.TimesFailed = state.TimesFailed
+	c.LastSuccess = state.LastSuccess
+}
+
 // ResetTimes 重置计数器
 func (c *Cache) ResetTimes() {
 	times, err := strconv.Atoi(os.Getenv(CacheTimesENV))
@@ -5,6 +5,21 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderMock, func() CDN { return &Mock{} }, ProviderSchema{
+		Name:         "模拟测试",
+		TypeSelect:   []string{"MOCK"},
+		IDHelpHTML:   "<tip>模拟测试不发起真实请求，用于验证配置与 Webhook 通道</tip>",
+		TypeHelpHTML: "<tip>所有操作仅打印日志，不调用云商 API</tip>",
+		MaxSources:   []int{10},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+		},
+		Order:  1000,
+		Hidden: true,
+	})
+}
+
 // Mock 模拟测试 CDN 提供商——不发起任何真实 API 请求，
 // 仅走完整状态机并打印"若在真实环境将做什么"。
 // 用途：验证配置、观察 IP 探测流程、测试 Webhook 通道。
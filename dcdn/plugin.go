@@ -0,0 +1,128 @@
+package dcdn
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func init() {
+	Register(ProviderExecPlugin, func() CDN { return &ExecPlugin{} }, ProviderSchema{
+		Name:         "Exec 插件",
+		IDLabel:      "插件命令：",
+		SecretLabel:  "插件凭证（可选）：",
+		TypeSelect:   []string{"EXEC"},
+		IDHelpHTML:   "<tip>启动外部程序对接自建 CDN：通过 stdin 发送 JSON 请求（upsert），从 stdout 读取逐个源站的结果。凭证与自定义 API 地址会原样透传给插件。</tip>",
+		TypeHelpHTML: "<tip>源站 IP 变化时调用插件，插件返回的 cname 会写回配置</tip>",
+		MaxSources:   []int{20},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+		},
+		RequireKey:     true,
+		CustomEndpoint: true,
+		Order:          65,
+	})
+}
+
+// ExecPlugin 外部插件 CDN 提供商。
+// 源站 IP 发生变化（或触发强制更新）时，以 upsert 把全部源站发送给插件，
+// 插件按 id 返回每个源站的结果，任一源站缺少结果或未返回 updated / unchanged 即视为本轮更新失败；更新明细、Webhook 与同步历史与内置提供商一致。
+//
+// 字段映射：
+//   - AccessKey    -> 插件命令（必填，经 shell 执行，可带参数）
+//   - AccessSecret -> 插件凭证（可选，透传为请求中的 secret）
+//   - Endpoint     -> 自定义 API 地址（可选，透传为请求中的 endpoint）
+type ExecPlugin struct {
+	BaseProvider
+}
+
+// Init 初始化（AccessKey=插件命令，AccessSecret=插件凭证，可选）
+func (p *ExecPlugin) Init(cdnConfig *config.CDN, cache *Cache) {
+	p.CDN = cdnConfig
+	p.Cache = cache
+	p.Status = InitFailed
+
+	if p.validateConfig() {
+		p.Status = InitSuccess
+		helper.Info(helper.LogTypeDCDN, "Exec 插件初始化成功 [域名=%s, 源站数量=%d]", cdnConfig.Domain, len(cdnConfig.Sources))
+	} else {
+		helper.Error(helper.LogTypeDCDN, "Exec 插件初始化失败：配置校验不通过")
+	}
+}
+
+// validateConfig 校验配置（只需 AccessKey=插件命令、域名和源站）
+func (p *ExecPlugin) validateConfig() bool {
+	if p.CDN == nil {
+		helper.Warn(helper.LogTypeDCDN, "Exec 插件配置校验失败：配置对象为空")
+		return false
+	}
+	if strings.TrimSpace(p.CDN.AccessKey) == "" {
+		helper.Warn(helper.LogTypeDCDN, "Exec 插件配置校验失败：插件命令为空 [域名=%s]", p.CDN.Domain)
+		return false
+	}
+	if p.CDN.Domain == "" {
+		helper.Warn(helper.LogTypeDCDN, "Exec 插件配置校验失败：域名为空")
+		return false
+	}
+	if len(p.CDN.Sources) == 0 {
+		helper.Warn(helper.LogTypeDCDN, "Exec 插件配置校验失败：源站配置为空 [域名=%s]", p.CDN.Domain)
+		return false
+	}
+	return true
+}
+
+func (p *ExecPlugin) UpdateOrCreateSources() bool {
+	return p.runUpdateOrCreate("Exec 插件", p.doUpsert)
+}
+
+// doUpsert 调用插件更新全部源站
+func (p *ExecPlugin) doUpsert() {
+	records := make([]helper.PluginRecord, 0, len(p.CDN.Sources))
+	for i := range p.CDN.Sources {
+		source := &p.CDN.Sources[i]
+		records = append(records, helper.PluginRecord{
+			ID:        strconv.Itoa(i),
+			Type:      source.Type,
+			Value:     p.getSourceAddr(source),
+			Priority:  source.Priority,
+			Weight:    source.Weight,
+			Port:      source.Port,
+			HttpsPort: source.HttpsPort,
+			Protocol:  source.Protocol,
+		})
+	}
+
+	response, err := helper.RunPlugin(p.CDN.AccessKey, helper.PluginTimeout(), helper.PluginRequest{
+		Kind:     "dcdn",
+		Action:   helper.PluginActionUpsert,
+		Domain:   p.CDN.Domain,
+		CDNType:  p.CDN.CDNType,
+		Secret:   p.CDN.AccessSecret,
+		Endpoint: p.CDN.Endpoint,
+		Records:  records,
+	})
+	if err != nil {
+		p.Status = UpdatedFailed
+		helper.Error(helper.LogTypeDCDN, "[Exec 插件] 更新失败 [域名=%s, 错误=%v]", p.CDN.Domain, err)
+		return
+	}
+	for _, record := range records {
+		result, ok := response.Result(record)
+		switch {
+		case !ok:
+			p.Status = UpdatedFailed
+			helper.Error(helper.LogTypeDCDN, "[Exec 插件] 插件未返回源站的结果 [域名=%s, 源站=%s]", p.CDN.Domain, record.Value)
+			return
+		case result.Status != helper.PluginStatusUpdated && result.Status != helper.PluginStatusUnchanged:
+			p.Status = UpdatedFailed
+			helper.Error(helper.LogTypeDCDN, "[Exec 插件] 源站更新失败 [域名=%s, 源站=%s, 状态=%s, 错误=%s]", p.CDN.Domain, record.Value, result.Status, result.Error)
+			return
+		}
+	}
+
+	p.updateCnameIfChanged(response.CName)
+	p.Status = UpdatedSuccess
+	helper.Info(helper.LogTypeDCDN, "[Exec 插件] 更新成功 [域名=%s]", p.CDN.Domain)
+}
@@ -0,0 +1,106 @@
+package dcdn
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+// newExecPluginCDN 写入返回固定响应的插件脚本，并构造测试配置（两个静态源站）
+// 脚本把收到的请求写入 request.json
+func newExecPluginCDN(t *testing.T, response string) (*config.CDN, *Cache, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("插件脚本测试依赖 sh")
+	}
+	dir := t.TempDir()
+	plugin := filepath.Join(dir, "plugin.sh")
+	script := "#!/bin/sh\ncat > \"$(dirname \"$0\")/request.json\"\necho '" + response + "'\n"
+	if err := os.WriteFile(plugin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cdn := &config.CDN{
+		Domain:    "cdn.example.com",
+		Service:   ProviderExecPlugin,
+		AccessKey: plugin,
+		CDNType:   "EXEC",
+		Sources: []config.Source{
+			{Type: "ipv4", Value: "1.2.3.4", Priority: "1", Weight: "10", Port: "80", Protocol: "http"},
+			{Type: "ipv4", Value: "5.6.7.8", Priority: "2", Weight: "20", Port: "80", Protocol: "https"},
+		},
+	}
+	c := NewCache()
+	return cdn, &c, filepath.Join(dir, "request.json")
+}
+
+func TestDCDNExecPluginUpsert(t *testing.T) {
+	cdn, cache, requestFile := newExecPluginCDN(t, `{"results":[{"type":"ipv4","status":"updated"}],"cname":"cdn.example.com.edge.net"}`)
+	p := &ExecPlugin{}
+	p.Init(cdn, cache)
+	p.UpdateOrCreateSources()
+
+	if p.GetServiceStatus() != string(UpdatedSuccess) {
+		t.Fatalf("期望成功, 实际状态: %s", p.GetServiceStatus())
+	}
+	if !p.ConfigChanged() || cdn.CName != "cdn.example.com.edge.net" {
+		t.Errorf("插件返回的 CNAME 应写回配置, 实际: %s", cdn.CName)
+	}
+	data, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := string(data)
+	for _, want := range []string{`"kind":"dcdn"`, `"action":"upsert"`, `"cdn_type":"EXEC"`, `"value":"5.6.7.8"`, `"weight":"20"`} {
+		if !strings.Contains(request, want) {
+			t.Errorf("请求中缺少 %s: %s", want, request)
+		}
+	}
+}
+
+// TestDCDNExecPluginMissingResult 按 id 对应源站结果，缺少结果的源站视为失败
+func TestDCDNExecPluginMissingResult(t *testing.T) {
+	for _, response := range []string{
+		`{}`,
+		`{"results":[{"id":"0","type":"ipv4","status":"updated"}]}`,
+		`{"results":[{"id":"0","type":"ipv4","status":"updated"},{"id":"1","type":"ipv4","status":"failed","error":"origin rejected"}]}`,
+	} {
+		cdn, cache, _ := newExecPluginCDN(t, response)
+		p := &ExecPlugin{}
+		p.Init(cdn, cache)
+		p.UpdateOrCreateSources()
+
+		if p.GetServiceStatus() != string(UpdatedFailed) {
+			t.Errorf("响应 %s 应失败, 实际状态: %s", response, p.GetServiceStatus())
+		}
+	}
+
+	cdn, cache, requestFile := newExecPluginCDN(t, `{"results":[{"id":"1","type":"ipv4","status":"unchanged"},{"id":"0","type":"ipv4","status":"updated"}]}`)
+	p := &ExecPlugin{}
+	p.Init(cdn, cache)
+	p.UpdateOrCreateSources()
+	if p.GetServiceStatus() != string(UpdatedSuccess) {
+		t.Errorf("全部源站返回结果时应成功, 实际状态: %s", p.GetServiceStatus())
+	}
+	data, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `{"id":"1","type":"ipv4","value":"5.6.7.8"`) {
+		t.Errorf("请求中的源站应带 id: %s", data)
+	}
+}
+
+func TestDCDNExecPluginFailed(t *testing.T) {
+	cdn, cache, _ := newExecPluginCDN(t, `{"results":[{"type":"ipv4","status":"failed","error":"origin rejected"}]}`)
+	p := &ExecPlugin{}
+	p.Init(cdn, cache)
+	p.UpdateOrCreateSources()
+
+	if p.GetServiceStatus() != string(UpdatedFailed) {
+		t.Errorf("源站返回 failed 时应失败, 实际状态: %s", p.GetServiceStatus())
+	}
+}
@@ -0,0 +1,118 @@
+package dcdn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// ProviderSchema 提供商的配置说明，Web 界面与配置校验共用
+type ProviderSchema struct {
+	Name            string   `json:"name"`            // 显示名称
+	IDLabel         string   `json:"idLabel"`         // AccessKey 的含义，为空表示不使用
+	SecretLabel     string   `json:"secretLabel"`     // AccessSecret 的含义，为空表示不使用
+	TypeSelect      []string `json:"typeSelect"`      // 可选的 CDN 类型
+	IDHelpHTML      string   `json:"idHelpHtml"`      // 凭证获取说明（HTML）
+	TypeHelpHTML    string   `json:"typeHelpHtml"`    // 类型说明（HTML）
+	MaxSources      []int    `json:"maxSources"`      // 各类型允许的最大源站数量，与 TypeSelect 一一对应
+	ProtocolTipHTML []string `json:"protocolTipHtml"` // 各类型的回源协议提示，与 TypeSelect 一一对应
+	RequireKey      bool     `json:"requireKey"`      // AccessKey 是否必填
+	RequireSecret   bool     `json:"requireSecret"`   // AccessSecret 是否必填
+	CustomEndpoint  bool     `json:"customEndpoint"`  // 是否支持自定义 API 地址
+	RequireType     bool     `json:"-"`               // CDNType 是否必填
+	Order           int      `json:"-"`               // 界面展示顺序，越小越靠前
+	Hidden          bool     `json:"-"`               // 不在界面中展示（如模拟测试），但仍可通过配置文件使用
+}
+
+// SortOrder 实现 helper.RegistrySchema
+func (s ProviderSchema) SortOrder() int {
+	return s.Order
+}
+
+// IsHidden 实现 helper.RegistrySchema
+func (s ProviderSchema) IsHidden() bool {
+	return s.Hidden
+}
+
+// Provider 已注册的 CDN 提供商
+type Provider = helper.RegistryEntry[CDN, ProviderSchema]
+
+var registry = helper.NewRegistry[CDN, ProviderSchema]("dcdn")
+
+// Register 注册 CDN 提供商，通常在提供商文件的 init 中调用；重复注册会 panic
+func Register(id string, newFunc func() CDN, schema ProviderSchema) {
+	registry.Register(id, newFunc, schema)
+}
+
+// LookupProvider 按 Service 查找已注册的提供商
+func LookupProvider(id string) (Provider, bool) {
+	return registry.Lookup(id)
+}
+
+// NewProvider 按 Service 创建提供商实例，未注册的服务返回错误（不再回退到阿里云）
+func NewProvider(id string) (CDN, error) {
+	p, ok := LookupProvider(id)
+	if !ok {
+		return nil, fmt.Errorf("不支持的 CDN 提供商: %s", id)
+	}
+	return p.New(), nil
+}
+
+// Providers 返回按展示顺序排列的所有已注册提供商
+func Providers() []Provider {
+	return registry.List()
+}
+
+// ProvidersJSON 返回供 Web 界面使用的提供商说明（按展示顺序的 JSON 对象，不含隐藏项）
+func ProvidersJSON() string {
+	return registry.JSON()
+}
+
+// ValidateCDN 按提供商的配置说明校验 CDN 配置
+func ValidateCDN(cdn *config.CDN) error {
+	if cdn == nil {
+		return errors.New("配置为空")
+	}
+	p, ok := LookupProvider(cdn.Service)
+	if !ok {
+		return fmt.Errorf("不支持的 CDN 提供商: %s", cdn.Service)
+	}
+	if strings.TrimSpace(cdn.Domain) == "" {
+		return fmt.Errorf("[%s] 域名不能为空", p.Schema.Name)
+	}
+	if p.Schema.RequireKey && strings.TrimSpace(cdn.AccessKey) == "" {
+		return fmt.Errorf("[%s] %s不能为空", cdn.Domain, helper.FieldLabel(p.Schema.IDLabel, "AccessKey"))
+	}
+	if p.Schema.RequireSecret && strings.TrimSpace(cdn.AccessSecret) == "" {
+		return fmt.Errorf("[%s] %s不能为空", cdn.Domain, helper.FieldLabel(p.Schema.SecretLabel, "AccessSecret"))
+	}
+	if strings.TrimSpace(cdn.Endpoint) != "" {
+		if !p.Schema.CustomEndpoint {
+			return fmt.Errorf("[%s] %s 不支持自定义 API 地址", cdn.Domain, p.Schema.Name)
+		}
+		if err := config.ValidateEndpoint(cdn.Endpoint); err != nil {
+			return fmt.Errorf("[%s] %v", cdn.Domain, err)
+		}
+	}
+	for i := range cdn.Sources {
+		source := &cdn.Sources[i]
+		if err := source.AddrSource().Validate(); err != nil {
+			return fmt.Errorf("[%s] 源站 %s: %v", cdn.Domain, source.Value, err)
+		}
+	}
+	if cdn.CDNType == "" {
+		if p.Schema.RequireType {
+			return fmt.Errorf("[%s] CDN 类型不能为空", cdn.Domain)
+		}
+		return nil
+	}
+	for _, t := range p.Schema.TypeSelect {
+		if strings.EqualFold(t, cdn.CDNType) {
+			return nil
+		}
+	}
+	return fmt.Errorf("[%s] %s 不支持类型 %s", cdn.Domain, p.Schema.Name, cdn.CDNType)
+}
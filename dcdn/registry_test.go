@@ -0,0 +1,82 @@
+package dcdn
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func TestBuiltinProvidersRegistered(t *testing.T) {
+	ids := []string{
+		ProviderAliyun, ProviderBaiduCloud, ProviderTencent, ProviderCloudflare,
+		ProviderUpyun, ProviderCallback, ProviderExecPlugin, ProviderMock,
+	}
+	for _, id := range ids {
+		p, err := NewProvider(id)
+		if err != nil || p == nil {
+			t.Errorf("提供商 %s 未注册: %v", id, err)
+		}
+	}
+}
+
+func TestNewProviderUnknownDoesNotFallBack(t *testing.T) {
+	p, err := NewProvider("unknown")
+	if err == nil || p != nil {
+		t.Errorf("未注册的提供商应返回错误而不是回退到阿里云, 实际: %T %v", p, err)
+	}
+}
+
+func TestProvidersJSONSchemaConsistent(t *testing.T) {
+	var schemas map[string]ProviderSchema
+	if err := json.Unmarshal([]byte(ProvidersJSON()), &schemas); err != nil {
+		t.Fatalf("ProvidersJSON 不是合法 JSON: %v", err)
+	}
+	if _, ok := schemas[ProviderMock]; ok {
+		t.Error("隐藏的提供商不应出现在界面 JSON 中")
+	}
+	for id, s := range schemas {
+		if len(s.TypeSelect) == 0 || len(s.MaxSources) != len(s.TypeSelect) || len(s.ProtocolTipHTML) != len(s.TypeSelect) {
+			t.Errorf("提供商 %s 的类型、源站数量和协议提示数量不一致: %+v", id, s)
+		}
+	}
+}
+
+func TestValidateCDN(t *testing.T) {
+	tests := []struct {
+		name    string
+		cdn     config.CDN
+		wantErr string
+	}{
+		{"完整配置", config.CDN{Service: ProviderAliyun, Domain: "cdn.example.com", AccessKey: "id", AccessSecret: "secret", CDNType: "dcdn"}, ""},
+		{"未知提供商", config.CDN{Service: "", Domain: "cdn.example.com", AccessKey: "id", AccessSecret: "secret"}, "不支持的 CDN 提供商"},
+		{"缺少 AccessSecret", config.CDN{Service: ProviderTencent, Domain: "cdn.example.com", AccessKey: "id"}, "SecretKey 不能为空"},
+		{"缺少必填类型", config.CDN{Service: ProviderCloudflare, Domain: "cdn.example.com", AccessKey: "token"}, "CDN 类型不能为空"},
+		{"不支持的类型", config.CDN{Service: ProviderCloudflare, Domain: "cdn.example.com", AccessKey: "token", CDNType: "ESA"}, "不支持类型"},
+		{"可选类型为空", config.CDN{Service: ProviderTencent, Domain: "cdn.example.com", AccessKey: "id", AccessSecret: "secret"}, ""},
+		{"可选 AccessSecret", config.CDN{Service: ProviderCallback, Domain: "cdn.example.com", AccessKey: "https://example.com/hook", CDNType: "CALLBACK"}, ""},
+		{"自定义 API 地址", config.CDN{Service: ProviderTencent, Domain: "cdn.example.com", AccessKey: "id", AccessSecret: "secret", Endpoint: "https://cdn.intl.tencentcloudapi.com"}, ""},
+		{"API 地址包含查询参数", config.CDN{Service: ProviderTencent, Domain: "cdn.example.com", AccessKey: "id", AccessSecret: "secret", Endpoint: "https://proxy.example.com/?a=1"}, "不能包含查询参数"},
+		{"不支持自定义 API 地址", config.CDN{Service: ProviderCallback, Domain: "cdn.example.com", AccessKey: "https://example.com/hook", CDNType: "CALLBACK", Endpoint: "https://example.com"}, "不支持自定义 API 地址"},
+		{"quorum 无效", config.CDN{Service: ProviderMock, Domain: "cdn.example.com", Sources: []config.Source{{Type: helper.DynamicIPv4URL, Value: "https://ip.example.com,quorum=0"}}}, "无效的 quorum=0"},
+		{"邻居表策略无效", config.CDN{Service: ProviderMock, Domain: "cdn.example.com", Sources: []config.Source{{Type: helper.DynamicIPv6Neighbor, Value: "aa:bb:cc:dd:ee:ff", IPv6Policy: "lifetime"}}}, "邻居表来源不支持"},
+		{"前缀长度无效", config.CDN{Service: ProviderMock, Domain: "cdn.example.com", Sources: []config.Source{{Type: helper.DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "::1", PrefixLength: 200}}}, "前缀长度无效"},
+		{"提取正则无效", config.CDN{Service: ProviderMock, Domain: "cdn.example.com", Sources: []config.Source{{Type: helper.DynamicIPv4URL, Value: "https://ip.example.com", ExtractRegex: "["}}}, "提取正则无效"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCDN(&tt.cdn)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("意外错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("期望错误包含 %q, 实际: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
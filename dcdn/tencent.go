@@ -13,9 +13,29 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
+func init() {
+	Register(ProviderTencent, func() CDN { return &Tencent{} }, ProviderSchema{
+		Name:         "腾讯云",
+		IDLabel:      "SecretId：",
+		SecretLabel:  "SecretKey：",
+		TypeSelect:   []string{CDNTypeEdgeOne, CDNTypeCDN},
+		IDHelpHTML:   "<a target='_blank' href='https://console.dnspod.cn/account/token/apikey'>创建腾讯云 API 密钥</a>",
+		TypeHelpHTML: "<a target='_blank' href='https://console.cloud.tencent.com/edgeone'>EdgeOne</a>、<a target='_blank' href='https://console.cloud.tencent.com/cdn'>CDN</a>",
+		MaxSources:   []int{1, 5},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+			"<tip>协议跟随时，不允许自定义端口号</tip>",
+		},
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          30,
+	})
+}
+
 const (
-	tencentCDNHost        string = "cdn.tencentcloudapi.com"
-	tencentEdgeOneHost    string = "teo.tencentcloudapi.com"
+	tencentCDNHost        string = "cdn.tencentcloudapi.com" // 默认地址，可通过 CDN.Endpoint 覆盖
+	tencentEdgeOneHost    string = "teo.tencentcloudapi.com" // 默认地址，可通过 CDN.Endpoint 覆盖
 	tencentCDNService     string = "cdn"
 	tencentEdgeOneService string = "teo"
 )
@@ -556,7 +576,7 @@ func (tencent *Tencent) request(action string, body interface{}, result interfac
 		service = tencentCDNService
 	}
 
-	endpoint := "https://" + host + "/"
+	endpoint := tencent.CDN.GetEndpoint("https://"+host) + "/"
 
 	// 构建请求体
 	jsonStr, _ := json.Marshal(body)
@@ -575,7 +595,7 @@ func (tencent *Tencent) request(action string, body interface{}, result interfac
 	req.Header.Set("X-TC-Action", action)
 
 	// 调用签名函数
-	signer.TencentSigner(tencent.CDN.AccessKey, tencent.CDN.AccessSecret, service, host, string(jsonStr), req)
+	signer.TencentSigner(tencent.CDN.AccessKey, tencent.CDN.AccessSecret, service, req.URL.Host, string(jsonStr), req)
 
 	client := helper.CreateHTTPClient()
 	resp, err := client.Do(req)
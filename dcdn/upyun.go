@@ -15,8 +15,25 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderUpyun, func() CDN { return &Upyun{} }, ProviderSchema{
+		Name:         "又拍云",
+		IDLabel:      "Token：",
+		TypeSelect:   []string{CDNTypeCDN},
+		IDHelpHTML:   "<a href='javascript:void(0)' onclick='openUpyunTokenDialog()'>点击获取 Token</a>",
+		TypeHelpHTML: "<a target='_blank' href='https://console.upyun.com/cdn/list/'>又拍云 CDN 控制台</a>",
+		MaxSources:   []int{1},
+		ProtocolTipHTML: []string{
+			"<tip></tip>",
+		},
+		RequireKey:     true,
+		CustomEndpoint: true,
+		Order:          50,
+	})
+}
+
 const (
-	upyunAPIEndpoint = "https://api.upyun.com"
+	upyunAPIEndpoint = "https://api.upyun.com" // 默认地址，可通过 CDN.Endpoint 覆盖
 )
 
 type Upyun struct {
@@ -250,7 +267,7 @@ func (upyun *Upyun) request(method, path string, body interface{}, result interf
 	}
 	helper.Debug(helper.LogTypeDCDN, "又拍云请求 [%s %s]: %s", method, path, string(bodyBytes))
 
-	req, err := http.NewRequest(method, upyunAPIEndpoint+path, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequest(method, upyun.CDN.GetEndpoint(upyunAPIEndpoint)+path, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return err
 	}
@@ -11,7 +11,21 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
-const aliyunDNSEndpoint = "https://alidns.aliyuncs.com/"
+func init() {
+	Register(ProviderAliDNS, func() DNS { return &Aliyun{} }, ProviderSchema{
+		Name:           "阿里云",
+		IDLabel:        "AccessKey ID",
+		SecretLabel:    "AccessKey Secret",
+		IDHelpHTML:     "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>创建 AccessKey</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          10,
+	})
+}
+
+// aliyunDNSEndpoint 阿里云 DNS API 默认地址，可通过 DNSGroup.Endpoint 覆盖（如 alidns.ap-southeast-1.aliyuncs.com）
+const aliyunDNSEndpoint = "https://alidns.aliyuncs.com"
 
 type Aliyun struct {
 	BaseDNSProvider
@@ -185,6 +199,37 @@ func (a *Aliyun) processRecord(record *config.DNSRecord, cache *Cache, existing
 	return result
 }
 
+// RemoveRecords 删除该域名下指定类型的全部记录
+func (a *Aliyun) RemoveRecords(recordTypes []string) []RecordResult {
+	if a.Group.Domain == "" || a.Group.AccessKey == "" || a.Group.AccessSecret == "" {
+		return createRemoveErrorResults(recordTypes, "配置不完整")
+	}
+	allRecords, err := a.describeAllDomainRecords()
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 查询所有 DNS 记录失败: %v", a.GetServiceName(), err)
+		return createRemoveErrorResults(recordTypes, err.Error())
+	}
+
+	results := make([]RecordResult, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		result := RecordResult{RecordType: recordType, Status: UpdatedNothing}
+		for _, rec := range allRecords {
+			if rec.Type != recordType {
+				continue
+			}
+			if err := a.deleteDomainRecord(rec.RecordId); err != nil {
+				result.Status = UpdatedFailed
+				result.ErrorMessage = err.Error()
+				break
+			}
+			result.Status = RemovedSuccess
+			result.OldValue = rec.Value
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 // describeAllDomainRecords 查询指定主机记录的所有 DNS 记录（所有类型）
 func (a *Aliyun) describeAllDomainRecords() ([]DomainRecord, error) {
 	params := url.Values{}
@@ -296,7 +341,7 @@ func (a *Aliyun) deleteDomainRecord(recordId string) error {
 func (a *Aliyun) request(method string, params url.Values, result interface{}) error {
 	signer.AliyunSigner(a.Group.AccessKey, a.Group.AccessSecret, &params, method)
 
-	req, err := http.NewRequest(method, aliyunDNSEndpoint, nil)
+	req, err := http.NewRequest(method, a.Group.GetEndpoint(aliyunDNSEndpoint)+"/", nil)
 	if err != nil {
 		return err
 	}
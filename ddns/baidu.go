@@ -14,7 +14,20 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
-// 百度智能云 DNS API Endpoint
+func init() {
+	Register(ProviderBaiduCloud, func() DNS { return &Baidu{} }, ProviderSchema{
+		Name:           "百度智能云",
+		IDLabel:        "AccessKey ID：",
+		SecretLabel:    "AccessKey Secret：",
+		IDHelpHTML:     "<a target='_blank' href='https://console.bce.baidu.com/iam/?_=1651763238057#/iam/accesslist'>创建 AccessKey</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          20,
+	})
+}
+
+// 百度智能云 DNS API 默认地址，可通过 DNSGroup.Endpoint 覆盖
 const baiduDNSEndpoint = "https://dns.baidubce.com"
 
 type Baidu struct {
@@ -270,7 +283,7 @@ func (b *Baidu) deleteRecord(recordID string) error {
 
 // request 统一请求方法
 func (b *Baidu) request(method, path string, body interface{}, result interface{}) error {
-	reqURL := baiduDNSEndpoint + path
+	reqURL := b.Group.GetEndpoint(baiduDNSEndpoint) + path
 
 	var reqBody []byte
 	var err error
@@ -11,6 +11,17 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderCallback, func() DNS { return &Callback{} }, ProviderSchema{
+		Name:        "Callback",
+		IDLabel:     "Callback URL：",
+		SecretLabel: "请求内容 RequestBody（可选）：",
+		IDHelpHTML:  "<tip><a target='_blank' href='https://github.com/cxbdasheng/dnet/wiki/DDNS-%E4%BD%BF%E7%94%A8%E6%8C%87%E5%8D%97#%E8%87%AA%E5%AE%9A%E4%B9%89%E5%9B%9E%E8%B0%83callback'>自定义回调</a>  支持变量：#{ip} #{domain} #{recordType} #{ttl}。RequestBody 为空发 GET，否则发 POST。</tip>",
+		RequireKey:  true,
+		Order:       90,
+	})
+}
+
 // Callback 自定义回调 DNS 提供商。
 // 不对接具体云厂商，而是在记录值发生变化（或触发强制更新）时，
 // 向用户配置的 URL 发起一次 HTTP 请求，用于对接自建 DNS、路由器或内部接口。
@@ -13,6 +13,18 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderCloudflare, func() DNS { return &Cloudflare{} }, ProviderSchema{
+		Name:           "Cloudflare",
+		IDLabel:        "API Token：",
+		IDHelpHTML:     "<a target='_blank' href='https://dash.cloudflare.com/profile/api-tokens'>创建 API 令牌 -> 编辑区域 DNS (使用模板)</a>",
+		RequireKey:     true,
+		CustomEndpoint: true,
+		Order:          40,
+	})
+}
+
+// cloudflareAPIEndpoint Cloudflare API 默认地址，可通过 DNSGroup.Endpoint 覆盖（如经由出口代理）
 const cloudflareAPIEndpoint = "https://api.cloudflare.com/client/v4"
 
 type Cloudflare struct {
@@ -245,6 +257,37 @@ func (cf *Cloudflare) processRecord(record *config.DNSRecord, cache *Cache, exis
 	return result
 }
 
+// RemoveRecords 删除该域名下指定类型的全部记录
+func (cf *Cloudflare) RemoveRecords(recordTypes []string) []RecordResult {
+	if cf.zoneID == "" {
+		return createRemoveErrorResults(recordTypes, "Zone ID 未初始化")
+	}
+	allRecords, err := cf.getAllDNSRecords()
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 查询所有 DNS 记录失败: %v", cf.GetServiceName(), err)
+		return createRemoveErrorResults(recordTypes, err.Error())
+	}
+
+	results := make([]RecordResult, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		result := RecordResult{RecordType: recordType, Status: UpdatedNothing}
+		for _, rec := range allRecords {
+			if rec.Type != recordType {
+				continue
+			}
+			if err := cf.deleteDNSRecord(rec.ID); err != nil {
+				result.Status = UpdatedFailed
+				result.ErrorMessage = err.Error()
+				break
+			}
+			result.Status = RemovedSuccess
+			result.OldValue = rec.Content
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 // getZoneID 获取根域名对应的 Zone ID
 func (cf *Cloudflare) getZoneID() (string, error) {
 	rootDomain := getRootDomain(cf.Group.Domain)
@@ -373,7 +416,7 @@ func (cf *Cloudflare) deleteDNSRecord(recordID string) error {
 
 // request 统一请求方法
 func (cf *Cloudflare) request(method, urlPath string, body interface{}, result interface{}) error {
-	reqURL := cloudflareAPIEndpoint + urlPath
+	reqURL := cf.Group.GetEndpoint(cloudflareAPIEndpoint) + urlPath
 
 	var reqBody io.Reader
 	if body != nil {
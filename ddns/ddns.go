@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cxbdasheng/dnet/config"
 	"github.com/cxbdasheng/dnet/helper"
@@ -30,7 +31,11 @@ const (
 	ProviderDnspod     = "dnspod"     // DNSPod DNS
 	ProviderNameSilo   = "namesilo"   // NameSilo DNS
 	ProviderGoDaddy    = "godaddy"    // GoDaddy DNS
+	ProviderRoute53    = "route53"    // Amazon Route 53
+	ProviderDynDNS2    = "dyndns2"    // DynDNS2 协议（No-IP、Dynu 等）
 	ProviderCallback   = "callback"   // 自定义回调（HTTP GET/POST）
+	ProviderRFC2136    = "rfc2136"    // 标准 DNS 动态更新（RFC 2136 + TSIG）
+	ProviderExecPlugin = "exec"       // 外部插件（JSON over stdin/stdout）
 	ProviderMock       = "mock"       // 模拟测试（不发起真实请求）
 )
 
@@ -40,7 +45,17 @@ var dynamicTypes = map[string]bool{
 	helper.DynamicIPv4Command:   true,
 	helper.DynamicIPv6URL:       true,
 	helper.DynamicIPv6Interface: true,
+	helper.DynamicIPv6Neighbor:  true,
+	helper.DynamicIPv4DHCP:      true,
+	helper.DynamicIPv6DHCP:      true,
 	helper.DynamicIPv6Command:   true,
+	helper.DynamicIPv4Stun:      true,
+	helper.DynamicIPv6Stun:      true,
+	helper.DynamicIPv4DNS:       true,
+	helper.DynamicIPv6DNS:       true,
+	helper.DynamicIPv4Gateway:   true,
+	helper.PushedIPv4:           true,
+	helper.PushedIPv6:           true,
 }
 
 var ForceCompareGlobal = true
@@ -57,6 +72,8 @@ const (
 	UpdatedFailed = "失败"
 	// UpdatedSuccess 更新成功
 	UpdatedSuccess = "成功"
+	// RemovedSuccess 记录已删除
+	RemovedSuccess = "已删除"
 )
 
 // Cache DDNS 缓存结构
@@ -66,6 +83,8 @@ type Cache struct {
 	DynamicIPs     map[string]string // 动态 IP 缓存: key=source 唯一标识(type:value), value=获取到的 IP
 	mu             sync.RWMutex      // 保护 DynamicIPs 的读写锁
 	HasRun         bool              // 是否已经运行过
+	LastSuccess    time.Time         // 最后一次成功推送的时间
+	Halted         string            // 服务商拒绝服务时的配置指纹，配置变化前不再发起请求
 	forcedNoChange bool              // 本轮是计数器归零触发的强制更新（值未变）
 }
 
@@ -76,6 +95,12 @@ type DNS interface {
 	GetServiceName() string
 }
 
+// RecordRemover 可选接口：支持按类型删除域名下记录的提供商实现，用于 Docker 容器停止、DHCP 租约过期后清理记录。
+// 调用前需先 Init，caches 可为空
+type RecordRemover interface {
+	RemoveRecords(recordTypes []string) []RecordResult
+}
+
 // RecordResult 单条记录的处理结果
 type RecordResult struct {
 	RecordType    string     // 记录类型 (A, AAAA, CNAME, TXT)
@@ -132,6 +157,37 @@ func (c *Cache) GetDynamicIP(cacheKey string) (string, bool) {
 	return ip, exists
 }
 
+// State 导出可持久化的缓存状态
+func (c *Cache) State() config.CacheState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ips := make(map[string]string, len(c.DynamicIPs))
+	for k, v := range c.DynamicIPs {
+		ips[k] = v
+	}
+	return config.CacheState{
+		DynamicIPs:  ips,
+		HasRun:      c.HasRun,
+		TimesFailed: c.TimesFailed,
+		LastSuccess: c.LastSuccess,
+		Halted:      c.Halted,
+	}
+}
+
+// RestoreState 从持久化状态恢复缓存，计数器保持初始值
+func (c *Cache) RestoreState(state config.CacheState) {
+	c.mu.Lock()
+	c.DynamicIPs = make(map[string]string, len(state.DynamicIPs))
+	for k, v := range state.DynamicIPs {
+		c.DynamicIPs[k] = v
+	}
+	c.mu.Unlock()
+	c.HasRun = state.HasRun
+	c.TimesFailed = state.TimesFailed
+	c.LastSuccess = state.LastSuccess
+	c.Halted = state.Halted
+}
+
 // ResetTimes 重置计数器
 func (c *Cache) ResetTimes() {
 	times, err := strconv.Atoi(os.Getenv(CacheTimesENV))
@@ -234,6 +290,19 @@ func createErrorResults(validRecords []validRecord, status statusType, errMsg st
 	return results
 }
 
+// createRemoveErrorResults 删除记录前失败时为每种类型生成错误结果
+func createRemoveErrorResults(recordTypes []string, errMsg string) []RecordResult {
+	results := make([]RecordResult, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		results = append(results, RecordResult{
+			RecordType:   recordType,
+			Status:       UpdatedFailed,
+			ErrorMessage: errMsg,
+		})
+	}
+	return results
+}
+
 // shouldSendWebhook 判断单条记录是否需要发送 Webhook
 func shouldSendWebhook(cache *Cache, status statusType) bool {
 	if status == UpdatedSuccess {
@@ -249,12 +318,25 @@ func shouldSendWebhook(cache *Cache, status statusType) bool {
 
 // getCacheKey 获取缓存键（支持正则表达式）
 func getCacheKey(ipType, value, regex string) string {
-	if ipType == helper.DynamicIPv6Interface && regex != "" {
+	if helper.SupportsIPv6Policy(ipType) && regex != "" {
 		return helper.GetIPCacheKeyWithRegex(ipType, value, regex)
 	}
 	return helper.GetIPCacheKey(ipType, value)
 }
 
+// getRecordCacheKey 获取记录的缓存键（网卡正则、IPv6 选择策略、接口 / 命令提取方式、主机后缀不同的记录互不影响）
+func getRecordCacheKey(record *config.DNSRecord) string {
+	var key string
+	if helper.SupportsIPv6Policy(record.IPType) && record.IPv6Policy != "" {
+		key = helper.GetIPCacheKeyWithPolicy(record.IPType, record.Value, record.Regex, record.IPv6Policy)
+	} else if extract := record.AddrExtract(); !extract.IsZero() && helper.SupportsExtract(record.IPType) {
+		key = helper.GetIPCacheKeyWithExtract(record.IPType, record.Value, extract)
+	} else {
+		key = getCacheKey(record.IPType, record.Value, record.Regex)
+	}
+	return helper.GetIPCacheKeyWithSuffix(key, record.IPType, record.IPv6Suffix, record.PrefixLength)
+}
+
 // getCurrentValue 步骤1：获取当前记录值，返回 (值, 初始化后的result, 是否成功)
 func getCurrentValue(serviceName string, record *config.DNSRecord, cache *Cache) (string, RecordResult, bool) {
 	result := RecordResult{
@@ -267,11 +349,17 @@ func getCurrentValue(serviceName string, record *config.DNSRecord, cache *Cache)
 		if IsDynamicType(record.IPType) {
 			var currentValue string
 			var ok bool
-			if record.IPType == helper.DynamicIPv6Interface && record.Regex != "" {
-				currentValue, ok = helper.GetOrSetDynamicIPWithCacheAndRegex(record.IPType, record.Value, record.Regex)
+			if helper.SupportsIPv6Policy(record.IPType) && (record.Regex != "" || record.IPv6Policy != "") {
+				currentValue, ok = helper.GetOrSetDynamicIPWithPolicy(record.IPType, record.Value, record.Regex, record.IPv6Policy)
+			} else if helper.SupportsExtract(record.IPType) {
+				currentValue, ok = helper.GetOrSetDynamicIPWithExtract(record.IPType, record.Value, record.AddrExtract())
 			} else {
 				currentValue, ok = helper.GetOrSetDynamicIPWithCache(record.IPType, record.Value)
 			}
+			if ok {
+				// 配置主机后缀时，以获取到的地址为前缀组合出局域网设备的完整地址
+				currentValue, ok = helper.ApplyIPv6Suffix(record.IPType, currentValue, record.IPv6Suffix, record.PrefixLength)
+			}
 			if !ok {
 				result.Status = InitGetIPFailed
 				result.ShouldWebhook = shouldSendWebhook(cache, InitGetIPFailed)
@@ -298,7 +386,7 @@ func checkDynamicCache(serviceName string, record *config.DNSRecord, cache *Cach
 	if !IsDynamicType(record.IPType) {
 		return false, RecordResult{}
 	}
-	cacheKey := getCacheKey(record.IPType, record.Value, record.Regex)
+	cacheKey := getRecordCacheKey(record)
 	valueChanged, oldValue := cache.CheckIPChanged(cacheKey, currentValue)
 	forceUpdate := cache.Times <= 0
 
@@ -326,13 +414,14 @@ func checkDynamicCache(serviceName string, record *config.DNSRecord, cache *Cach
 // finalizeSuccess 步骤4：更新缓存并设置成功状态
 func finalizeSuccess(serviceName string, record *config.DNSRecord, cache *Cache, currentValue string, result *RecordResult) {
 	if IsDynamicType(record.IPType) {
-		cacheKey := getCacheKey(record.IPType, record.Value, record.Regex)
+		cacheKey := getRecordCacheKey(record)
 		cache.UpdateDynamicIP(cacheKey, currentValue)
 	}
 	forcedNoChange := cache.forcedNoChange
 	cache.forcedNoChange = false
 	cache.HasRun = true
 	cache.TimesFailed = 0
+	cache.LastSuccess = time.Now()
 	cache.ResetTimes()
 	result.Status = UpdatedSuccess
 	result.ShouldWebhook = !forcedNoChange && shouldSendWebhook(cache, UpdatedSuccess)
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/cxbdasheng/dnet/config"
 	"github.com/cxbdasheng/dnet/helper"
 )
 
@@ -295,3 +296,19 @@ func TestShouldSendWebhook(t *testing.T) {
 		}
 	})
 }
+
+// TestGetCurrentValue_NeighborMissing 邻居表中找不到设备时按获取 IP 失败处理
+func TestGetCurrentValue_NeighborMissing(t *testing.T) {
+	helper.ClearGlobalIPCache()
+	t.Cleanup(helper.ClearGlobalIPCache)
+	c := NewCache()
+	record := &config.DNSRecord{Type: RecordTypeAAAA, IPType: helper.DynamicIPv6Neighbor, Value: "02:00:5e:00:53:01"}
+
+	value, result, ok := getCurrentValue("mock", record, &c)
+	if ok || value != "" {
+		t.Fatalf("邻居不存在时应获取失败，得到 %q %v", value, ok)
+	}
+	if result.Status != InitGetIPFailed {
+		t.Errorf("Status = %s, want %s", result.Status, InitGetIPFailed)
+	}
+}
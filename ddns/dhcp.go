@@ -0,0 +1,112 @@
+package ddns
+
+import (
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// ExpandLeaseGroups 展开 DHCP 租约批量记录（来源值含通配符，如 *@/tmp/dhcp.leases）：
+// 每个匹配且上报了主机名的租约生成一个 <主机名>.<域名> 的配置组，记录改为按该租约的 MAC / 主机名获取地址。
+// 不含批量记录的配置组原样返回，传入的配置不会被修改。
+// 同时返回读取租约失败的配置组 ID，这些配置组本轮展开的主机不完整，调用方不应据此删除记录
+func ExpandLeaseGroups(groups []config.DNSGroup) ([]config.DNSGroup, map[string]bool) {
+	expanded := make([]config.DNSGroup, 0, len(groups))
+	unreadable := make(map[string]bool)
+	for _, group := range groups {
+		var records []config.DNSRecord
+		var hosts []string
+		hostGroups := make(map[string]*config.DNSGroup)
+		bulk := false
+
+		for _, record := range group.Records {
+			if !helper.IsDHCPLeaseType(record.IPType) || !helper.IsDHCPLeaseBulk(record.Value) {
+				records = append(records, record)
+				continue
+			}
+			bulk = true
+			if group.Domain == "" {
+				continue
+			}
+
+			leases, err := helper.FindDHCPLeases(record.Value, helper.DHCPLeaseAddrType(record.IPType))
+			if err != nil {
+				helper.Warn(helper.LogTypeDDNS, "读取 DHCP 租约失败: %v", err)
+				unreadable[group.ID] = true
+				continue
+			}
+			_, file := helper.ParseDHCPLeaseValue(record.Value)
+			for _, lease := range leases {
+				host := leaseDNSLabel(lease.Hostname)
+				if host == "" {
+					continue
+				}
+				hostGroup, ok := hostGroups[host]
+				if !ok {
+					hostGroup = &config.DNSGroup{}
+					*hostGroup = group
+					hostGroup.ID = group.ID + "/" + host
+					hostGroup.Domain = host + "." + group.Domain
+					hostGroup.Records = nil
+					hostGroups[host] = hostGroup
+					hosts = append(hosts, host)
+				}
+				if hasLeaseRecord(hostGroup.Records, record) {
+					// 同名多个租约只取排序最靠前的一个
+					continue
+				}
+
+				hostRecord := record
+				hostRecord.Value = lease.Hostname
+				if lease.MAC != "" {
+					hostRecord.Value = lease.MAC
+				}
+				if file != "" {
+					hostRecord.Value += "@" + file
+				}
+				hostGroup.Records = append(hostGroup.Records, hostRecord)
+			}
+		}
+
+		if !bulk {
+			expanded = append(expanded, group)
+			continue
+		}
+		if len(records) > 0 {
+			group.Records = records
+			expanded = append(expanded, group)
+		}
+		for _, host := range hosts {
+			expanded = append(expanded, *hostGroups[host])
+		}
+	}
+	return expanded, unreadable
+}
+
+// hasLeaseRecord 配置组中是否已有同类型的租约记录
+func hasLeaseRecord(records []config.DNSRecord, record config.DNSRecord) bool {
+	for _, existing := range records {
+		if existing.Type == record.Type && existing.IPType == record.IPType {
+			return true
+		}
+	}
+	return false
+}
+
+// leaseDNSLabel 将主机名转换为合法的 DNS 标签：小写，非字母数字替换为 -，去掉首尾的 -
+func leaseDNSLabel(hostname string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(hostname) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+	label := strings.Trim(sb.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
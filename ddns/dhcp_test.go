@@ -0,0 +1,92 @@
+package ddns
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// writeLeaseFile 写入 dnsmasq 格式的租约文件，租约一小时后到期
+func writeLeaseFile(t *testing.T) string {
+	t.Helper()
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	content := expires + " aa:bb:cc:dd:ee:01 192.168.1.10 NAS *\n" +
+		expires + " aa:bb:cc:dd:ee:02 192.168.1.11 Living_Room.TV *\n" +
+		expires + " aa:bb:cc:dd:ee:03 192.168.1.12 * *\n" +
+		"duid 00:01:00:01:2b:00:00:00:11:22:33:44:55:66\n" +
+		expires + " 1 2001:db8::10 NAS 00:03:00:01:aa:bb:cc:dd:ee:01\n"
+	file := filepath.Join(t.TempDir(), "dhcp.leases")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// TestExpandLeaseGroups 测试 DHCP 租约批量记录展开
+func TestExpandLeaseGroups(t *testing.T) {
+	file := writeLeaseFile(t)
+	groups := []config.DNSGroup{
+		{ID: "plain", Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"}}},
+		{ID: "lan", Domain: "lan.example.com", Service: ProviderMock, Records: []config.DNSRecord{
+			{Type: RecordTypeA, IPType: helper.DynamicIPv4DHCP, Value: "*@" + file},
+			{Type: RecordTypeAAAA, IPType: helper.DynamicIPv6DHCP, Value: "*@" + file},
+		}},
+	}
+
+	expanded, unreadable := ExpandLeaseGroups(groups)
+	if len(unreadable) != 0 {
+		t.Errorf("expected no unreadable groups, got %v", unreadable)
+	}
+	if len(expanded) != 3 {
+		t.Fatalf("expected 3 groups, got %d: %+v", len(expanded), expanded)
+	}
+	if expanded[0].ID != "plain" || len(expanded[0].Records) != 1 {
+		t.Errorf("group without bulk records should be unchanged, got %+v", expanded[0])
+	}
+
+	nas := expanded[1]
+	if nas.ID != "lan/nas" || nas.Domain != "nas.lan.example.com" || nas.Service != ProviderMock {
+		t.Errorf("unexpected nas group: %+v", nas)
+	}
+	if len(nas.Records) != 2 || nas.Records[0].Value != "aa:bb:cc:dd:ee:01@"+file || nas.Records[1].Type != RecordTypeAAAA {
+		t.Errorf("unexpected nas records: %+v", nas.Records)
+	}
+
+	tv := expanded[2]
+	if tv.Domain != "living-room-tv.lan.example.com" || len(tv.Records) != 1 || tv.Records[0].Type != RecordTypeA {
+		t.Errorf("unexpected tv group: %+v", tv)
+	}
+
+	if groups[1].Records[0].Value != "*@"+file {
+		t.Error("ExpandLeaseGroups should not modify the input groups")
+	}
+
+	groups[1].Records[0].Value = "*@" + file + ".missing"
+	if _, unreadable := ExpandLeaseGroups(groups); !unreadable["lan"] {
+		t.Errorf("expected lan to be reported as unreadable, got %v", unreadable)
+	}
+}
+
+// TestGetCurrentValue_DHCPLease 展开后的记录按 MAC 从租约获取地址
+func TestGetCurrentValue_DHCPLease(t *testing.T) {
+	helper.ClearGlobalIPCache()
+	t.Cleanup(helper.ClearGlobalIPCache)
+	file := writeLeaseFile(t)
+	c := NewCache()
+
+	record := &config.DNSRecord{Type: RecordTypeAAAA, IPType: helper.DynamicIPv6DHCP, Value: "aa:bb:cc:dd:ee:01@" + file}
+	value, _, ok := getCurrentValue("mock", record, &c)
+	if !ok || value != "2001:db8::10" {
+		t.Fatalf("expected 2001:db8::10, got %q %v", value, ok)
+	}
+
+	record = &config.DNSRecord{Type: RecordTypeA, IPType: helper.DynamicIPv4DHCP, Value: "missing@" + file}
+	if value, result, ok := getCurrentValue("mock", record, &c); ok || result.Status != InitGetIPFailed {
+		t.Errorf("expected IP fetch failure for unknown host, got %q %v %s", value, ok, result.Status)
+	}
+}
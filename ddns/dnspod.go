@@ -10,11 +10,27 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderDnspod, func() DNS { return &Dnspod{} }, ProviderSchema{
+		Name:           "DNSPod",
+		IDLabel:        "Token ID：",
+		SecretLabel:    "Token：",
+		IDHelpHTML:     "<a target='_blank' href='https://console.dnspod.cn/account/token/token'>创建 DNSPod Token</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          60,
+	})
+}
+
+// dnspodAPIEndpoint DNSPod API 默认地址，可通过 DNSGroup.Endpoint 覆盖
+const dnspodAPIEndpoint = "https://dnsapi.cn"
+
 const (
-	dnspodRecordListAPI   = "https://dnsapi.cn/Record.List"
-	dnspodRecordCreateAPI = "https://dnsapi.cn/Record.Create"
-	dnspodRecordModifyAPI = "https://dnsapi.cn/Record.Modify"
-	dnspodRecordRemoveAPI = "https://dnsapi.cn/Record.Remove"
+	dnspodRecordListAPI   = "/Record.List"
+	dnspodRecordCreateAPI = "/Record.Create"
+	dnspodRecordModifyAPI = "/Record.Modify"
+	dnspodRecordRemoveAPI = "/Record.Remove"
 )
 
 type Dnspod struct {
@@ -260,9 +276,9 @@ func (d *Dnspod) deleteDomainRecord(recordId string) error {
 }
 
 // request 统一请求方法（DNSPod 使用 form POST + JSON 响应）
-func (d *Dnspod) request(apiURL string, params url.Values, result interface{}) error {
+func (d *Dnspod) request(apiPath string, params url.Values, result interface{}) error {
 	client := helper.CreateHTTPClient()
-	resp, err := client.PostForm(apiURL, params)
+	resp, err := client.PostForm(d.Group.GetEndpoint(dnspodAPIEndpoint)+apiPath, params)
 	if err := helper.GetHTTPResponse(resp, err, result); err != nil {
 		return err
 	}
@@ -0,0 +1,150 @@
+package ddns
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// Docker 容器标签
+const (
+	DockerLabelDomain = "dnet.ddns.domain"  // 必填，完整域名，多个以逗号分隔
+	DockerLabelType   = "dnet.ddns.type"    // 记录类型 A / AAAA，多个以逗号分隔，默认 A
+	DockerLabelGroup  = "dnet.ddns.group"   // 沿用其服务商与凭证的配置组（ID 或名称），默认取 docker.group
+	DockerLabelIPType = "dnet.ddns.ip_type" // IP 获取方式，默认使用容器在 Docker 网络中的地址
+	DockerLabelValue  = "dnet.ddns.value"   // 配合 dnet.ddns.ip_type 使用的值
+)
+
+// DockerGroupIDPrefix 由容器生成的配置组 ID 前缀
+const DockerGroupIDPrefix = "docker/"
+
+// DockerGroups 根据运行中容器的标签生成配置组，服务商、凭证与 TTL 取自配置文件中的模板配置组；
+// 域名已在配置文件中配置时以配置文件为准，多个容器声明同一域名时按容器名排序取第一个
+func DockerGroups(discovery config.DockerDiscovery, groups []config.DNSGroup, containers []helper.DockerContainer) []config.DNSGroup {
+	claimed := make(map[string]string)
+	for _, group := range groups {
+		claimed[strings.ToLower(group.Domain)] = ""
+	}
+
+	sorted := append([]helper.DockerContainer(nil), containers...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	var discovered []config.DNSGroup
+	for _, container := range sorted {
+		name := container.Name()
+		labels := container.Labels
+		if strings.TrimSpace(labels[DockerLabelDomain]) == "" {
+			continue
+		}
+
+		templateName := labels[DockerLabelGroup]
+		if templateName == "" {
+			templateName = discovery.Group
+		}
+		template := findTemplateGroup(groups, templateName)
+		if template == nil {
+			helper.Warn(helper.LogTypeDDNS, "容器 %s 未找到模板配置组 %q，请设置 %s 标签或 docker.group，跳过", name, templateName, DockerLabelGroup)
+			continue
+		}
+
+		records := dockerRecords(container, template)
+		if len(records) == 0 {
+			continue
+		}
+		for _, domain := range splitLabel(labels[DockerLabelDomain]) {
+			key := strings.ToLower(domain)
+			if owner, ok := claimed[key]; ok {
+				if owner == "" {
+					helper.Debug(helper.LogTypeDDNS, "容器 %s 的域名 %s 已在配置文件中配置，以配置文件为准", name, domain)
+				} else {
+					helper.Warn(helper.LogTypeDDNS, "容器 %s 的域名 %s 已被容器 %s 使用，跳过", name, domain, owner)
+				}
+				continue
+			}
+			claimed[key] = name
+
+			discovered = append(discovered, config.DNSGroup{
+				ID:           DockerGroupIDPrefix + name + "/" + key,
+				Name:         name,
+				Domain:       domain,
+				Service:      template.Service,
+				AccessKey:    template.AccessKey,
+				AccessSecret: template.AccessSecret,
+				Endpoint:     template.Endpoint,
+				TTL:          template.TTL,
+				Records:      append([]config.DNSRecord(nil), records...),
+			})
+		}
+	}
+	return discovered
+}
+
+// dockerRecords 按 dnet.ddns.type 生成记录：指定了 ip_type 时按其获取，否则使用容器地址，
+// 容器没有对应类型的地址（如 host 网络）时沿用模板配置组中同类型记录的获取方式
+func dockerRecords(container helper.DockerContainer, template *config.DNSGroup) []config.DNSRecord {
+	types := splitLabel(container.Labels[DockerLabelType])
+	if len(types) == 0 {
+		types = []string{RecordTypeA}
+	}
+
+	var records []config.DNSRecord
+	for _, recordType := range types {
+		recordType = strings.ToUpper(recordType)
+		if recordType != RecordTypeA && recordType != RecordTypeAAAA {
+			helper.Warn(helper.LogTypeDDNS, "容器 %s 的记录类型 %s 不受支持，仅支持 A / AAAA", container.Name(), recordType)
+			continue
+		}
+
+		if ipType := container.Labels[DockerLabelIPType]; ipType != "" {
+			records = append(records, config.DNSRecord{Type: recordType, IPType: ipType, Value: container.Labels[DockerLabelValue]})
+			continue
+		}
+
+		addrType, staticType := helper.IPv4, "static_ipv4"
+		if recordType == RecordTypeAAAA {
+			addrType, staticType = helper.IPv6, "static_ipv6"
+		}
+		if addr := container.Addr(addrType); addr != "" {
+			records = append(records, config.DNSRecord{Type: recordType, IPType: staticType, Value: addr})
+			continue
+		}
+		inherited := false
+		for _, record := range template.Records {
+			if record.Type == recordType {
+				records = append(records, record)
+				inherited = true
+				break
+			}
+		}
+		if !inherited {
+			helper.Warn(helper.LogTypeDDNS, "容器 %s 没有可用于 %s 记录的地址，模板配置组中也没有 %s 记录，跳过", container.Name(), recordType, recordType)
+		}
+	}
+	return records
+}
+
+// findTemplateGroup 按 ID 或名称查找配置组
+func findTemplateGroup(groups []config.DNSGroup, name string) *config.DNSGroup {
+	if name == "" {
+		return nil
+	}
+	for i := range groups {
+		if groups[i].ID == name || groups[i].Name == name {
+			return &groups[i]
+		}
+	}
+	return nil
+}
+
+// splitLabel 拆分逗号分隔的标签值
+func splitLabel(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,76 @@
+package ddns
+
+import (
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// newTestContainer 构造带标签和网络地址的容器
+func newTestContainer(name string, labels map[string]string, ipv4, ipv6 string) helper.DockerContainer {
+	c := helper.DockerContainer{ID: name + "-id", Names: []string{"/" + name}, Labels: labels}
+	c.NetworkSettings.Networks = map[string]helper.DockerNetwork{
+		"bridge": {IPAddress: ipv4, GlobalIPv6Address: ipv6},
+	}
+	return c
+}
+
+// TestDockerGroups 测试根据容器标签生成配置组
+func TestDockerGroups(t *testing.T) {
+	groups := []config.DNSGroup{
+		{ID: "cf", Name: "Cloudflare", Domain: "home.example.com", Service: ProviderCloudflare, AccessKey: "token", TTL: "120",
+			Records: []config.DNSRecord{{Type: RecordTypeA, IPType: helper.DynamicIPv4URL, Value: "https://4.ipw.cn"}}},
+		{ID: "mock", Domain: "mock.example.com", Service: ProviderMock},
+	}
+	containers := []helper.DockerContainer{
+		newTestContainer("web", map[string]string{DockerLabelDomain: "app.example.com, www.example.com", DockerLabelType: "A,aaaa"}, "172.17.0.2", "2001:db8::2"),
+		newTestContainer("hostnet", map[string]string{DockerLabelDomain: "host.example.com"}, "", ""),
+		newTestContainer("custom", map[string]string{DockerLabelDomain: "custom.example.com", DockerLabelGroup: "mock",
+			DockerLabelType: "AAAA", DockerLabelIPType: helper.DynamicIPv6Interface, DockerLabelValue: "eth0"}, "", ""),
+		newTestContainer("zeta", map[string]string{DockerLabelDomain: "app.example.com,home.example.com"}, "172.17.0.3", ""),
+		newTestContainer("orphan", map[string]string{DockerLabelDomain: "orphan.example.com", DockerLabelGroup: "missing"}, "172.17.0.4", ""),
+		newTestContainer("unlabeled", map[string]string{}, "172.17.0.5", ""),
+	}
+
+	discovered := DockerGroups(config.DockerDiscovery{Enabled: true, Group: "cf"}, groups, containers)
+	byDomain := make(map[string]config.DNSGroup)
+	for _, group := range discovered {
+		byDomain[group.Domain] = group
+	}
+	if len(discovered) != 4 {
+		t.Fatalf("expected 4 groups, got %d: %+v", len(discovered), discovered)
+	}
+
+	app := byDomain["app.example.com"]
+	if app.ID != "docker/web/app.example.com" || app.Name != "web" || app.Service != ProviderCloudflare || app.AccessKey != "token" || app.TTL != "120" {
+		t.Errorf("unexpected app group: %+v", app)
+	}
+	if len(app.Records) != 2 || app.Records[0].Value != "172.17.0.2" || app.Records[1].Type != RecordTypeAAAA || app.Records[1].Value != "2001:db8::2" {
+		t.Errorf("unexpected app records: %+v", app.Records)
+	}
+	if _, ok := byDomain["www.example.com"]; !ok {
+		t.Error("expected group for second domain of web")
+	}
+
+	// 容器没有 IPv4 地址时沿用模板配置组的 A 记录获取方式
+	host := byDomain["host.example.com"]
+	if len(host.Records) != 1 || host.Records[0].IPType != helper.DynamicIPv4URL {
+		t.Errorf("expected host records inherited from template, got %+v", host.Records)
+	}
+
+	custom := byDomain["custom.example.com"]
+	if custom.Service != ProviderMock || len(custom.Records) != 1 || custom.Records[0].IPType != helper.DynamicIPv6Interface || custom.Records[0].Value != "eth0" {
+		t.Errorf("unexpected custom group: %+v", custom)
+	}
+
+	// app.example.com 归按名称排序靠前的 web；home.example.com 已在配置文件中配置
+	for _, group := range discovered {
+		if group.Domain == "home.example.com" {
+			t.Error("domain configured in YAML should not be overridden")
+		}
+		if group.Name == "zeta" || group.Name == "orphan" {
+			t.Errorf("unexpected group %+v", group)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ddns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func init() {
+	Register(ProviderDynDNS2, func() DNS { return &DynDNS2{} }, ProviderSchema{
+		Name:           "DynDNS2",
+		IDLabel:        "用户名 Username：",
+		SecretLabel:    "密码 Password：",
+		IDHelpHTML:     "<tip>兼容 No-IP、Dynu 等支持 <code>/nic/update</code> 协议的服务商，服务器地址默认 No-IP，可在 API 地址中修改（如 https://api.dynu.com）</tip>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          88,
+	})
+}
+
+// dynDNS2Endpoint 默认服务器（No-IP），地址不含路径时自动补全 /nic/update
+const (
+	dynDNS2Endpoint = "https://dynupdate.no-ip.com"
+	dynDNS2Path     = "/nic/update"
+)
+
+// dynDNS2Fatal 需要人工处理的返回码：继续请求可能导致账号被封禁，修改配置前不再请求
+var dynDNS2Fatal = map[string]string{
+	"badauth":  "用户名或密码错误",
+	"abuse":    "账号因请求过于频繁被封禁",
+	"!donator": "当前账号不支持该功能",
+	"notfqdn":  "域名不是完整的 FQDN",
+	"nohost":   "域名不存在或不属于该账号",
+	"numhost":  "一次请求的域名过多",
+	"badagent": "客户端被服务器拒绝",
+}
+
+// dynDNS2Temporary 服务端临时故障，下个周期重试
+var dynDNS2Temporary = map[string]string{
+	"dnserr": "服务端 DNS 错误",
+	"911":    "服务端故障或维护中",
+}
+
+// DynDNS2 经典 DynDNS2 协议（GET /nic/update?hostname=&myip=，HTTP Basic 认证）
+//
+// 字段映射：
+//   - AccessKey    -> 用户名
+//   - AccessSecret -> 密码（或服务商提供的更新令牌）
+//   - Endpoint     -> 服务器地址（可选，默认 No-IP）
+//
+// 仅支持 A / AAAA 记录，每条记录单独请求一次。
+type DynDNS2 struct {
+	BaseDNSProvider
+}
+
+// UpdateOrCreateRecords 遍历记录，值变化时调用 /nic/update
+func (d *DynDNS2) UpdateOrCreateRecords() []RecordResult {
+	validRecords := filterValidRecords(d.Group, d.Caches)
+	if len(validRecords) == 0 {
+		return []RecordResult{}
+	}
+
+	if d.Group.Domain == "" || d.Group.AccessKey == "" || d.Group.AccessSecret == "" {
+		return createErrorResults(validRecords, InitFailed, "配置不完整")
+	}
+
+	fingerprint := d.fingerprint()
+	results := make([]RecordResult, 0, len(validRecords))
+	for _, vr := range validRecords {
+		results = append(results, d.processRecord(vr.record, vr.cache, fingerprint))
+	}
+	return results
+}
+
+// processRecord 处理单条记录
+func (d *DynDNS2) processRecord(record *config.DNSRecord, cache *Cache, fingerprint string) RecordResult {
+	// 0. 服务器曾拒绝服务且配置未变化，不再请求
+	if cache.Halted != "" {
+		if cache.Halted == fingerprint {
+			helper.Warn(helper.LogTypeDDNS, "[%s] [%s] 服务器曾拒绝请求，修改配置前不再更新", d.GetServiceName(), record.Type)
+			return RecordResult{
+				RecordType:   record.Type,
+				Status:       UpdatedFailed,
+				ErrorMessage: "服务器曾拒绝请求，已暂停更新，请检查配置后保存",
+			}
+		}
+		helper.Info(helper.LogTypeDDNS, "[%s] [%s] 配置已变化，恢复更新", d.GetServiceName(), record.Type)
+		cache.Halted = ""
+	}
+
+	if record.Type != RecordTypeA && record.Type != RecordTypeAAAA {
+		helper.Error(helper.LogTypeDDNS, "[%s] 不支持的记录类型: %s", d.GetServiceName(), record.Type)
+		return RecordResult{RecordType: record.Type, Status: UpdatedFailed, ErrorMessage: "DynDNS2 仅支持 A / AAAA 记录"}
+	}
+
+	// 1. 获取当前值
+	currentValue, result, ok := getCurrentValue(d.GetServiceName(), record, cache)
+	if !ok {
+		return result
+	}
+
+	// 2. 检查缓存
+	if skip, r := checkDynamicCache(d.GetServiceName(), record, cache, currentValue, &result); skip {
+		return r
+	}
+
+	// 3. 发起更新
+	code, err := d.update(currentValue)
+	if err != nil {
+		result.Status = UpdatedFailed
+		result.ErrorMessage = err.Error()
+		result.ShouldWebhook = shouldSendWebhook(cache, UpdatedFailed)
+		if _, fatal := dynDNS2Fatal[code]; fatal {
+			cache.Halted = fingerprint
+			result.ShouldWebhook = true
+			helper.Error(helper.LogTypeDDNS, "[%s] [%s] 服务器拒绝请求，修改配置前不再更新 [返回=%s, 错误=%v]", d.GetServiceName(), record.Type, code, err)
+		} else {
+			helper.Error(helper.LogTypeDDNS, "[%s] [%s] 更新失败 [值=%s, 错误=%v]", d.GetServiceName(), record.Type, currentValue, err)
+		}
+		return result
+	}
+
+	// 4. 更新缓存（nochg 表示服务器上已是该值，不发送 Webhook）
+	finalizeSuccess(d.GetServiceName(), record, cache, currentValue, &result)
+	if code == "nochg" {
+		result.ShouldWebhook = false
+	}
+	return result
+}
+
+// update 发送更新请求，返回服务器返回码
+func (d *DynDNS2) update(ip string) (string, error) {
+	reqURL, err := d.updateURL(ip)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.SetBasicAuth(d.Group.AccessKey, d.Group.AccessSecret)
+	req.Header.Set("User-Agent", dynDNS2UserAgent())
+
+	client := helper.CreateHTTPClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+	text := strings.TrimSpace(string(body))
+
+	helper.Debug(helper.LogTypeDDNS, "[%s] API 响应 [状态码=%d, 响应=%s]", d.GetServiceName(), resp.StatusCode, text)
+
+	// 部分服务商认证失败时只返回 401 而没有正文
+	if resp.StatusCode == http.StatusUnauthorized && text == "" {
+		text = "badauth"
+	}
+	return parseDynDNS2Response(text)
+}
+
+// updateURL 构造请求地址
+func (d *DynDNS2) updateURL(ip string) (string, error) {
+	u, err := url.Parse(d.Group.GetEndpoint(dynDNS2Endpoint))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("服务器地址无效: %s", d.Group.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = dynDNS2Path
+	}
+	query := u.Query()
+	query.Set("hostname", d.Group.Domain)
+	query.Set("myip", ip)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// fingerprint 与服务端交互相关的配置指纹，任一项变化即解除暂停
+func (d *DynDNS2) fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		d.Group.GetEndpoint(dynDNS2Endpoint),
+		d.Group.Domain,
+		d.Group.AccessKey,
+		d.Group.AccessSecret,
+	}, "\x1f")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// parseDynDNS2Response 解析返回码：good / nochg 为成功，其余为失败
+func parseDynDNS2Response(text string) (string, error) {
+	code := text
+	if fields := strings.Fields(text); len(fields) > 0 {
+		code = fields[0]
+	}
+	switch code {
+	case "good", "nochg":
+		return code, nil
+	case "":
+		return code, fmt.Errorf("服务器返回空响应")
+	}
+	if msg, ok := dynDNS2Fatal[code]; ok {
+		return code, fmt.Errorf("%s: %s", code, msg)
+	}
+	if msg, ok := dynDNS2Temporary[code]; ok {
+		return code, fmt.Errorf("%s: %s", code, msg)
+	}
+	return code, fmt.Errorf("未知响应: %s", text)
+}
+
+// dynDNS2UserAgent 协议要求客户端提供可识别的 User-Agent
+func dynDNS2UserAgent() string {
+	version := os.Getenv(helper.VersionENV)
+	if version == "" {
+		version = "dev"
+	}
+	return "D-NET/" + version + " github.com/cxbdasheng/dnet"
+}
@@ -0,0 +1,181 @@
+package ddns
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+// newDynDNS2Group 构造一个 DynDNS2 测试配置组（单条静态记录）
+func newDynDNS2Group(endpoint string) (*config.DNSGroup, []*Cache) {
+	group := &config.DNSGroup{
+		Domain:       "home.example.com",
+		AccessKey:    "user",
+		AccessSecret: "pass",
+		Endpoint:     endpoint,
+		Records: []config.DNSRecord{
+			{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"},
+		},
+	}
+	c := NewCache()
+	return group, []*Cache{&c}
+}
+
+func runDynDNS2(group *config.DNSGroup, caches []*Cache) []RecordResult {
+	d := &DynDNS2{}
+	d.Init(group, caches)
+	return d.UpdateOrCreateRecords()
+}
+
+func TestDynDNS2Good(t *testing.T) {
+	var gotPath, gotQuery, gotUser, gotPass, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotUser, gotPass, _ = r.BasicAuth()
+		gotAgent = r.UserAgent()
+		io.WriteString(w, "good 1.2.3.4\n")
+	}))
+	defer srv.Close()
+
+	group, caches := newDynDNS2Group(srv.URL)
+	results := runDynDNS2(group, caches)
+
+	if len(results) != 1 || results[0].Status != UpdatedSuccess || !results[0].ShouldWebhook {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+	if gotPath != "/nic/update" || gotQuery != "hostname=home.example.com&myip=1.2.3.4" {
+		t.Errorf("请求地址不正确: %s?%s", gotPath, gotQuery)
+	}
+	if gotUser != "user" || gotPass != "pass" {
+		t.Errorf("Basic 认证不正确: %s / %s", gotUser, gotPass)
+	}
+	if !strings.HasPrefix(gotAgent, "D-NET/") {
+		t.Errorf("User-Agent 不正确: %s", gotAgent)
+	}
+}
+
+func TestDynDNS2CustomPathAndNochg(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		io.WriteString(w, "nochg 1.2.3.4")
+	}))
+	defer srv.Close()
+
+	group, caches := newDynDNS2Group(srv.URL + "/v3/update")
+	results := runDynDNS2(group, caches)
+
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+	if results[0].ShouldWebhook {
+		t.Error("nochg 不应发送 Webhook")
+	}
+	if gotPath != "/v3/update" {
+		t.Errorf("应保留自定义路径, 实际: %s", gotPath)
+	}
+}
+
+func TestDynDNS2BadauthHaltsUntilConfigChanges(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if _, pass, _ := r.BasicAuth(); pass != "fixed" {
+			io.WriteString(w, "badauth")
+			return
+		}
+		io.WriteString(w, "good 1.2.3.4")
+	}))
+	defer srv.Close()
+
+	group, caches := newDynDNS2Group(srv.URL)
+	results := runDynDNS2(group, caches)
+	if results[0].Status != UpdatedFailed || !strings.Contains(results[0].ErrorMessage, "badauth") || !results[0].ShouldWebhook {
+		t.Fatalf("期望 badauth 失败并发送 Webhook, 实际: %+v", results[0])
+	}
+	if caches[0].Halted == "" {
+		t.Fatal("badauth 后应暂停更新")
+	}
+
+	// 配置未变化：不再请求
+	for i := 0; i < 3; i++ {
+		results = runDynDNS2(group, caches)
+		if results[0].Status != UpdatedFailed || results[0].ShouldWebhook {
+			t.Fatalf("暂停期间期望失败且不发送 Webhook, 实际: %+v", results[0])
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("暂停期间不应请求服务器, 实际请求 %d 次", calls)
+	}
+
+	// 暂停状态可持久化
+	restored := NewCache()
+	restored.RestoreState(caches[0].State())
+	if restored.Halted != caches[0].Halted {
+		t.Error("暂停状态应随缓存状态持久化")
+	}
+
+	// 修改密码后恢复
+	group.AccessSecret = "fixed"
+	results = runDynDNS2(group, caches)
+	if results[0].Status != UpdatedSuccess || calls != 2 || caches[0].Halted != "" {
+		t.Fatalf("配置变化后应恢复更新, 实际: %+v, 请求 %d 次", results[0], calls)
+	}
+}
+
+func TestDynDNS2TemporaryErrorRetries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, "911")
+	}))
+	defer srv.Close()
+
+	group, caches := newDynDNS2Group(srv.URL)
+	runDynDNS2(group, caches)
+	results := runDynDNS2(group, caches)
+
+	if results[0].Status != UpdatedFailed || !strings.Contains(results[0].ErrorMessage, "911") {
+		t.Fatalf("期望 911 失败, 实际: %+v", results[0])
+	}
+	if calls != 2 || caches[0].Halted != "" {
+		t.Errorf("临时故障不应暂停更新, 请求 %d 次", calls)
+	}
+}
+
+func TestParseDynDNS2Response(t *testing.T) {
+	tests := []struct {
+		text    string
+		code    string
+		wantErr bool
+	}{
+		{"good 1.2.3.4", "good", false},
+		{"nochg", "nochg", false},
+		{"abuse", "abuse", true},
+		{"nohost", "nohost", true},
+		{"dnserr", "dnserr", true},
+		{"<html>oops</html>", "<html>oops</html>", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		code, err := parseDynDNS2Response(tt.text)
+		if code != tt.code || (err != nil) != tt.wantErr {
+			t.Errorf("parseDynDNS2Response(%q) = %q, %v", tt.text, code, err)
+		}
+	}
+}
+
+func TestDynDNS2RejectsUnsupportedType(t *testing.T) {
+	group, caches := newDynDNS2Group("http://127.0.0.1:1")
+	group.Records[0] = config.DNSRecord{Type: RecordTypeTXT, Value: "hello"}
+	results := runDynDNS2(group, caches)
+
+	if len(results) != 1 || results[0].Status != UpdatedFailed {
+		t.Fatalf("期望 TXT 记录失败, 实际: %+v", results)
+	}
+}
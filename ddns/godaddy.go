@@ -14,7 +14,20 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
-// goDaddyAPIEndpoint GoDaddy API 基础地址（var 以便测试时覆盖）
+func init() {
+	Register(ProviderGoDaddy, func() DNS { return &GoDaddy{} }, ProviderSchema{
+		Name:           "GoDaddy",
+		IDLabel:        "API Key：",
+		SecretLabel:    "API Secret：",
+		IDHelpHTML:     "<a target='_blank' href='https://developer.godaddy.com/keys'>创建 GoDaddy API 密钥</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          80,
+	})
+}
+
+// goDaddyAPIEndpoint GoDaddy API 默认地址（var 以便测试时覆盖），可通过 DNSGroup.Endpoint 覆盖
 var goDaddyAPIEndpoint = "https://api.godaddy.com/v1"
 
 // GoDaddy TTL 最小值为 600 秒
@@ -220,7 +233,7 @@ func (g *GoDaddy) deleteRecordType(recordType string) error {
 
 // request 统一请求方法
 func (g *GoDaddy) request(method, urlPath string, body interface{}, result interface{}) error {
-	reqURL := goDaddyAPIEndpoint + urlPath
+	reqURL := g.Group.GetEndpoint(goDaddyAPIEndpoint) + urlPath
 
 	var reqBody io.Reader
 	if body != nil {
@@ -251,3 +251,30 @@ func TestGoDaddyRecordUnmarshal(t *testing.T) {
 		t.Errorf("解析结果不正确: %+v", recs)
 	}
 }
+
+func TestGoDaddyUsesGroupEndpoint(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if r.URL.Path != "/proxy/domains/example.com/records" {
+			t.Errorf("意外请求: %s %s", r.Method, r.URL.Path)
+		}
+		io.WriteString(w, `[{"type":"A","name":"www","data":"1.2.3.4","ttl":600}]`)
+	}))
+	defer srv.Close()
+	// 全局默认地址指向不可用地址，确保请求走配置组的自定义地址
+	withGoDaddyEndpoint(t, "http://127.0.0.1:0")
+
+	group, caches := newGoDaddyGroup(RecordTypeA, "static_ipv4", "1.2.3.4")
+	group.Endpoint = srv.URL + "/proxy/"
+	g := &GoDaddy{}
+	g.Init(group, caches)
+	results := g.UpdateOrCreateRecords()
+
+	if !called {
+		t.Fatal("期望请求发送到自定义 API 地址")
+	}
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+}
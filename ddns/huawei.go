@@ -14,7 +14,20 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
-// 华为云 DNS API Endpoint (根据区域可能不同,这里使用华北-北京四)
+func init() {
+	Register(ProviderHuawei, func() DNS { return &Huawei{} }, ProviderSchema{
+		Name:           "华为云",
+		IDLabel:        "Access Key ID：",
+		SecretLabel:    "Secret Access Key：",
+		IDHelpHTML:     "<a target='_blank' href='https://console.huaweicloud.com/iam/#/mine/accessKey'>创建访问密钥</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          50,
+	})
+}
+
+// 华为云 DNS API 默认地址 (根据区域可能不同,这里使用华北-北京四)，可通过 DNSGroup.Endpoint 覆盖
 // 完整的区域列表: https://developer.huaweicloud.com/endpoint?DNS
 const huaweiDNSEndpoint = "https://dns.myhuaweicloud.com"
 
@@ -331,7 +344,7 @@ func (h *Huawei) deleteRecordSet(recordID string) error {
 
 // request 统一请求方法
 func (h *Huawei) request(method, path string, body interface{}, result interface{}) error {
-	reqURL := huaweiDNSEndpoint + path
+	reqURL := h.Group.GetEndpoint(huaweiDNSEndpoint) + path
 
 	var reqBody []byte
 	var err error
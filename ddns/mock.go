@@ -5,6 +5,15 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderMock, func() DNS { return &Mock{} }, ProviderSchema{
+		Name:       "模拟测试",
+		IDHelpHTML: "<tip>模拟测试不发起真实请求，仅打印日志，用于验证配置与 Webhook 通道</tip>",
+		Order:      1000,
+		Hidden:     true,
+	})
+}
+
 // Mock 模拟测试 DNS 提供商——不发起任何真实 API 请求，
 // 仅走完整状态机并打印"若在真实环境将做什么"。
 // 用途：验证配置、观察 IP 探测流程、测试 Webhook 通道。
@@ -64,3 +73,16 @@ func (m *Mock) processRecord(record *config.DNSRecord, cache *Cache) RecordResul
 	finalizeSuccess(m.GetServiceName(), record, cache, currentValue, &result)
 	return result
 }
+
+// RemoveRecords 模拟删除记录，不发起真实请求
+func (m *Mock) RemoveRecords(recordTypes []string) []RecordResult {
+	if m.Group == nil || m.Group.Domain == "" {
+		return createRemoveErrorResults(recordTypes, "配置不完整")
+	}
+	results := make([]RecordResult, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		helper.Info(helper.LogTypeDDNS, "[%s] [MOCK] [%s] 若在真实环境将删除记录 [域名=%s]", m.GetServiceName(), recordType, m.Group.Domain)
+		results = append(results, RecordResult{RecordType: recordType, Status: RemovedSuccess})
+	}
+	return results
+}
@@ -11,11 +11,25 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
+func init() {
+	Register(ProviderNameSilo, func() DNS { return &NameSilo{} }, ProviderSchema{
+		Name:           "NameSilo",
+		SecretLabel:    "API Key：",
+		IDHelpHTML:     "<a target='_blank' href='https://www.namesilo.com/account/api-manager'>获取 NameSilo API Key</a>",
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          70,
+	})
+}
+
+// nameSiloAPIEndpoint NameSilo API 默认地址，可通过 DNSGroup.Endpoint 覆盖
+const nameSiloAPIEndpoint = "https://www.namesilo.com/api"
+
 const (
-	nameSiloListRecordEndpoint   = "https://www.namesilo.com/api/dnsListRecords?version=1&type=xml&key=%s&domain=%s"
-	nameSiloAddRecordEndpoint    = "https://www.namesilo.com/api/dnsAddRecord?version=1&type=xml&key=%s&domain=%s&rrhost=%s&rrtype=%s&rrvalue=%s&rrttl=3600"
-	nameSiloUpdateRecordEndpoint = "https://www.namesilo.com/api/dnsUpdateRecord?version=1&type=xml&key=%s&domain=%s&rrhost=%s&rrid=%s&rrvalue=%s&rrttl=3600"
-	nameSiloDeleteRecordEndpoint = "https://www.namesilo.com/api/dnsDeleteRecord?version=1&type=xml&key=%s&domain=%s&rrid=%s"
+	nameSiloListRecordEndpoint   = "/dnsListRecords?version=1&type=xml&key=%s&domain=%s"
+	nameSiloAddRecordEndpoint    = "/dnsAddRecord?version=1&type=xml&key=%s&domain=%s&rrhost=%s&rrtype=%s&rrvalue=%s&rrttl=3600"
+	nameSiloUpdateRecordEndpoint = "/dnsUpdateRecord?version=1&type=xml&key=%s&domain=%s&rrhost=%s&rrid=%s&rrvalue=%s&rrttl=3600"
+	nameSiloDeleteRecordEndpoint = "/dnsDeleteRecord?version=1&type=xml&key=%s&domain=%s&rrid=%s"
 )
 
 type NameSilo struct {
@@ -194,10 +208,10 @@ func (n *NameSilo) processRecord(record *config.DNSRecord, cache *Cache, existin
 // listAllRecords 查询指定域名下与当前主机记录匹配的所有 DNS 记录
 func (n *NameSilo) listAllRecords() ([]nameSiloResourceItem, error) {
 	rootDomain := getRootDomain(n.Group.Domain)
-	apiURL := fmt.Sprintf(nameSiloListRecordEndpoint, n.Group.AccessSecret, rootDomain)
+	apiPath := fmt.Sprintf(nameSiloListRecordEndpoint, n.Group.AccessSecret, rootDomain)
 
 	var resp nameSiloListResp
-	if err := n.request(apiURL, &resp); err != nil {
+	if err := n.request(apiPath, &resp); err != nil {
 		return nil, err
 	}
 	if resp.Reply.Code != 300 {
@@ -219,10 +233,10 @@ func (n *NameSilo) listAllRecords() ([]nameSiloResourceItem, error) {
 func (n *NameSilo) addDomainRecord(recordType, value string) error {
 	rootDomain := getRootDomain(n.Group.Domain)
 	host := getHostRecord(n.Group.Domain)
-	apiURL := fmt.Sprintf(nameSiloAddRecordEndpoint, n.Group.AccessSecret, rootDomain, host, recordType, value)
+	apiPath := fmt.Sprintf(nameSiloAddRecordEndpoint, n.Group.AccessSecret, rootDomain, host, recordType, value)
 
 	var resp nameSiloResp
-	if err := n.request(apiURL, &resp); err != nil {
+	if err := n.request(apiPath, &resp); err != nil {
 		helper.Error(helper.LogTypeDDNS, "[%s] [%s] 创建 DNS 记录失败 [域名=%s, 错误=%v]", n.GetServiceName(), recordType, n.Group.Domain, err)
 		return err
 	}
@@ -240,10 +254,10 @@ func (n *NameSilo) addDomainRecord(recordType, value string) error {
 func (n *NameSilo) updateDomainRecord(recordID, recordType, value string) error {
 	rootDomain := getRootDomain(n.Group.Domain)
 	host := getHostRecord(n.Group.Domain)
-	apiURL := fmt.Sprintf(nameSiloUpdateRecordEndpoint, n.Group.AccessSecret, rootDomain, host, recordID, value)
+	apiPath := fmt.Sprintf(nameSiloUpdateRecordEndpoint, n.Group.AccessSecret, rootDomain, host, recordID, value)
 
 	var resp nameSiloResp
-	if err := n.request(apiURL, &resp); err != nil {
+	if err := n.request(apiPath, &resp); err != nil {
 		helper.Error(helper.LogTypeDDNS, "[%s] [%s] 更新 DNS 记录失败 [RecordId=%s, 错误=%v]", n.GetServiceName(), recordType, recordID, err)
 		return err
 	}
@@ -260,10 +274,10 @@ func (n *NameSilo) updateDomainRecord(recordID, recordType, value string) error
 // deleteDomainRecord 删除 DNS 记录
 func (n *NameSilo) deleteDomainRecord(recordID string) error {
 	rootDomain := getRootDomain(n.Group.Domain)
-	apiURL := fmt.Sprintf(nameSiloDeleteRecordEndpoint, n.Group.AccessSecret, rootDomain, recordID)
+	apiPath := fmt.Sprintf(nameSiloDeleteRecordEndpoint, n.Group.AccessSecret, rootDomain, recordID)
 
 	var resp nameSiloResp
-	if err := n.request(apiURL, &resp); err != nil {
+	if err := n.request(apiPath, &resp); err != nil {
 		helper.Error(helper.LogTypeDDNS, "[%s] 删除 DNS 记录失败 [RecordId=%s, 错误=%v]", n.GetServiceName(), recordID, err)
 		return err
 	}
@@ -290,8 +304,8 @@ func (n *NameSilo) deleteRecords(records []nameSiloResourceItem, contextType str
 }
 
 // request 发送 GET 请求并解析 XML 响应
-func (n *NameSilo) request(apiURL string, result interface{}) error {
-	req, err := http.NewRequest(http.MethodGet, apiURL, http.NoBody)
+func (n *NameSilo) request(apiPath string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, n.Group.GetEndpoint(nameSiloAPIEndpoint)+apiPath, http.NoBody)
 	if err != nil {
 		return err
 	}
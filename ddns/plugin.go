@@ -0,0 +1,207 @@
+package ddns
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func init() {
+	Register(ProviderExecPlugin, func() DNS { return &ExecPlugin{} }, ProviderSchema{
+		Name:           "Exec 插件",
+		IDLabel:        "插件命令：",
+		SecretLabel:    "插件凭证（可选）：",
+		IDHelpHTML:     "<tip>启动外部程序对接自建 DNS：通过 stdin 发送 JSON 请求（list / upsert / delete），从 stdout 读取逐条结果。凭证与自定义 API 地址会原样透传给插件。</tip>",
+		RequireKey:     true,
+		CustomEndpoint: true,
+		Order:          95,
+	})
+}
+
+// ExecPlugin 外部插件 DNS 提供商。
+// 每次同步先以 list 查询远端当前值，再对值不同的记录发送一次 upsert，
+// 插件的逐条结果映射为 RecordResult，缓存、Webhook 与同步历史与内置提供商一致。
+//
+// 字段映射：
+//   - AccessKey    -> 插件命令（必填，经 shell 执行，可带参数）
+//   - AccessSecret -> 插件凭证（可选，透传为请求中的 secret）
+//   - Endpoint     -> 自定义 API 地址（可选，透传为请求中的 endpoint）
+type ExecPlugin struct {
+	BaseDNSProvider
+}
+
+// pluginRecord 需要调用插件的记录
+type pluginRecord struct {
+	index int
+	validRecord
+	value string
+}
+
+// Init 初始化（只需要域名和插件命令）
+func (p *ExecPlugin) Init(group *config.DNSGroup, caches []*Cache) {
+	p.Group = group
+	p.Caches = caches
+
+	if group == nil || group.Domain == "" || strings.TrimSpace(group.AccessKey) == "" {
+		helper.Error(helper.LogTypeDDNS, "[%s] 初始化失败: 配置不完整（需要域名和插件命令）", p.GetServiceName())
+		return
+	}
+
+	if len(caches) > 0 && !caches[0].HasRun {
+		helper.Info(helper.LogTypeDDNS, "[%s] 初始化成功，共 %d 条记录", p.GetServiceName(), len(caches))
+	}
+}
+
+// UpdateOrCreateRecords 值变化的记录先与远端比对，不一致时通过插件更新
+func (p *ExecPlugin) UpdateOrCreateRecords() []RecordResult {
+	validRecords := filterValidRecords(p.Group, p.Caches)
+	if len(validRecords) == 0 {
+		return []RecordResult{}
+	}
+
+	if p.Group.Domain == "" || strings.TrimSpace(p.Group.AccessKey) == "" {
+		return createErrorResults(validRecords, InitFailed, "配置不完整（需要域名和插件命令）")
+	}
+
+	// 1. 获取当前值并检查缓存，未变化的记录不调用插件
+	results := make([]RecordResult, len(validRecords))
+	var pending []pluginRecord
+	for i, vr := range validRecords {
+		currentValue, result, ok := getCurrentValue(p.GetServiceName(), vr.record, vr.cache)
+		if !ok {
+			results[i] = result
+			continue
+		}
+		if skip, r := checkDynamicCache(p.GetServiceName(), vr.record, vr.cache, currentValue, &result); skip {
+			results[i] = r
+			continue
+		}
+		results[i] = result
+		pending = append(pending, pluginRecord{index: i, validRecord: vr, value: currentValue})
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	// 2. 查询远端当前值
+	listed, err := p.call(helper.PluginActionList, nil)
+	if err != nil {
+		for _, pr := range pending {
+			p.fail(&results[pr.index], pr.cache, err)
+		}
+		return results
+	}
+	// 同类型可能有多条记录，远端存在相同类型和值的记录即视为未变化
+	remote := make(map[string]bool, len(listed.Records))
+	for _, rec := range listed.Records {
+		remote[strings.ToUpper(rec.Type)+" "+rec.Value] = true
+	}
+
+	// 3. 远端值不同的记录合并为一次 upsert，以序号作为 id 对应逐条结果
+	var upserts []pluginRecord
+	var records []helper.PluginRecord
+	for _, pr := range pending {
+		if remote[pr.record.Type+" "+pr.value] {
+			helper.Debug(helper.LogTypeDDNS, "[%s] [%s] 记录值未变化，无需更新 [值=%s]", p.GetServiceName(), pr.record.Type, pr.value)
+			finalizeSuccess(p.GetServiceName(), pr.record, pr.cache, pr.value, &results[pr.index])
+			continue
+		}
+		upserts = append(upserts, pr)
+		records = append(records, helper.PluginRecord{ID: strconv.Itoa(pr.index), Type: pr.record.Type, Value: pr.value})
+	}
+	if len(upserts) == 0 {
+		return results
+	}
+
+	updated, err := p.call(helper.PluginActionUpsert, records)
+	for i, pr := range upserts {
+		result := &results[pr.index]
+		if err != nil {
+			p.fail(result, pr.cache, err)
+			continue
+		}
+		pluginResult, ok := updated.Result(records[i])
+		switch {
+		case !ok:
+			p.fail(result, pr.cache, errors.New("插件未返回该记录的结果"))
+		case pluginResult.Status != helper.PluginStatusUpdated && pluginResult.Status != helper.PluginStatusUnchanged:
+			p.fail(result, pr.cache, pluginError(pluginResult))
+		default:
+			if result.OldValue == "" {
+				result.OldValue = pluginResult.OldValue
+			}
+			finalizeSuccess(p.GetServiceName(), pr.record, pr.cache, pr.value, result)
+		}
+	}
+	return results
+}
+
+// RemoveRecords 通过插件删除指定类型的记录
+func (p *ExecPlugin) RemoveRecords(recordTypes []string) []RecordResult {
+	if p.Group == nil || p.Group.Domain == "" || strings.TrimSpace(p.Group.AccessKey) == "" {
+		return createRemoveErrorResults(recordTypes, "配置不完整（需要域名和插件命令）")
+	}
+	records := make([]helper.PluginRecord, 0, len(recordTypes))
+	for i, recordType := range recordTypes {
+		records = append(records, helper.PluginRecord{ID: strconv.Itoa(i), Type: recordType})
+	}
+	deleted, err := p.call(helper.PluginActionDelete, records)
+	if err != nil {
+		return createRemoveErrorResults(recordTypes, err.Error())
+	}
+
+	results := make([]RecordResult, 0, len(recordTypes))
+	for i, recordType := range recordTypes {
+		result := RecordResult{RecordType: recordType, Status: UpdatedNothing}
+		pluginResult, ok := deleted.Result(records[i])
+		switch {
+		case !ok:
+			result.Status = UpdatedFailed
+			result.ErrorMessage = "插件未返回该记录的结果"
+		case pluginResult.Status == helper.PluginStatusDeleted:
+			result.Status = RemovedSuccess
+			result.OldValue = pluginResult.OldValue
+		case pluginResult.Status != helper.PluginStatusUnchanged:
+			result.Status = UpdatedFailed
+			result.ErrorMessage = pluginError(pluginResult).Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// call 以当前配置组调用插件
+func (p *ExecPlugin) call(action string, records []helper.PluginRecord) (helper.PluginResponse, error) {
+	response, err := helper.RunPlugin(p.Group.AccessKey, helper.PluginTimeout(), helper.PluginRequest{
+		Kind:     "ddns",
+		Action:   action,
+		Domain:   p.Group.Domain,
+		TTL:      p.Group.TTL,
+		Secret:   p.Group.AccessSecret,
+		Endpoint: p.Group.Endpoint,
+		Records:  records,
+	})
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 插件调用失败 [动作=%s, 错误=%v]", p.GetServiceName(), action, err)
+	}
+	return response, err
+}
+
+// fail 标记记录更新失败
+func (p *ExecPlugin) fail(result *RecordResult, cache *Cache, err error) {
+	result.Status = UpdatedFailed
+	result.ErrorMessage = err.Error()
+	result.ShouldWebhook = shouldSendWebhook(cache, UpdatedFailed)
+	helper.Error(helper.LogTypeDDNS, "[%s] [%s] 插件更新记录失败 [错误=%v]", p.GetServiceName(), result.RecordType, err)
+}
+
+// pluginError 插件逐条结果中的错误信息
+func pluginError(result helper.PluginResult) error {
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	return errors.New("插件返回状态 " + result.Status)
+}
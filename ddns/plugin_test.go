@@ -0,0 +1,170 @@
+package ddns
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+// newExecPluginGroup 写入插件脚本并构造测试配置组（A、AAAA 两条静态记录）
+// 脚本把每次请求追加到 requests.log，按动作返回预置的响应
+func newExecPluginGroup(t *testing.T, list, upsert, remove string) (*config.DNSGroup, []*Cache, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("插件脚本测试依赖 sh")
+	}
+	dir := t.TempDir()
+	script := `req=$(cat)
+echo "$req" >> "$(dirname "$0")/requests.log"
+case "$req" in
+*'"action":"list"'*) echo '` + list + `' ;;
+*'"action":"upsert"'*) echo '` + upsert + `' ;;
+*'"action":"delete"'*) echo '` + remove + `' ;;
+esac
+`
+	plugin := filepath.Join(dir, "plugin.sh")
+	if err := os.WriteFile(plugin, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	group := &config.DNSGroup{
+		Domain:       "www.example.com",
+		Service:      ProviderExecPlugin,
+		AccessKey:    plugin,
+		AccessSecret: "secret",
+		TTL:          "600",
+		Records: []config.DNSRecord{
+			{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"},
+			{Type: RecordTypeAAAA, IPType: "static_ipv6", Value: "2001:db8::1"},
+		},
+	}
+	caches := make([]*Cache, len(group.Records))
+	for i := range caches {
+		c := NewCache()
+		caches[i] = &c
+	}
+	return group, caches, filepath.Join(dir, "requests.log")
+}
+
+// readPluginRequests 读取插件收到的请求
+func readPluginRequests(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestExecPluginUpdateOrCreateRecords(t *testing.T) {
+	group, caches, log := newExecPluginGroup(t,
+		`{"records":[{"type":"A","value":"1.2.3.4"},{"type":"AAAA","value":"2001:db8::9"}]}`,
+		`{"results":[{"type":"AAAA","status":"updated","old_value":"2001:db8::9"}]}`,
+		`{}`)
+
+	p := &ExecPlugin{}
+	p.Init(group, caches)
+	results := p.UpdateOrCreateRecords()
+
+	if len(results) != 2 || results[0].Status != UpdatedSuccess || results[1].Status != UpdatedSuccess {
+		t.Fatalf("期望 2 条成功结果, 实际: %+v", results)
+	}
+	if results[1].OldValue != "2001:db8::9" {
+		t.Errorf("AAAA 记录旧值应取插件返回的 old_value: %+v", results[1])
+	}
+
+	// 远端 A 记录已是当前值，只对 AAAA 发送 upsert
+	requests := readPluginRequests(t, log)
+	if len(requests) != 2 {
+		t.Fatalf("期望 list、upsert 两次调用, 实际: %v", requests)
+	}
+	upsert := requests[1]
+	if !strings.Contains(upsert, `"records":[{"id":"1","type":"AAAA","value":"2001:db8::1"}]`) ||
+		!strings.Contains(upsert, `"secret":"secret"`) || !strings.Contains(upsert, `"ttl":"600"`) {
+		t.Errorf("upsert 请求不正确: %s", upsert)
+	}
+}
+
+// TestExecPluginSameTypeRecords 同类型的多条记录按值比对远端、按 id 对应结果
+func TestExecPluginSameTypeRecords(t *testing.T) {
+	group, _, log := newExecPluginGroup(t,
+		`{"records":[{"type":"A","value":"1.2.3.4"},{"type":"A","value":"5.6.7.8"}]}`,
+		`{"results":[{"id":"2","type":"A","status":"failed","error":"quota exceeded"},{"id":"1","type":"A","status":"updated","old_value":"5.6.7.8"}]}`,
+		`{}`)
+	group.Records = []config.DNSRecord{
+		{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"},
+		{Type: RecordTypeA, IPType: "static_ipv4", Value: "5.6.7.9"},
+		{Type: RecordTypeA, IPType: "static_ipv4", Value: "9.9.9.9"},
+	}
+	caches := make([]*Cache, len(group.Records))
+	for i := range caches {
+		c := NewCache()
+		caches[i] = &c
+	}
+
+	p := &ExecPlugin{}
+	p.Init(group, caches)
+	results := p.UpdateOrCreateRecords()
+
+	if len(results) != 3 {
+		t.Fatalf("期望 3 条结果, 实际: %+v", results)
+	}
+	if results[0].Status != UpdatedSuccess {
+		t.Errorf("远端已有相同值的第一条 A 记录应成功: %+v", results[0])
+	}
+	if results[1].Status != UpdatedSuccess || results[1].OldValue != "5.6.7.8" {
+		t.Errorf("第二条 A 记录应取 id 为 1 的结果: %+v", results[1])
+	}
+	if results[2].Status != UpdatedFailed || results[2].ErrorMessage != "quota exceeded" {
+		t.Errorf("第三条 A 记录应取 id 为 2 的结果: %+v", results[2])
+	}
+
+	requests := readPluginRequests(t, log)
+	if len(requests) != 2 || !strings.Contains(requests[1],
+		`"records":[{"id":"1","type":"A","value":"5.6.7.9"},{"id":"2","type":"A","value":"9.9.9.9"}]`) {
+		t.Errorf("upsert 请求不正确: %v", requests)
+	}
+}
+
+func TestExecPluginUpdateFailed(t *testing.T) {
+	group, caches, _ := newExecPluginGroup(t,
+		`{"records":[]}`,
+		`{"results":[{"type":"A","status":"failed","error":"quota exceeded"}]}`,
+		`{}`)
+
+	p := &ExecPlugin{}
+	p.Init(group, caches)
+	results := p.UpdateOrCreateRecords()
+
+	if len(results) != 2 {
+		t.Fatalf("期望 2 条结果, 实际: %+v", results)
+	}
+	if results[0].Status != UpdatedFailed || results[0].ErrorMessage != "quota exceeded" {
+		t.Errorf("A 记录应失败并带插件错误: %+v", results[0])
+	}
+	// 插件未返回 AAAA 的结果
+	if results[1].Status != UpdatedFailed {
+		t.Errorf("缺少结果的记录应视为失败: %+v", results[1])
+	}
+}
+
+func TestExecPluginRemoveRecords(t *testing.T) {
+	group, caches, log := newExecPluginGroup(t,
+		`{}`,
+		`{}`,
+		`{"results":[{"type":"A","status":"deleted","old_value":"1.2.3.4"},{"type":"AAAA","status":"unchanged"}]}`)
+
+	p := &ExecPlugin{}
+	p.Init(group, caches)
+	results := p.RemoveRecords([]string{RecordTypeA, RecordTypeAAAA})
+
+	if len(results) != 2 || results[0].Status != RemovedSuccess || results[0].OldValue != "1.2.3.4" || results[1].Status != UpdatedNothing {
+		t.Errorf("删除结果不正确: %+v", results)
+	}
+	if requests := readPluginRequests(t, log); len(requests) != 1 || !strings.Contains(requests[0], `"action":"delete"`) {
+		t.Errorf("期望一次 delete 调用, 实际: %v", requests)
+	}
+}
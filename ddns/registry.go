@@ -0,0 +1,102 @@
+package ddns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// ProviderSchema 提供商的配置说明，Web 界面与配置校验共用
+type ProviderSchema struct {
+	Name           string `json:"name"`           // 显示名称
+	IDLabel        string `json:"idLabel"`        // AccessKey 的含义，为空表示不使用
+	SecretLabel    string `json:"secretLabel"`    // AccessSecret 的含义，为空表示不使用
+	IDHelpHTML     string `json:"idHelpHtml"`     // 凭证获取说明（HTML）
+	RequireKey     bool   `json:"requireKey"`     // AccessKey 是否必填
+	RequireSecret  bool   `json:"requireSecret"`  // AccessSecret 是否必填
+	CustomEndpoint bool   `json:"customEndpoint"` // 是否支持自定义 API 地址
+	Order          int    `json:"-"`              // 界面展示顺序，越小越靠前
+	Hidden         bool   `json:"-"`              // 不在界面中展示（如模拟测试），但仍可通过配置文件使用
+}
+
+// SortOrder 实现 helper.RegistrySchema
+func (s ProviderSchema) SortOrder() int {
+	return s.Order
+}
+
+// IsHidden 实现 helper.RegistrySchema
+func (s ProviderSchema) IsHidden() bool {
+	return s.Hidden
+}
+
+// Provider 已注册的 DNS 提供商
+type Provider = helper.RegistryEntry[DNS, ProviderSchema]
+
+var registry = helper.NewRegistry[DNS, ProviderSchema]("ddns")
+
+// Register 注册 DNS 提供商，通常在提供商文件的 init 中调用；重复注册会 panic
+func Register(id string, newFunc func() DNS, schema ProviderSchema) {
+	registry.Register(id, newFunc, schema)
+}
+
+// LookupProvider 按 Service 查找已注册的提供商
+func LookupProvider(id string) (Provider, bool) {
+	return registry.Lookup(id)
+}
+
+// NewProvider 按 Service 创建提供商实例
+func NewProvider(id string) (DNS, error) {
+	p, ok := LookupProvider(id)
+	if !ok {
+		return nil, fmt.Errorf("不支持的 DNS 提供商: %s", id)
+	}
+	return p.New(), nil
+}
+
+// Providers 返回按展示顺序排列的所有已注册提供商
+func Providers() []Provider {
+	return registry.List()
+}
+
+// ProvidersJSON 返回供 Web 界面使用的提供商说明（按展示顺序的 JSON 对象，不含隐藏项）
+func ProvidersJSON() string {
+	return registry.JSON()
+}
+
+// ValidateGroup 按提供商的配置说明校验解析组
+func ValidateGroup(group *config.DNSGroup) error {
+	if group == nil {
+		return errors.New("配置为空")
+	}
+	p, ok := LookupProvider(group.Service)
+	if !ok {
+		return fmt.Errorf("不支持的 DNS 提供商: %s", group.Service)
+	}
+	if strings.TrimSpace(group.Domain) == "" {
+		return fmt.Errorf("[%s] 域名不能为空", p.Schema.Name)
+	}
+	if p.Schema.RequireKey && strings.TrimSpace(group.AccessKey) == "" {
+		return fmt.Errorf("[%s] %s不能为空", group.Domain, helper.FieldLabel(p.Schema.IDLabel, "AccessKey"))
+	}
+	if p.Schema.RequireSecret && strings.TrimSpace(group.AccessSecret) == "" {
+		return fmt.Errorf("[%s] %s不能为空", group.Domain, helper.FieldLabel(p.Schema.SecretLabel, "AccessSecret"))
+	}
+	if strings.TrimSpace(group.Endpoint) != "" {
+		if !p.Schema.CustomEndpoint {
+			return fmt.Errorf("[%s] %s 不支持自定义 API 地址", group.Domain, p.Schema.Name)
+		}
+		if err := config.ValidateEndpoint(group.Endpoint); err != nil {
+			return fmt.Errorf("[%s] %v", group.Domain, err)
+		}
+	}
+	for i := range group.Records {
+		record := &group.Records[i]
+		if err := record.AddrSource().Validate(); err != nil {
+			return fmt.Errorf("[%s] %s 记录: %v", group.Domain, record.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package ddns
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func TestBuiltinProvidersRegistered(t *testing.T) {
+	ids := []string{
+		ProviderAliDNS, ProviderTencent, ProviderBaiduCloud, ProviderCloudflare, ProviderHuawei,
+		ProviderDnspod, ProviderNameSilo, ProviderGoDaddy, ProviderCallback, ProviderRFC2136, ProviderExecPlugin, ProviderMock,
+	}
+	for _, id := range ids {
+		p, err := NewProvider(id)
+		if err != nil || p == nil {
+			t.Errorf("提供商 %s 未注册: %v", id, err)
+		}
+	}
+	if _, err := NewProvider("unknown"); err == nil {
+		t.Error("未注册的提供商应返回错误")
+	}
+}
+
+func TestNewProviderReturnsFreshInstance(t *testing.T) {
+	a, _ := NewProvider(ProviderMock)
+	b, _ := NewProvider(ProviderMock)
+	if a == b {
+		t.Error("每次调用应返回新的实例")
+	}
+}
+
+func TestProvidersJSONOrderAndHidden(t *testing.T) {
+	raw := ProvidersJSON()
+	var schemas map[string]ProviderSchema
+	if err := json.Unmarshal([]byte(raw), &schemas); err != nil {
+		t.Fatalf("ProvidersJSON 不是合法 JSON: %v", err)
+	}
+	if _, ok := schemas[ProviderMock]; ok {
+		t.Error("隐藏的提供商不应出现在界面 JSON 中")
+	}
+	if !strings.HasPrefix(raw, `{"`+ProviderAliDNS+`":`) {
+		t.Errorf("界面 JSON 应按展示顺序输出，首项应为 %s: %s", ProviderAliDNS, raw[:40])
+	}
+	if !schemas[ProviderCallback].RequireKey || schemas[ProviderCallback].RequireSecret {
+		t.Errorf("Callback 的必填项不正确: %+v", schemas[ProviderCallback])
+	}
+}
+
+func TestValidateGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   config.DNSGroup
+		wantErr string
+	}{
+		{"完整配置", config.DNSGroup{Service: ProviderAliDNS, Domain: "example.com", AccessKey: "id", AccessSecret: "secret"}, ""},
+		{"未知提供商", config.DNSGroup{Service: "unknown", Domain: "example.com", AccessKey: "id"}, "不支持的 DNS 提供商"},
+		{"缺少域名", config.DNSGroup{Service: ProviderCloudflare, AccessKey: "token"}, "域名不能为空"},
+		{"缺少 AccessKey", config.DNSGroup{Service: ProviderCloudflare, Domain: "example.com"}, "API Token 不能为空"},
+		{"缺少 AccessSecret", config.DNSGroup{Service: ProviderAliDNS, Domain: "example.com", AccessKey: "id"}, "AccessKey Secret 不能为空"},
+		{"可选 AccessSecret", config.DNSGroup{Service: ProviderRFC2136, Domain: "example.com", AccessKey: "127.0.0.1"}, ""},
+		{"隐藏的提供商仍可使用", config.DNSGroup{Service: ProviderMock, Domain: "example.com"}, ""},
+		{"自定义 API 地址", config.DNSGroup{Service: ProviderAliDNS, Domain: "example.com", AccessKey: "id", AccessSecret: "secret", Endpoint: "https://alidns.ap-southeast-1.aliyuncs.com"}, ""},
+		{"API 地址格式错误", config.DNSGroup{Service: ProviderAliDNS, Domain: "example.com", AccessKey: "id", AccessSecret: "secret", Endpoint: "alidns.aliyuncs.com"}, "API 地址格式不正确"},
+		{"不支持自定义 API 地址", config.DNSGroup{Service: ProviderRFC2136, Domain: "example.com", AccessKey: "127.0.0.1", Endpoint: "https://example.com"}, "不支持自定义 API 地址"},
+		{"提取正则无效", config.DNSGroup{Service: ProviderMock, Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeA, IPType: helper.DynamicIPv4URL, Value: "https://ip.example.com", ExtractRegex: "ip=("}}}, "提取正则无效"},
+		{"quorum 大于接口数量", config.DNSGroup{Service: ProviderMock, Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeA, IPType: helper.DynamicIPv4URL, Value: "https://ip.example.com,quorum=3"}}}, "大于接口数量"},
+		{"IPv6 选择策略无效", config.DNSGroup{Service: ProviderMock, Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeAAAA, IPType: helper.DynamicIPv6Interface, Value: "eth0", IPv6Policy: "no_link_local"}}}, "未知的 IPv6 选择策略"},
+		{"主机后缀无效", config.DNSGroup{Service: ProviderMock, Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeAAAA, IPType: helper.DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "nas"}}}, "主机后缀不是有效的 IPv6 地址"},
+		{"匹配序号为负数", config.DNSGroup{Service: ProviderMock, Domain: "example.com", Records: []config.DNSRecord{{Type: RecordTypeA, IPType: helper.DynamicIPv4Command, Value: "ip addr", MatchIndex: -1}}}, "匹配序号不能为负数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGroup(&tt.group)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("意外错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("期望错误包含 %q, 实际: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
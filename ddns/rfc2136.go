@@ -0,0 +1,433 @@
+package ddns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/helper/dnsmsg"
+)
+
+func init() {
+	Register(ProviderRFC2136, func() DNS { return &RFC2136{} }, ProviderSchema{
+		Name:        "RFC2136",
+		IDLabel:     "DNS 服务器：",
+		SecretLabel: "TSIG 密钥（可选）：",
+		IDHelpHTML:  "<tip>向权威 DNS 服务器（BIND、Knot、PowerDNS 等）发送标准动态更新。服务器格式 host[:port]，默认端口 53；TSIG 密钥格式 [算法:]名称:Base64密钥，算法支持 hmac-sha256（默认）/ hmac-sha512</tip>",
+		RequireKey:  true,
+		Order:       100,
+	})
+}
+
+// rfc2136Timeout 单次 DNS 请求超时时间
+const rfc2136Timeout = 10 * time.Second
+
+// rfc2136DefaultTTL 未配置 TTL 时使用的默认值
+const rfc2136DefaultTTL = 600
+
+// RFC2136 标准 DNS 动态更新（RFC 2136）提供商。
+// 直接向权威 DNS 服务器（BIND、Knot、PowerDNS、Windows DNS 等）发送 UPDATE 报文，
+// 可选使用 TSIG（RFC 8945）签名。
+//
+// 字段映射：
+//   - AccessKey    -> DNS 服务器地址 host[:port]（必填，默认端口 53）
+//   - AccessSecret -> TSIG 密钥 [算法:]名称:Base64密钥（可选，算法支持 hmac-sha256 / hmac-sha512）
+type RFC2136 struct {
+	BaseDNSProvider
+	server string
+	key    *dnsmsg.TSIGKey
+	zone   string
+}
+
+// Init 初始化（只需要 Domain 和服务器地址，TSIG 密钥可选）
+func (r *RFC2136) Init(group *config.DNSGroup, caches []*Cache) {
+	r.Group = group
+	r.Caches = caches
+	r.server = ""
+	r.key = nil
+	r.zone = ""
+
+	if group == nil || group.Domain == "" || strings.TrimSpace(group.AccessKey) == "" {
+		helper.Error(helper.LogTypeDDNS, "[%s] 初始化失败: 配置不完整（需要域名和 DNS 服务器地址）", r.GetServiceName())
+		return
+	}
+	r.server = dnsmsg.WithDefaultPort(group.AccessKey)
+
+	if secret := strings.TrimSpace(group.AccessSecret); secret != "" {
+		key, err := dnsmsg.ParseTSIGKey(secret)
+		if err != nil {
+			helper.Error(helper.LogTypeDDNS, "[%s] 初始化失败: %v", r.GetServiceName(), err)
+			r.server = ""
+			return
+		}
+		r.key = key
+	}
+
+	if len(caches) > 0 && !caches[0].HasRun {
+		helper.Info(helper.LogTypeDDNS, "[%s] 初始化成功，共 %d 条记录", r.GetServiceName(), len(caches))
+	}
+}
+
+// UpdateOrCreateRecords 批量更新或创建 DNS 记录
+func (r *RFC2136) UpdateOrCreateRecords() []RecordResult {
+	validRecords := filterValidRecords(r.Group, r.Caches)
+	if len(validRecords) == 0 {
+		return []RecordResult{}
+	}
+
+	if r.server == "" {
+		return createErrorResults(validRecords, InitFailed, "配置不完整（需要 DNS 服务器地址，TSIG 密钥格式为 [算法:]名称:密钥）")
+	}
+
+	if r.zone == "" {
+		zone, err := r.findZone()
+		if err != nil {
+			helper.Error(helper.LogTypeDDNS, "[%s] 查询权威区域失败: %v", r.GetServiceName(), err)
+			return createErrorResults(validRecords, UpdatedFailed, err.Error())
+		}
+		r.zone = zone
+		helper.Debug(helper.LogTypeDDNS, "[%s] 权威区域: %s", r.GetServiceName(), zone)
+	}
+
+	results := make([]RecordResult, 0, len(validRecords))
+	for _, vr := range validRecords {
+		results = append(results, r.processRecord(vr.record, vr.cache))
+	}
+	return results
+}
+
+// processRecord 处理单条 DNS 记录
+func (r *RFC2136) processRecord(record *config.DNSRecord, cache *Cache) RecordResult {
+	// 1. 获取当前值
+	currentValue, result, ok := getCurrentValue(r.GetServiceName(), record, cache)
+	if !ok {
+		return result
+	}
+
+	// 2. 检查缓存
+	if skip, res := checkDynamicCache(r.GetServiceName(), record, cache, currentValue, &result); skip {
+		return res
+	}
+
+	// 3. 查询现有记录，值一致时不发送 UPDATE
+	updateErr := func() error {
+		existing, err := r.lookup(record.Type)
+		if err != nil {
+			return err
+		}
+		if r.matches(existing, record.Type, currentValue) {
+			helper.Debug(helper.LogTypeDDNS, "[%s] [%s] 记录值未变化，无需更新 [值=%s]", r.GetServiceName(), record.Type, currentValue)
+			return nil
+		}
+		helper.Info(helper.LogTypeDDNS, "[%s] [%s] 设置 DNS 记录 [值=%s]", r.GetServiceName(), record.Type, currentValue)
+		return r.update(record.Type, currentValue)
+	}()
+
+	if updateErr != nil {
+		result.Status = UpdatedFailed
+		result.ErrorMessage = updateErr.Error()
+		result.ShouldWebhook = shouldSendWebhook(cache, UpdatedFailed)
+		helper.Error(helper.LogTypeDDNS, "[%s] [%s] 设置 DNS 记录失败 [值=%s, 错误=%v]", r.GetServiceName(), record.Type, currentValue, updateErr)
+		return result
+	}
+
+	// 4. 更新缓存
+	finalizeSuccess(r.GetServiceName(), record, cache, currentValue, &result)
+	return result
+}
+
+// RemoveRecords 以 DNS UPDATE 删除同名下指定类型的记录集
+func (r *RFC2136) RemoveRecords(recordTypes []string) []RecordResult {
+	if r.server == "" {
+		return createRemoveErrorResults(recordTypes, "配置不完整（需要 DNS 服务器地址，TSIG 密钥格式为 [算法:]名称:密钥）")
+	}
+	if r.zone == "" {
+		zone, err := r.findZone()
+		if err != nil {
+			helper.Error(helper.LogTypeDDNS, "[%s] 查询权威区域失败: %v", r.GetServiceName(), err)
+			return createRemoveErrorResults(recordTypes, err.Error())
+		}
+		r.zone = zone
+	}
+
+	results := make([]RecordResult, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		result := RecordResult{RecordType: recordType, Status: UpdatedNothing}
+		oldValue, err := r.remove(recordType)
+		switch {
+		case err != nil:
+			result.Status = UpdatedFailed
+			result.ErrorMessage = err.Error()
+			helper.Error(helper.LogTypeDDNS, "[%s] [%s] 删除 DNS 记录失败 [错误=%v]", r.GetServiceName(), recordType, err)
+		case oldValue != "":
+			result.Status = RemovedSuccess
+			result.OldValue = oldValue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// remove 查询并删除同名同类型的记录集，返回删除前的值；记录不存在时返回空
+func (r *RFC2136) remove(recordType string) (string, error) {
+	rrType, err := rfc2136Type(recordType)
+	if err != nil {
+		return "", err
+	}
+	existing, err := r.lookup(recordType)
+	if err != nil {
+		return "", err
+	}
+	var values []string
+	for _, rr := range existing {
+		if rr.Type == rrType {
+			values = append(values, rfc2136Value(rr))
+		}
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	msg := dnsmsg.NewUpdate(r.zone)
+	msg.Authority = append(msg.Authority, dnsmsg.RR{Name: r.fqdn(), Type: rrType, Class: dnsmsg.ClassANY})
+	resp, err := r.exchange(msg)
+	if err != nil {
+		return "", err
+	}
+	if resp.Rcode != dnsmsg.RcodeSuccess {
+		return "", fmt.Errorf("服务器拒绝删除: %s", dnsmsg.RcodeName(resp.Rcode))
+	}
+	helper.Info(helper.LogTypeDDNS, "[%s] [%s] 删除 DNS 记录成功 [值=%v]", r.GetServiceName(), recordType, values)
+	return strings.Join(values, ","), nil
+}
+
+// findZone 通过 SOA 查询确定域名所在的权威区域
+func (r *RFC2136) findZone() (string, error) {
+	resp, err := r.exchange(dnsmsg.NewQuery(r.fqdn(), dnsmsg.TypeSOA, dnsmsg.ClassINET))
+	if err != nil {
+		return "", err
+	}
+	if resp.Rcode != dnsmsg.RcodeSuccess && resp.Rcode != dnsmsg.RcodeNameError {
+		return "", fmt.Errorf("SOA 查询失败: %s", dnsmsg.RcodeName(resp.Rcode))
+	}
+	// 域名本身是区域顶点时 SOA 位于应答段，否则位于授权段
+	for _, section := range [][]dnsmsg.RR{resp.Answer, resp.Authority} {
+		for _, rr := range section {
+			if rr.Type == dnsmsg.TypeSOA {
+				return strings.ToLower(rr.Name), nil
+			}
+		}
+	}
+	return "", errors.New("服务器未返回 SOA 记录，请确认其为该域名的权威服务器")
+}
+
+// lookup 查询同名下指定类型的现有记录（同名存在 CNAME 时服务器会一并返回）
+func (r *RFC2136) lookup(recordType string) ([]dnsmsg.RR, error) {
+	rrType, err := rfc2136Type(recordType)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.exchange(dnsmsg.NewQuery(r.fqdn(), rrType, dnsmsg.ClassINET))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Rcode != dnsmsg.RcodeSuccess && resp.Rcode != dnsmsg.RcodeNameError {
+		return nil, fmt.Errorf("查询 DNS 记录失败: %s", dnsmsg.RcodeName(resp.Rcode))
+	}
+
+	existing := make([]dnsmsg.RR, 0, len(resp.Answer))
+	for _, rr := range resp.Answer {
+		if strings.EqualFold(rr.Name, r.fqdn()) {
+			existing = append(existing, rr)
+		}
+	}
+	return existing, nil
+}
+
+// matches 判断现有记录是否恰好为一条值相同的目标记录
+func (r *RFC2136) matches(existing []dnsmsg.RR, recordType, value string) bool {
+	if len(existing) != 1 {
+		return false
+	}
+	rr := existing[0]
+	switch recordType {
+	case RecordTypeA, RecordTypeAAAA:
+		ip := net.ParseIP(value)
+		return ip != nil && rr.IP() != nil && rr.IP().Equal(ip) && (rr.Type == dnsmsg.TypeAAAA) == (recordType == RecordTypeAAAA)
+	case RecordTypeCNAME:
+		return rr.Type == dnsmsg.TypeCNAME && strings.EqualFold(rr.Target(), dnsmsg.Fqdn(value))
+	case RecordTypeTXT:
+		return rr.Type == dnsmsg.TypeTXT && rr.Text() == value
+	}
+	return false
+}
+
+// update 发送 UPDATE 报文，以「先删后加」的方式替换同名同类型的记录集
+func (r *RFC2136) update(recordType, value string) error {
+	rrType, err := rfc2136Type(recordType)
+	if err != nil {
+		return err
+	}
+	rdata, err := rfc2136RData(recordType, value)
+	if err != nil {
+		return err
+	}
+
+	name := r.fqdn()
+	msg := dnsmsg.NewUpdate(r.zone)
+	if recordType == RecordTypeCNAME {
+		// CNAME 与任何其他类型互斥，删除同名下的所有记录集
+		msg.Authority = append(msg.Authority, dnsmsg.RR{Name: name, Type: dnsmsg.TypeANY, Class: dnsmsg.ClassANY})
+	} else {
+		// A/AAAA/TXT 与 CNAME 互斥，先删除同名 CNAME，再删除同类型旧记录
+		msg.Authority = append(msg.Authority,
+			dnsmsg.RR{Name: name, Type: dnsmsg.TypeCNAME, Class: dnsmsg.ClassANY},
+			dnsmsg.RR{Name: name, Type: rrType, Class: dnsmsg.ClassANY},
+		)
+	}
+	msg.Authority = append(msg.Authority, dnsmsg.RR{
+		Name:  name,
+		Type:  rrType,
+		Class: dnsmsg.ClassINET,
+		TTL:   r.parseTTL(),
+		Data:  rdata,
+	})
+
+	resp, err := r.exchange(msg)
+	if err != nil {
+		return err
+	}
+	if resp.Rcode != dnsmsg.RcodeSuccess {
+		return fmt.Errorf("服务器拒绝更新: %s", dnsmsg.RcodeName(resp.Rcode))
+	}
+	helper.Info(helper.LogTypeDDNS, "[%s] [%s] 设置 DNS 记录成功 [值=%s]", r.GetServiceName(), recordType, value)
+	return nil
+}
+
+// exchange 统一请求方法，配置了 TSIG 密钥时对请求签名并校验响应签名
+func (r *RFC2136) exchange(msg *dnsmsg.Message) (*dnsmsg.Message, error) {
+	if msg.Opcode == dnsmsg.OpcodeQuery {
+		// 直接询问权威服务器，不需要递归
+		msg.RecursionDesired = false
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("构造 DNS 报文失败: %v", err)
+	}
+
+	var requestMAC []byte
+	if r.key != nil {
+		if packed, requestMAC, err = r.key.Sign(packed, nil, time.Now()); err != nil {
+			return nil, fmt.Errorf("TSIG 签名失败: %v", err)
+		}
+	}
+
+	raw, err := dnsmsg.Exchange("udp", r.server, packed, rfc2136Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	resp, err := dnsmsg.Unpack(raw)
+	if err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	helper.Debug(helper.LogTypeDDNS, "[%s] DNS 响应 [响应码=%s, 长度=%d]", r.GetServiceName(), dnsmsg.RcodeName(resp.Rcode), len(raw))
+
+	if r.key != nil {
+		if _, err := r.key.Verify(raw, requestMAC, time.Now()); err != nil {
+			// 服务器拒绝请求时通常不签名，优先报告响应码
+			if resp.Rcode != dnsmsg.RcodeSuccess {
+				return nil, fmt.Errorf("服务器拒绝请求: %s (%v)", dnsmsg.RcodeName(resp.Rcode), err)
+			}
+			return nil, fmt.Errorf("响应签名校验失败: %v", err)
+		}
+	}
+	return resp, nil
+}
+
+// fqdn 返回以点结尾的完整域名
+func (r *RFC2136) fqdn() string {
+	return dnsmsg.Fqdn(strings.ToLower(r.Group.Domain))
+}
+
+// parseTTL 解析 TTL 值（默认 600 秒）
+func (r *RFC2136) parseTTL() uint32 {
+	ttlStr := strings.ToLower(strings.TrimSpace(r.Group.TTL))
+	if ttlStr == "" || ttlStr == "auto" {
+		return rfc2136DefaultTTL
+	}
+
+	var seconds int
+	switch {
+	case strings.HasSuffix(ttlStr, "h"):
+		if v, err := strconv.Atoi(ttlStr[:len(ttlStr)-1]); err == nil {
+			seconds = v * 3600
+		}
+	case strings.HasSuffix(ttlStr, "m"):
+		if v, err := strconv.Atoi(ttlStr[:len(ttlStr)-1]); err == nil {
+			seconds = v * 60
+		}
+	case strings.HasSuffix(ttlStr, "s"):
+		if v, err := strconv.Atoi(ttlStr[:len(ttlStr)-1]); err == nil {
+			seconds = v
+		}
+	default:
+		if v, err := strconv.Atoi(ttlStr); err == nil {
+			seconds = v
+		}
+	}
+
+	if seconds <= 0 {
+		return rfc2136DefaultTTL
+	}
+	return uint32(seconds)
+}
+
+// rfc2136Type 将记录类型映射为 DNS 类型码
+func rfc2136Type(recordType string) (uint16, error) {
+	switch recordType {
+	case RecordTypeA:
+		return dnsmsg.TypeA, nil
+	case RecordTypeAAAA:
+		return dnsmsg.TypeAAAA, nil
+	case RecordTypeCNAME:
+		return dnsmsg.TypeCNAME, nil
+	case RecordTypeTXT:
+		return dnsmsg.TypeTXT, nil
+	}
+	return 0, fmt.Errorf("不支持的记录类型: %s", recordType)
+}
+
+// rfc2136Value 记录的值，用于日志与同步历史
+func rfc2136Value(rr dnsmsg.RR) string {
+	switch rr.Type {
+	case dnsmsg.TypeA, dnsmsg.TypeAAAA:
+		if ip := rr.IP(); ip != nil {
+			return ip.String()
+		}
+	case dnsmsg.TypeCNAME:
+		return strings.TrimSuffix(rr.Target(), ".")
+	case dnsmsg.TypeTXT:
+		return rr.Text()
+	}
+	return ""
+}
+
+// rfc2136RData 构造记录值对应的 RDATA
+func rfc2136RData(recordType, value string) ([]byte, error) {
+	switch recordType {
+	case RecordTypeA:
+		return dnsmsg.AData(net.ParseIP(value))
+	case RecordTypeAAAA:
+		return dnsmsg.AAAAData(net.ParseIP(value))
+	case RecordTypeCNAME:
+		return dnsmsg.NameData(dnsmsg.Fqdn(value))
+	case RecordTypeTXT:
+		return dnsmsg.TXTData(value), nil
+	}
+	return nil, fmt.Errorf("不支持的记录类型: %s", recordType)
+}
@@ -0,0 +1,264 @@
+package ddns
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper/dnsmsg"
+)
+
+const rfc2136TestKey = "hmac-sha256:ddns-key:c2VjcmV0LWtleS1mb3ItdGVzdA=="
+
+// fakeAuthServer 本地 UDP 权威 DNS 服务器，只服务 example.com. 区域并校验 TSIG
+type fakeAuthServer struct {
+	conn    net.PacketConn
+	key     *dnsmsg.TSIGKey
+	mu      sync.Mutex
+	records map[string][]dnsmsg.RR // key=小写名称
+	updates int
+}
+
+func newFakeAuthServer(t *testing.T, keyStr string) *fakeAuthServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听 UDP: %v", err)
+	}
+	s := &fakeAuthServer{conn: conn, records: make(map[string][]dnsmsg.RR)}
+	if keyStr != "" {
+		s.key, _ = dnsmsg.ParseTSIGKey(keyStr)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeAuthServer) addr() string { return s.conn.LocalAddr().String() }
+
+func (s *fakeAuthServer) serve() {
+	buf := make([]byte, 4096)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.handle(append([]byte(nil), buf[:n]...)); resp != nil {
+			s.conn.WriteTo(resp, from)
+		}
+	}
+}
+
+func (s *fakeAuthServer) handle(raw []byte) []byte {
+	req, err := dnsmsg.Unpack(raw)
+	if err != nil {
+		return nil
+	}
+	resp := &dnsmsg.Message{ID: req.ID, Response: true, Opcode: req.Opcode, Authoritative: true, Question: req.Question}
+
+	var reqMAC []byte
+	if s.key != nil {
+		if reqMAC, err = s.key.Verify(raw, nil, time.Now()); err != nil {
+			resp.Rcode = dnsmsg.RcodeNotAuth
+			b, _ := resp.Pack()
+			return b
+		}
+	}
+
+	s.mu.Lock()
+	switch req.Opcode {
+	case dnsmsg.OpcodeQuery:
+		q := req.Question[0]
+		name := strings.ToLower(q.Name)
+		if q.Type == dnsmsg.TypeSOA {
+			mname, _ := dnsmsg.NameData("ns1.example.com.")
+			rname, _ := dnsmsg.NameData("hostmaster.example.com.")
+			soa := append(append(mname, rname...), make([]byte, 20)...) // 5 个计时字段
+			resp.Authority = append(resp.Authority, dnsmsg.RR{Name: "example.com.", Type: dnsmsg.TypeSOA, Class: dnsmsg.ClassINET, TTL: 3600, Data: soa})
+		} else {
+			for _, rr := range s.records[name] {
+				if rr.Type == q.Type || rr.Type == dnsmsg.TypeCNAME {
+					resp.Answer = append(resp.Answer, rr)
+				}
+			}
+		}
+	case dnsmsg.OpcodeUpdate:
+		s.updates++
+		for _, rr := range req.Authority {
+			name := strings.ToLower(rr.Name)
+			switch rr.Class {
+			case dnsmsg.ClassANY:
+				kept := s.records[name][:0]
+				for _, old := range s.records[name] {
+					if rr.Type != dnsmsg.TypeANY && old.Type != rr.Type {
+						kept = append(kept, old)
+					}
+				}
+				s.records[name] = kept
+			case dnsmsg.ClassINET:
+				s.records[name] = append(s.records[name], rr)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	b, _ := resp.Pack()
+	if s.key != nil {
+		b, _, _ = s.key.Sign(b, reqMAC, time.Now())
+	}
+	return b
+}
+
+// newRFC2136Group 构造一个 RFC2136 测试配置组（单条静态记录）
+func newRFC2136Group(server, key, recordType, value string) (*config.DNSGroup, []*Cache) {
+	group := &config.DNSGroup{
+		Domain:       "www.example.com",
+		AccessKey:    server, // DNS 服务器
+		AccessSecret: key,    // TSIG 密钥（可选）
+		TTL:          "5m",
+		Records: []config.DNSRecord{
+			{Type: recordType, IPType: "static_ipv4", Value: value},
+		},
+	}
+	c := NewCache()
+	return group, []*Cache{&c}
+}
+
+func TestRFC2136CreatesAndSkipsUnchangedRecord(t *testing.T) {
+	srv := newFakeAuthServer(t, rfc2136TestKey)
+	group, caches := newRFC2136Group(srv.addr(), rfc2136TestKey, RecordTypeA, "1.2.3.4")
+
+	r := &RFC2136{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+	if r.zone != "example.com." {
+		t.Errorf("期望权威区域 example.com., 实际 %s", r.zone)
+	}
+
+	srv.mu.Lock()
+	rrs := srv.records["www.example.com."]
+	updates := srv.updates
+	srv.mu.Unlock()
+	if updates != 1 || len(rrs) != 1 || !rrs[0].IP().Equal(net.ParseIP("1.2.3.4")) || rrs[0].TTL != 300 {
+		t.Fatalf("服务器记录不正确: updates=%d, records=%+v", updates, rrs)
+	}
+
+	// 再次同步，记录值一致时不应发送 UPDATE
+	r.Init(group, caches)
+	r.UpdateOrCreateRecords()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.updates != 1 {
+		t.Errorf("值未变化时不应再次发送 UPDATE, 实际 %d 次", srv.updates)
+	}
+}
+
+func TestRFC2136ReplacesConflictingCNAME(t *testing.T) {
+	srv := newFakeAuthServer(t, "")
+	target, _ := dnsmsg.NameData("old.example.net.")
+	// serve 已在后台运行，预置记录需持有锁
+	srv.mu.Lock()
+	srv.records["www.example.com."] = []dnsmsg.RR{
+		{Name: "www.example.com.", Type: dnsmsg.TypeCNAME, Class: dnsmsg.ClassINET, TTL: 60, Data: target},
+	}
+	srv.mu.Unlock()
+
+	group, caches := newRFC2136Group(srv.addr(), "", RecordTypeTXT, "hello")
+	r := &RFC2136{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	rrs := srv.records["www.example.com."]
+	if len(rrs) != 1 || rrs[0].Type != dnsmsg.TypeTXT || rrs[0].Text() != "hello" {
+		t.Errorf("期望 CNAME 被替换为 TXT, 实际 %+v", rrs)
+	}
+}
+
+func TestRFC2136FailsWithWrongKey(t *testing.T) {
+	srv := newFakeAuthServer(t, rfc2136TestKey)
+	group, caches := newRFC2136Group(srv.addr(), "hmac-sha256:ddns-key:d3Jvbmc=", RecordTypeA, "1.2.3.4")
+
+	r := &RFC2136{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+	if len(results) != 1 || results[0].Status != UpdatedFailed {
+		t.Fatalf("期望 1 条失败结果, 实际: %+v", results)
+	}
+	if !strings.Contains(results[0].ErrorMessage, "NOTAUTH") {
+		t.Errorf("错误信息应包含响应码, 实际: %s", results[0].ErrorMessage)
+	}
+}
+
+func TestRFC2136IncompleteConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		key    string
+	}{
+		{"缺少服务器", "", ""},
+		{"密钥格式错误", "127.0.0.1", "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, caches := newRFC2136Group(tt.server, tt.key, RecordTypeA, "1.2.3.4")
+			r := &RFC2136{}
+			r.Init(group, caches)
+			results := r.UpdateOrCreateRecords()
+			if len(results) != 1 || results[0].Status != InitFailed {
+				t.Errorf("期望 InitFailed, 实际: %+v", results)
+			}
+		})
+	}
+}
+
+func TestRFC2136ParseTTL(t *testing.T) {
+	tests := map[string]uint32{
+		"":     600,
+		"auto": 600,
+		"60":   60,
+		"10m":  600,
+		"1h":   3600,
+		"bad":  600,
+	}
+	for input, want := range tests {
+		r := &RFC2136{BaseDNSProvider: BaseDNSProvider{Group: &config.DNSGroup{TTL: input}}}
+		if got := r.parseTTL(); got != want {
+			t.Errorf("parseTTL(%q) = %d, 期望 %d", input, got, want)
+		}
+	}
+}
+
+func TestRFC2136RemoveRecords(t *testing.T) {
+	srv := newFakeAuthServer(t, rfc2136TestKey)
+	group, caches := newRFC2136Group(srv.addr(), rfc2136TestKey, RecordTypeA, "1.2.3.4")
+
+	r := &RFC2136{}
+	r.Init(group, caches)
+	if results := r.UpdateOrCreateRecords(); len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("创建记录失败: %+v", results)
+	}
+
+	r = &RFC2136{}
+	r.Init(group, nil)
+	results := r.RemoveRecords([]string{RecordTypeA, RecordTypeAAAA})
+	if len(results) != 2 || results[0].Status != RemovedSuccess || results[0].OldValue != "1.2.3.4" || results[1].Status != UpdatedNothing {
+		t.Fatalf("删除结果不正确: %+v", results)
+	}
+	srv.mu.Lock()
+	remaining, updates := len(srv.records["www.example.com."]), srv.updates
+	srv.mu.Unlock()
+	if remaining != 0 || updates != 2 {
+		t.Errorf("期望记录已删除且只为 A 发送一次删除, 实际剩余 %d 条, UPDATE %d 次", remaining, updates)
+	}
+}
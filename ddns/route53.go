@@ -0,0 +1,534 @@
+package ddns
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/signer"
+)
+
+func init() {
+	Register(ProviderRoute53, func() DNS { return &Route53{} }, ProviderSchema{
+		Name:           "Amazon Route 53",
+		IDLabel:        "Access Key ID：",
+		SecretLabel:    "Secret Access Key：",
+		IDHelpHTML:     "<a target='_blank' href='https://console.aws.amazon.com/iam/home#/security_credentials'>创建访问密钥</a>（需要 route53:ListHostedZonesByName、ListResourceRecordSets、ChangeResourceRecordSets 权限）",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          85,
+	})
+}
+
+// Route 53 为全局服务，签名固定使用 us-east-1；中国区使用 cn-northwest-1，可通过 DNSGroup.Endpoint 覆盖地址
+const (
+	route53Endpoint   = "https://route53.amazonaws.com"
+	route53APIVersion = "2013-04-01"
+	route53Namespace  = "https://route53.amazonaws.com/doc/2013-04-01/"
+	route53Region     = "us-east-1"
+	route53RegionCN   = "cn-northwest-1"
+	route53Service    = "route53"
+)
+
+type Route53 struct {
+	BaseDNSProvider
+	zoneID string // Hosted Zone ID 缓存（同组所有记录共享）
+}
+
+// Route53HostedZone 托管区域
+type Route53HostedZone struct {
+	ID          string `xml:"Id"`
+	Name        string `xml:"Name"`
+	PrivateZone bool   `xml:"Config>PrivateZone"`
+}
+
+// Route53ListHostedZonesByNameResponse ListHostedZonesByName 响应
+type Route53ListHostedZonesByNameResponse struct {
+	HostedZones []Route53HostedZone `xml:"HostedZones>HostedZone"`
+}
+
+// Route53ResourceRecordSet 记录集
+type Route53ResourceRecordSet struct {
+	Name            string                  `xml:"Name"`
+	Type            string                  `xml:"Type"`
+	TTL             int                     `xml:"TTL,omitempty"`
+	ResourceRecords []Route53ResourceRecord `xml:"ResourceRecords>ResourceRecord"`
+}
+
+// Route53ResourceRecord 记录值
+type Route53ResourceRecord struct {
+	Value string `xml:"Value"`
+}
+
+// Values 返回记录集中的全部值
+func (rs Route53ResourceRecordSet) Values() []string {
+	values := make([]string, len(rs.ResourceRecords))
+	for i, rr := range rs.ResourceRecords {
+		values[i] = rr.Value
+	}
+	return values
+}
+
+// Route53ListResourceRecordSetsResponse ListResourceRecordSets 响应
+type Route53ListResourceRecordSetsResponse struct {
+	ResourceRecordSets []Route53ResourceRecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+}
+
+// Route53Change 单个变更
+type Route53Change struct {
+	Action            string                   `xml:"Action"`
+	ResourceRecordSet Route53ResourceRecordSet `xml:"ResourceRecordSet"`
+}
+
+// Route53ChangeRequest ChangeResourceRecordSets 请求体
+type Route53ChangeRequest struct {
+	XMLName xml.Name        `xml:"ChangeResourceRecordSetsRequest"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Comment string          `xml:"ChangeBatch>Comment,omitempty"`
+	Changes []Route53Change `xml:"ChangeBatch>Changes>Change"`
+}
+
+// Route53ChangeResponse ChangeResourceRecordSets 响应
+type Route53ChangeResponse struct {
+	ID     string `xml:"ChangeInfo>Id"`
+	Status string `xml:"ChangeInfo>Status"`
+}
+
+// Route53ErrorResponse 错误响应
+type Route53ErrorResponse struct {
+	Code     string   `xml:"Error>Code"`
+	Message  string   `xml:"Error>Message"`
+	Messages []string `xml:"Messages>Message"` // InvalidChangeBatch 使用该格式
+}
+
+// route53Pending 需要推送的记录
+type route53Pending struct {
+	index  int
+	record *config.DNSRecord
+	cache  *Cache
+	value  string
+}
+
+// Init 初始化 Route 53 DDNS（需按名称解析托管区域）
+func (r *Route53) Init(group *config.DNSGroup, caches []*Cache) {
+	if !r.initConfig(group, caches) {
+		return
+	}
+
+	zoneID, err := r.getZoneID()
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 获取 Hosted Zone 失败: %v", r.GetServiceName(), err)
+		return
+	}
+	r.zoneID = zoneID
+
+	if len(r.Caches) > 0 && !r.Caches[0].HasRun {
+		helper.Info(helper.LogTypeDDNS, "[%s] 初始化成功，共 %d 条记录 [HostedZone=%s]", r.GetServiceName(), len(r.Caches), zoneID)
+	}
+}
+
+// UpdateOrCreateRecords 将同组所有待更新记录合并为一个 UPSERT 批次提交
+func (r *Route53) UpdateOrCreateRecords() []RecordResult {
+	validRecords := filterValidRecords(r.Group, r.Caches)
+	if len(validRecords) == 0 {
+		return []RecordResult{}
+	}
+
+	if r.Group.Domain == "" || r.Group.AccessKey == "" || r.Group.AccessSecret == "" {
+		return createErrorResults(validRecords, InitFailed, "配置不完整")
+	}
+	if r.zoneID == "" {
+		return createErrorResults(validRecords, InitFailed, "Hosted Zone 未初始化")
+	}
+
+	// 1. 获取当前值并检查缓存，收集需要推送的记录；
+	// Route 53 以记录集为单位覆盖，未变化或获取失败的记录也要带上其值，否则会被一并删除
+	results := make([]RecordResult, len(validRecords))
+	pending := make([]route53Pending, 0, len(validRecords))
+	all := make([]route53Pending, 0, len(validRecords))
+	for i, vr := range validRecords {
+		currentValue, result, ok := getCurrentValue(r.GetServiceName(), vr.record, vr.cache)
+		if !ok {
+			results[i] = result
+			lastValue, _ := vr.cache.GetDynamicIP(getRecordCacheKey(vr.record))
+			all = append(all, route53Pending{index: i, record: vr.record, cache: vr.cache, value: lastValue})
+			continue
+		}
+		all = append(all, route53Pending{index: i, record: vr.record, cache: vr.cache, value: currentValue})
+		if skip, skipped := checkDynamicCache(r.GetServiceName(), vr.record, vr.cache, currentValue, &result); skip {
+			results[i] = skipped
+			continue
+		}
+		results[i] = result
+		pending = append(pending, route53Pending{index: i, record: vr.record, cache: vr.cache, value: currentValue})
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	// 2. 查询现有记录，构建变更批次
+	existing, err := r.listRecordSets()
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 查询 DNS 记录失败: %v", r.GetServiceName(), err)
+		return r.failPending(results, pending, err)
+	}
+	changes, incomplete := r.buildChanges(pending, all, existing)
+
+	// 3. 提交变更（值均未变化时无需提交）
+	if len(changes) > 0 {
+		if err = r.changeRecordSets(changes); err != nil {
+			return r.failPending(results, pending, err)
+		}
+	} else {
+		helper.Debug(helper.LogTypeDDNS, "[%s] 记录值未变化，无需提交变更", r.GetServiceName())
+	}
+
+	for _, p := range pending {
+		if incomplete[p.record.Type] {
+			results[p.index].Status = UpdatedFailed
+			results[p.index].ErrorMessage = "同类型的其它记录获取 IP 失败，暂不更新该记录集"
+			results[p.index].ShouldWebhook = shouldSendWebhook(p.cache, UpdatedFailed)
+			continue
+		}
+		finalizeSuccess(r.GetServiceName(), p.record, p.cache, p.value, &results[p.index])
+	}
+	return results
+}
+
+// RemoveRecords 删除该域名下指定类型的记录集，合并为一个 DELETE 批次提交
+func (r *Route53) RemoveRecords(recordTypes []string) []RecordResult {
+	if r.Group == nil || r.Group.Domain == "" || r.Group.AccessKey == "" || r.Group.AccessSecret == "" {
+		return createRemoveErrorResults(recordTypes, "配置不完整")
+	}
+	if r.zoneID == "" {
+		return createRemoveErrorResults(recordTypes, "Hosted Zone 未初始化")
+	}
+	existing, err := r.listRecordSets()
+	if err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 查询 DNS 记录失败: %v", r.GetServiceName(), err)
+		return createRemoveErrorResults(recordTypes, err.Error())
+	}
+
+	results := make([]RecordResult, 0, len(recordTypes))
+	var changes []Route53Change
+	for _, recordType := range recordTypes {
+		result := RecordResult{RecordType: recordType, Status: UpdatedNothing}
+		for _, rs := range existing {
+			if rs.Type == recordType {
+				changes = append(changes, Route53Change{Action: "DELETE", ResourceRecordSet: rs})
+				result.Status = RemovedSuccess
+				result.OldValue = strings.Join(rs.Values(), ",")
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	if len(changes) == 0 {
+		return results
+	}
+
+	if err := r.changeRecordSets(changes); err != nil {
+		for i := range results {
+			if results[i].Status == RemovedSuccess {
+				results[i].Status = UpdatedFailed
+				results[i].ErrorMessage = err.Error()
+			}
+		}
+	}
+	return results
+}
+
+// failPending 批次失败时，所有待推送记录均标记为失败
+func (r *Route53) failPending(results []RecordResult, pending []route53Pending, err error) []RecordResult {
+	for _, p := range pending {
+		results[p.index].Status = UpdatedFailed
+		results[p.index].ErrorMessage = err.Error()
+		results[p.index].ShouldWebhook = shouldSendWebhook(p.cache, UpdatedFailed)
+	}
+	return results
+}
+
+// buildChanges 按记录类型合并为 UPSERT 变更；与 CNAME 冲突的现有记录在同一批次中删除。
+// 每个记录集由该类型的全部记录组成（含本轮未变化的记录），只提交包含待推送记录且与远端不同的记录集；
+// 同类型有记录既获取失败又没有上次的值时，无法得到完整的记录集，该类型本轮不提交，通过 incomplete 返回
+func (r *Route53) buildChanges(pending, all []route53Pending, existing []Route53ResourceRecordSet) ([]Route53Change, map[string]bool) {
+	fqdn := route53FQDN(r.Group.Domain)
+	ttl := r.parseTTL()
+
+	// 同类型多条记录合并为一个记录集（去重，保持配置顺序）
+	types := make([]string, 0, len(pending))
+	values := make(map[string][]string)
+	incomplete := make(map[string]bool)
+	for _, p := range pending {
+		if _, ok := values[p.record.Type]; !ok {
+			types = append(types, p.record.Type)
+			values[p.record.Type] = nil
+		}
+	}
+	for _, p := range all {
+		if _, ok := values[p.record.Type]; !ok {
+			continue
+		}
+		if p.value == "" {
+			incomplete[p.record.Type] = true
+			continue
+		}
+		value := route53RecordValue(p.record.Type, p.value)
+		if !containsString(values[p.record.Type], value) {
+			values[p.record.Type] = append(values[p.record.Type], value)
+		}
+	}
+	for recordType := range incomplete {
+		helper.Warn(helper.LogTypeDDNS, "[%s] [%s] 同类型有记录获取 IP 失败且无上次的值，暂不更新该记录集", r.GetServiceName(), recordType)
+		delete(values, recordType)
+	}
+	if len(values) == 0 {
+		return nil, incomplete
+	}
+
+	current := make(map[string]Route53ResourceRecordSet)
+	for _, rs := range existing {
+		current[rs.Type] = rs
+	}
+
+	var changes []Route53Change
+	_, wantCNAME := values[RecordTypeCNAME]
+	for _, rs := range existing {
+		if _, wanted := values[rs.Type]; wanted {
+			continue
+		}
+		conflict := (wantCNAME && rs.Type != RecordTypeCNAME) || (!wantCNAME && rs.Type == RecordTypeCNAME)
+		if conflict && rs.Type != "NS" && rs.Type != "SOA" {
+			helper.Info(helper.LogTypeDDNS, "[%s] [%s] 与 CNAME 冲突，将在同一批次中删除 [值=%v]", r.GetServiceName(), rs.Type, rs.Values())
+			changes = append(changes, Route53Change{Action: "DELETE", ResourceRecordSet: rs})
+		}
+	}
+
+	for _, recordType := range types {
+		if incomplete[recordType] {
+			continue
+		}
+		if rs, ok := current[recordType]; ok && rs.TTL == ttl && sameStrings(rs.Values(), values[recordType]) {
+			helper.Debug(helper.LogTypeDDNS, "[%s] [%s] 记录值未变化，无需更新 [值=%v]", r.GetServiceName(), recordType, rs.Values())
+			continue
+		}
+		records := make([]Route53ResourceRecord, len(values[recordType]))
+		for i, v := range values[recordType] {
+			records[i] = Route53ResourceRecord{Value: v}
+		}
+		changes = append(changes, Route53Change{
+			Action: "UPSERT",
+			ResourceRecordSet: Route53ResourceRecordSet{
+				Name:            fqdn,
+				Type:            recordType,
+				TTL:             ttl,
+				ResourceRecords: records,
+			},
+		})
+	}
+	return changes, incomplete
+}
+
+// getZoneID 按名称解析托管区域：从完整域名逐级向上查找，优先公有区域
+func (r *Route53) getZoneID() (string, error) {
+	labels := strings.Split(strings.TrimSuffix(strings.TrimPrefix(r.Group.Domain, "*."), "."), ".")
+	for i := 0; i < len(labels)-1; i++ {
+		name := strings.Join(labels[i:], ".") + "."
+
+		helper.Debug(helper.LogTypeDDNS, "[%s] 正在查找 Hosted Zone [名称=%s]", r.GetServiceName(), name)
+
+		var resp Route53ListHostedZonesByNameResponse
+		query := url.Values{"dnsname": {name}, "maxitems": {"10"}}
+		if err := r.request(http.MethodGet, "/hostedzonesbyname", query, nil, &resp); err != nil {
+			return "", err
+		}
+		var private string
+		for _, zone := range resp.HostedZones {
+			if !strings.EqualFold(zone.Name, name) {
+				continue
+			}
+			id := strings.TrimPrefix(zone.ID, "/hostedzone/")
+			if !zone.PrivateZone {
+				return id, nil
+			}
+			if private == "" {
+				private = id
+			}
+		}
+		if private != "" {
+			return private, nil
+		}
+	}
+	return "", fmt.Errorf("未找到域名 %s 对应的 Hosted Zone", r.Group.Domain)
+}
+
+// listRecordSets 查询该域名下的现有记录集
+func (r *Route53) listRecordSets() ([]Route53ResourceRecordSet, error) {
+	fqdn := route53FQDN(r.Group.Domain)
+	query := url.Values{"name": {fqdn}, "maxitems": {"20"}}
+
+	var resp Route53ListResourceRecordSetsResponse
+	if err := r.request(http.MethodGet, "/hostedzone/"+r.zoneID+"/rrset", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	// 返回结果从该名称开始按字典序排列，只保留同名记录
+	records := make([]Route53ResourceRecordSet, 0, len(resp.ResourceRecordSets))
+	for _, rs := range resp.ResourceRecordSets {
+		if route53NameEqual(rs.Name, fqdn) {
+			records = append(records, rs)
+		}
+	}
+	return records, nil
+}
+
+// changeRecordSets 提交变更批次
+func (r *Route53) changeRecordSets(changes []Route53Change) error {
+	body := Route53ChangeRequest{
+		Xmlns:   route53Namespace,
+		Comment: "D-NET DDNS",
+		Changes: changes,
+	}
+
+	var resp Route53ChangeResponse
+	if err := r.request(http.MethodPost, "/hostedzone/"+r.zoneID+"/rrset/", nil, body, &resp); err != nil {
+		helper.Error(helper.LogTypeDDNS, "[%s] 提交变更失败 [数量=%d, 错误=%v]", r.GetServiceName(), len(changes), err)
+		return err
+	}
+
+	helper.Info(helper.LogTypeDDNS, "[%s] 提交变更成功 [数量=%d, ChangeID=%s, 状态=%s]", r.GetServiceName(), len(changes), resp.ID, resp.Status)
+	return nil
+}
+
+// request 统一请求方法（XML + SigV4 签名）
+func (r *Route53) request(method, path string, query url.Values, body interface{}, result interface{}) error {
+	endpoint := r.Group.GetEndpoint(route53Endpoint)
+	reqURL := endpoint + "/" + route53APIVersion + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("序列化请求数据失败: %v", err)
+		}
+		payload = append([]byte(xml.Header), data...)
+	}
+
+	req, err := http.NewRequest(method, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/xml")
+	}
+
+	region := route53Region
+	if strings.Contains(req.URL.Host, ".amazonaws.com.cn") {
+		region = route53RegionCN
+	}
+	signer.NewAWSSigV4Signer(r.Group.AccessKey, r.Group.AccessSecret, region, route53Service).Sign(req, payload)
+
+	client := helper.CreateHTTPClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	helper.Debug(helper.LogTypeDDNS, "[%s] API 响应 [状态码=%d, 长度=%d]", r.GetServiceName(), resp.StatusCode, len(responseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp Route53ErrorResponse
+		if xml.Unmarshal(responseBody, &errResp) == nil && (errResp.Code != "" || len(errResp.Messages) > 0) {
+			msg := errResp.Message
+			if len(errResp.Messages) > 0 {
+				msg = strings.Join(errResp.Messages, "; ")
+			}
+			if errResp.Code == "" {
+				errResp.Code = "InvalidChangeBatch"
+			}
+			return fmt.Errorf("%s: %s", errResp.Code, msg)
+		}
+		helper.Warn(helper.LogTypeDDNS, "[%s] API 响应状态码异常 [状态码=%d, 响应=%s]", r.GetServiceName(), resp.StatusCode, string(responseBody))
+		return fmt.Errorf("请求失败 [状态码=%d]: %s", resp.StatusCode, string(responseBody))
+	}
+
+	if result != nil && len(responseBody) > 0 {
+		if err := xml.Unmarshal(responseBody, result); err != nil {
+			helper.Error(helper.LogTypeDDNS, "[%s] 解析响应失败: %v", r.GetServiceName(), err)
+			return fmt.Errorf("解析响应失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// parseTTL 解析 TTL 值（默认 300 秒）
+func (r *Route53) parseTTL() int {
+	if r.Group.TTL == "" || r.Group.TTL == "AUTO" {
+		return 300
+	}
+	if ttl, err := strconv.Atoi(r.Group.TTL); err == nil && ttl >= 0 {
+		return ttl
+	}
+	return 300
+}
+
+// route53FQDN 返回以点结尾的完整域名
+func route53FQDN(domain string) string {
+	if strings.HasSuffix(domain, ".") {
+		return domain
+	}
+	return domain + "."
+}
+
+// route53NameEqual 比较记录名称；Route 53 返回的通配符为 \052
+func route53NameEqual(name, fqdn string) bool {
+	name = strings.ReplaceAll(name, `\052`, "*")
+	return strings.EqualFold(name, fqdn)
+}
+
+// route53RecordValue TXT 记录值需要加引号
+func route53RecordValue(recordType, value string) string {
+	if recordType != RecordTypeTXT || strings.HasPrefix(value, `"`) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStrings 判断两个列表元素是否相同（忽略顺序）
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !containsString(b, v) {
+			return false
+		}
+	}
+	return true
+}
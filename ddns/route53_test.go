@@ -0,0 +1,293 @@
+package ddns
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// newRoute53Group 构造一个 Route 53 测试配置组
+func newRoute53Group(endpoint string, records ...config.DNSRecord) (*config.DNSGroup, []*Cache) {
+	group := &config.DNSGroup{
+		Domain:       "www.example.com",
+		AccessKey:    "AKIDEXAMPLE",
+		AccessSecret: "test-secret",
+		TTL:          "600",
+		Endpoint:     endpoint,
+		Records:      records,
+	}
+	caches := make([]*Cache, len(records))
+	for i := range records {
+		c := NewCache()
+		caches[i] = &c
+	}
+	return group, caches
+}
+
+const route53ZonesXML = `<?xml version="1.0"?>
+<ListHostedZonesByNameResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <HostedZones>
+    <HostedZone><Id>/hostedzone/ZPRIVATE</Id><Name>example.com.</Name><Config><PrivateZone>true</PrivateZone></Config></HostedZone>
+    <HostedZone><Id>/hostedzone/ZPUBLIC</Id><Name>example.com.</Name><Config><PrivateZone>false</PrivateZone></Config></HostedZone>
+    <HostedZone><Id>/hostedzone/ZOTHER</Id><Name>example.net.</Name></HostedZone>
+  </HostedZones>
+</ListHostedZonesByNameResponse>`
+
+// route53Server 模拟 Route 53 API：www.example.com. 无托管区域，example.com. 返回公有/私有两个区域
+func route53Server(t *testing.T, rrsets string, change http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/route53/aws4_request") {
+			t.Errorf("签名头不正确: %s", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzonesbyname":
+			if r.URL.Query().Get("dnsname") == "example.com." {
+				io.WriteString(w, route53ZonesXML)
+				return
+			}
+			io.WriteString(w, `<ListHostedZonesByNameResponse><HostedZones/></ListHostedZonesByNameResponse>`)
+		case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzone/ZPUBLIC/rrset":
+			if r.URL.Query().Get("name") != "www.example.com." {
+				t.Errorf("查询名称不正确: %s", r.URL.RawQuery)
+			}
+			io.WriteString(w, `<ListResourceRecordSetsResponse><ResourceRecordSets>`+rrsets+`</ResourceRecordSets></ListResourceRecordSetsResponse>`)
+		case r.Method == http.MethodPost && r.URL.Path == "/2013-04-01/hostedzone/ZPUBLIC/rrset/":
+			change(w, r)
+		default:
+			t.Errorf("意外请求: %s %s", r.Method, r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRoute53BatchUpsert(t *testing.T) {
+	var body string
+	calls := 0
+	srv := route53Server(t, `
+<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>9.9.9.9</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>
+<ResourceRecordSet><Name>zzz.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>8.8.8.8</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`,
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			io.WriteString(w, `<ChangeResourceRecordSetsResponse><ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+		})
+
+	group, caches := newRoute53Group(srv.URL,
+		config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"},
+		config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "5.6.7.8"},
+		config.DNSRecord{Type: RecordTypeTXT, Value: `v=spf1 -all`},
+	)
+	r := &Route53{}
+	r.Init(group, caches)
+	if r.zoneID != "ZPUBLIC" {
+		t.Fatalf("期望选择公有区域 ZPUBLIC, 实际: %q", r.zoneID)
+	}
+
+	results := r.UpdateOrCreateRecords()
+	if len(results) != 3 {
+		t.Fatalf("期望 3 条结果, 实际: %+v", results)
+	}
+	for i, res := range results {
+		if res.Status != UpdatedSuccess {
+			t.Errorf("第 %d 条记录期望成功, 实际: %+v", i, res)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("期望只提交一个变更批次, 实际: %d", calls)
+	}
+	for _, want := range []string{
+		`xmlns="https://route53.amazonaws.com/doc/2013-04-01/"`,
+		`<Action>UPSERT</Action><ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>1.2.3.4</Value></ResourceRecord><ResourceRecord><Value>5.6.7.8</Value></ResourceRecord></ResourceRecords>`,
+		`<Type>TXT</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>&#34;v=spf1 -all&#34;</Value>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("变更请求缺少 %s\n实际: %s", want, body)
+		}
+	}
+	if strings.Contains(body, "DELETE") {
+		t.Errorf("不应删除记录: %s", body)
+	}
+}
+
+func TestRoute53SkipsUnchangedRecord(t *testing.T) {
+	srv := route53Server(t,
+		`<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>1.2.3.4</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`,
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Error("记录未变化时不应提交变更")
+		})
+
+	group, caches := newRoute53Group(srv.URL, config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"})
+	r := &Route53{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+}
+
+func TestRoute53ReplacesConflictingCNAME(t *testing.T) {
+	var body string
+	srv := route53Server(t,
+		`<ResourceRecordSet><Name>www.example.com.</Name><Type>CNAME</Type><TTL>300</TTL><ResourceRecords><ResourceRecord><Value>old.example.net</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`,
+		func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			io.WriteString(w, `<ChangeResourceRecordSetsResponse><ChangeInfo><Id>/change/C2</Id><Status>PENDING</Status></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+		})
+
+	group, caches := newRoute53Group(srv.URL, config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"})
+	r := &Route53{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+
+	if len(results) != 1 || results[0].Status != UpdatedSuccess {
+		t.Fatalf("期望 1 条成功结果, 实际: %+v", results)
+	}
+	deleteAt := strings.Index(body, "<Action>DELETE</Action><ResourceRecordSet><Name>www.example.com.</Name><Type>CNAME</Type>")
+	upsertAt := strings.Index(body, "<Action>UPSERT</Action>")
+	if deleteAt < 0 || upsertAt < deleteAt {
+		t.Errorf("期望同一批次中先删除 CNAME 再写入 A 记录: %s", body)
+	}
+}
+
+func TestRoute53ChangeErrorMarksAllFailed(t *testing.T) {
+	srv := route53Server(t, "", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `<InvalidChangeBatch xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><Messages><Message>RRSet with DNS name www.example.com. is not permitted in zone example.com.</Message></Messages></InvalidChangeBatch>`)
+	})
+
+	group, caches := newRoute53Group(srv.URL,
+		config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"},
+		config.DNSRecord{Type: RecordTypeAAAA, IPType: "static_ipv6", Value: "2001:db8::1"},
+	)
+	r := &Route53{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+
+	if len(results) != 2 {
+		t.Fatalf("期望 2 条结果, 实际: %+v", results)
+	}
+	for i, res := range results {
+		if res.Status != UpdatedFailed || !strings.Contains(res.ErrorMessage, "InvalidChangeBatch: RRSet with DNS name") {
+			t.Errorf("第 %d 条记录期望失败并带错误信息, 实际: %+v", i, res)
+		}
+		if caches[i].TimesFailed != 1 {
+			t.Errorf("第 %d 条记录期望失败计数为 1, 实际: %d", i, caches[i].TimesFailed)
+		}
+	}
+}
+
+func TestRoute53ZoneNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>not authorized</Message></Error></ErrorResponse>`)
+	}))
+	defer srv.Close()
+
+	group, caches := newRoute53Group(srv.URL, config.DNSRecord{Type: RecordTypeA, IPType: "static_ipv4", Value: "1.2.3.4"})
+	r := &Route53{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+
+	if len(results) != 1 || results[0].Status != InitFailed {
+		t.Fatalf("期望初始化失败, 实际: %+v", results)
+	}
+}
+
+func TestRoute53KeepsUnchangedValuesOfSameType(t *testing.T) {
+	prevForceCompare := ForceCompareGlobal
+	defer func() {
+		ForceCompareGlobal = prevForceCompare
+		helper.ClearGlobalIPCache()
+	}()
+	const aCmd, bCmd = "dnet-route53-test-a", "dnet-route53-test-b"
+	var bodies []string
+	upsert := func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		io.WriteString(w, `<ChangeResourceRecordSetsResponse><ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+	}
+
+	// 首轮两条 A 记录都推送
+	helper.ClearGlobalIPCache()
+	helper.SetGlobalIPCache(helper.DynamicIPv4Command, aCmd, "1.1.1.1")
+	helper.SetGlobalIPCache(helper.DynamicIPv4Command, bCmd, "2.2.2.2")
+	group, caches := newRoute53Group(route53Server(t, "", upsert).URL,
+		config.DNSRecord{Type: RecordTypeA, IPType: helper.DynamicIPv4Command, Value: aCmd},
+		config.DNSRecord{Type: RecordTypeA, IPType: helper.DynamicIPv4Command, Value: bCmd},
+	)
+	r := &Route53{}
+	r.Init(group, caches)
+	r.UpdateOrCreateRecords()
+
+	// 第二轮只有第一条变化，第二条的值仍需出现在记录集中
+	ForceCompareGlobal = false
+	helper.ClearGlobalIPCache()
+	helper.SetGlobalIPCache(helper.DynamicIPv4Command, aCmd, "3.3.3.3")
+	helper.SetGlobalIPCache(helper.DynamicIPv4Command, bCmd, "2.2.2.2")
+	group.Endpoint = route53Server(t, `<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords>`+
+		`<ResourceRecord><Value>1.1.1.1</Value></ResourceRecord><ResourceRecord><Value>2.2.2.2</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`, upsert).URL
+	r = &Route53{}
+	r.Init(group, caches)
+	results := r.UpdateOrCreateRecords()
+
+	if len(results) != 2 || results[0].Status != UpdatedSuccess || results[1].Status != UpdatedNothing {
+		t.Fatalf("期望第一条成功、第二条未改变, 实际: %+v", results)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("期望每轮提交一个变更批次, 实际 %d 次", len(bodies))
+	}
+	want := `<ResourceRecords><ResourceRecord><Value>3.3.3.3</Value></ResourceRecord><ResourceRecord><Value>2.2.2.2</Value></ResourceRecord></ResourceRecords>`
+	if !strings.Contains(bodies[1], want) {
+		t.Errorf("记录集应包含未变化的记录\n期望包含: %s\n实际: %s", want, bodies[1])
+	}
+
+	// 第三轮第二条获取 IP 失败，沿用其上次的值
+	helper.ClearGlobalIPCache()
+	helper.SetGlobalIPCache(helper.DynamicIPv4Command, aCmd, "4.4.4.4")
+	r = &Route53{}
+	r.Init(group, caches)
+	results = r.UpdateOrCreateRecords()
+	if results[0].Status != UpdatedSuccess || results[1].Status != InitGetIPFailed {
+		t.Fatalf("期望第一条成功、第二条获取 IP 失败, 实际: %+v", results)
+	}
+	want = `<ResourceRecord><Value>4.4.4.4</Value></ResourceRecord><ResourceRecord><Value>2.2.2.2</Value></ResourceRecord>`
+	if len(bodies) != 3 || !strings.Contains(bodies[2], want) {
+		t.Errorf("获取失败的记录应沿用上次的值\n期望包含: %s\n实际: %v", want, bodies)
+	}
+}
+
+func TestRoute53RemoveRecords(t *testing.T) {
+	var body string
+	srv := route53Server(t, `
+<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>1.2.3.4</Value></ResourceRecord><ResourceRecord><Value>5.6.7.8</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`,
+		func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			io.WriteString(w, `<ChangeResourceRecordSetsResponse><ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+		})
+
+	group, _ := newRoute53Group(srv.URL)
+	r := &Route53{}
+	r.Init(group, nil)
+	results := r.RemoveRecords([]string{RecordTypeA, RecordTypeAAAA})
+
+	if len(results) != 2 || results[0].Status != RemovedSuccess || results[0].OldValue != "1.2.3.4,5.6.7.8" || results[1].Status != UpdatedNothing {
+		t.Fatalf("删除结果不正确: %+v", results)
+	}
+	want := `<Action>DELETE</Action><ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><TTL>600</TTL><ResourceRecords><ResourceRecord><Value>1.2.3.4</Value></ResourceRecord><ResourceRecord><Value>5.6.7.8</Value></ResourceRecord></ResourceRecords>`
+	if !strings.Contains(body, want) || strings.Count(body, "<Change>") != 1 {
+		t.Errorf("删除请求不正确: %s", body)
+	}
+}
@@ -13,9 +13,21 @@ import (
 	"github.com/cxbdasheng/dnet/signer"
 )
 
+func init() {
+	Register(ProviderTencent, func() DNS { return &TencentCloud{} }, ProviderSchema{
+		Name:           "腾讯云",
+		IDLabel:        "SecretId：",
+		SecretLabel:    "SecretKey：",
+		IDHelpHTML:     "<a target='_blank' href='https://console.dnspod.cn/account/token/apikey'>创建腾讯云 API 密钥</a>",
+		RequireKey:     true,
+		RequireSecret:  true,
+		CustomEndpoint: true,
+		Order:          30,
+	})
+}
+
 const (
-	tencentCloudDNSEndpoint = "https://dnspod.tencentcloudapi.com/"
-	tencentCloudDNSHost     = "dnspod.tencentcloudapi.com"
+	tencentCloudDNSEndpoint = "https://dnspod.tencentcloudapi.com" // 默认地址，可通过 DNSGroup.Endpoint 覆盖
 	tencentCloudDNSService  = "dnspod"
 	tencentCloudDNSVersion  = "2021-03-23"
 )
@@ -310,13 +322,13 @@ func (t *TencentCloud) deleteDomainRecord(recordId uint64) error {
 func (t *TencentCloud) request(action string, body interface{}, result interface{}) error {
 	jsonStr, _ := json.Marshal(body)
 
-	req, err := http.NewRequest(http.MethodPost, tencentCloudDNSEndpoint, bytes.NewBuffer(jsonStr))
+	req, err := http.NewRequest(http.MethodPost, t.Group.GetEndpoint(tencentCloudDNSEndpoint)+"/", bytes.NewBuffer(jsonStr))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("X-TC-Action", action)
-	signer.TencentSigner(t.Group.AccessKey, t.Group.AccessSecret, tencentCloudDNSService, tencentCloudDNSHost, string(jsonStr), req)
+	signer.TencentSigner(t.Group.AccessKey, t.Group.AccessSecret, tencentCloudDNSService, req.URL.Host, string(jsonStr), req)
 	req.Header.Set("X-TC-Version", tencentCloudDNSVersion)
 
 	client := helper.CreateHTTPClient()
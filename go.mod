@@ -4,6 +4,7 @@ go 1.23.0
 
 require (
 	github.com/kardianos/service v1.3.0
+	golang.org/x/crypto v0.40.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
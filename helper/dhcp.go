@@ -0,0 +1,328 @@
+package helper
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDHCPLeaseFiles 未指定租约文件时依次读取的位置（dnsmasq / odhcpd / ISC dhcpd），不存在的文件会被跳过
+var DefaultDHCPLeaseFiles = []string{
+	"/tmp/dhcp.leases",
+	"/var/lib/misc/dnsmasq.leases",
+	"/tmp/hosts/odhcpd",
+	"/var/lib/dhcp/dhcpd.leases",
+}
+
+// DHCPLease 租约文件中的一条有效租约
+type DHCPLease struct {
+	MAC      string    // 小写冒号分隔；DHCPv6 租约仅在 DUID 由 MAC 生成时可知
+	Hostname string    // 客户端上报的主机名，可能为空
+	Addr     net.IP    // 租出的地址
+	Expires  time.Time // 到期时间，零值表示永久
+}
+
+// IsDHCPLeaseType 判断是否为 DHCP 租约来源
+func IsDHCPLeaseType(sourceType string) bool {
+	return sourceType == DynamicIPv4DHCP || sourceType == DynamicIPv6DHCP
+}
+
+// DHCPLeaseAddrType 租约来源对应的地址类型
+func DHCPLeaseAddrType(sourceType string) string {
+	if sourceType == DynamicIPv6DHCP {
+		return IPv6
+	}
+	return IPv4
+}
+
+// ParseDHCPLeaseValue 拆分来源值 "MAC / 主机名 / 通配符[@租约文件]"
+func ParseDHCPLeaseValue(value string) (match, file string) {
+	match, file, _ = strings.Cut(strings.TrimSpace(value), "@")
+	return strings.TrimSpace(match), strings.TrimSpace(file)
+}
+
+// IsDHCPLeaseBulk 匹配部分含通配符时为批量模式，每个匹配的租约单独生成一条记录
+func IsDHCPLeaseBulk(value string) bool {
+	match, _ := ParseDHCPLeaseValue(value)
+	return strings.ContainsAny(match, "*?[")
+}
+
+// FindDHCPLeases 读取租约文件并返回与来源值匹配的指定类型租约，按可信度排序（IPv6 全局地址优先，到期晚的优先）
+func FindDHCPLeases(value, addrType string) ([]DHCPLease, error) {
+	match, file := ParseDHCPLeaseValue(value)
+	if match == "" {
+		return nil, errors.New("未指定 MAC 地址或主机名")
+	}
+	leases, err := readDHCPLeases(file, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return matchDHCPLeases(leases, match, addrType), nil
+}
+
+// GetAddrFromDHCPLease 从 DHCP 租约文件获取指定 MAC 地址或主机名的设备地址
+func GetAddrFromDHCPLease(value, addrType string) string {
+	leases, err := FindDHCPLeases(value, addrType)
+	if err != nil {
+		Warn(LogTypeNetwork, "读取 DHCP 租约失败: %v", err)
+		return ""
+	}
+	if len(leases) == 0 {
+		Warn(LogTypeNetwork, "DHCP 租约中没有与 %s 匹配的 %s 地址，请确认设备在线且租约未过期", value, getAddrTypeConfig(addrType).addrTypeName)
+		return ""
+	}
+	return leases[0].Addr.String()
+}
+
+// readDHCPLeases 读取指定租约文件；未指定时合并所有存在的默认租约文件
+func readDHCPLeases(file string, now time.Time) ([]DHCPLease, error) {
+	files := DefaultDHCPLeaseFiles
+	if file != "" {
+		files = []string{file}
+	}
+	var leases []DHCPLease
+	found := false
+	for _, name := range files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			if file == "" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		leases = append(leases, parseDHCPLeases(string(content), now)...)
+	}
+	if !found {
+		return nil, fmt.Errorf("未找到租约文件，请在来源值后用 @ 指定，如 nas@/tmp/dhcp.leases")
+	}
+	return leases, nil
+}
+
+// parseDHCPLeases 根据内容识别租约文件格式并解析，已过期的租约会被丢弃
+func parseDHCPLeases(content string, now time.Time) []DHCPLease {
+	var leases []DHCPLease
+	switch {
+	case strings.Contains(content, "lease ") && strings.Contains(content, "{"):
+		leases = parseISCLeases(content)
+	case strings.HasPrefix(strings.TrimSpace(content), "# "):
+		leases = parseOdhcpdLeases(content)
+	default:
+		leases = parseDnsmasqLeases(content)
+	}
+	valid := leases[:0]
+	for _, lease := range leases {
+		if lease.Expires.IsZero() || lease.Expires.After(now) {
+			valid = append(valid, lease)
+		}
+	}
+	return valid
+}
+
+// parseDnsmasqLeases 解析 dnsmasq 租约：<到期时间> <MAC> <IP> <主机名> <客户端 ID>；
+// DHCPv6 租约位于 "duid ..." 行之后，第二列为 IAID，最后一列为客户端 DUID
+func parseDnsmasqLeases(content string) []DHCPLease {
+	var leases []DHCPLease
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "duid" {
+			continue
+		}
+		ip := net.ParseIP(fields[2])
+		if ip == nil {
+			continue
+		}
+		lease := DHCPLease{Addr: ip, Hostname: leaseHostname(fields[3])}
+		if expires, err := strconv.ParseInt(fields[0], 10, 64); err == nil && expires > 0 {
+			lease.Expires = time.Unix(expires, 0)
+		}
+		if mac, err := net.ParseMAC(fields[1]); err == nil {
+			lease.MAC = mac.String()
+		} else if len(fields) > 4 {
+			lease.MAC = macFromDUID(strings.ReplaceAll(fields[4], ":", ""))
+		}
+		leases = append(leases, lease)
+	}
+	return leases
+}
+
+// parseOdhcpdLeases 解析 odhcpd 租约：# <接口> <DUID/MAC> <IAID> <主机名> <到期时间> <ID> <前缀长度> <地址/长度>...；
+// 到期时间为 -1 表示永久，0 表示已失效
+func parseOdhcpdLeases(content string) []DHCPLease {
+	var leases []DHCPLease
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || fields[0] != "#" {
+			continue
+		}
+		expires, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil || expires == 0 {
+			continue
+		}
+		mac := macFromDUID(fields[2])
+		if len(fields[2]) == 12 {
+			// DHCPv4 租约第二列为不带分隔符的 MAC
+			if hw, err := hex.DecodeString(fields[2]); err == nil {
+				mac = net.HardwareAddr(hw).String()
+			}
+		}
+		for _, field := range fields[8:] {
+			addr, _, _ := strings.Cut(field, "/")
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				continue
+			}
+			lease := DHCPLease{MAC: mac, Hostname: leaseHostname(fields[4]), Addr: ip}
+			if expires > 0 {
+				lease.Expires = time.Unix(expires, 0)
+			}
+			leases = append(leases, lease)
+		}
+	}
+	return leases
+}
+
+// parseISCLeases 解析 ISC dhcpd 的 lease <IP> { ... } 块，同一地址以文件中最后一个块为准
+func parseISCLeases(content string) []DHCPLease {
+	var order []string
+	byAddr := make(map[string]DHCPLease)
+	var current *DHCPLease
+	active := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = strings.TrimSpace(line[:comment])
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, ";"))
+		switch {
+		case len(fields) >= 2 && fields[0] == "lease":
+			current, active = nil, true
+			if ip := net.ParseIP(fields[1]); ip != nil {
+				current = &DHCPLease{Addr: ip}
+			}
+		case current == nil:
+		case line == "}":
+			key := current.Addr.String()
+			if _, ok := byAddr[key]; !ok {
+				order = append(order, key)
+			}
+			if active {
+				byAddr[key] = *current
+			} else {
+				delete(byAddr, key)
+			}
+			current = nil
+		case len(fields) >= 3 && fields[0] == "hardware":
+			if mac, err := net.ParseMAC(fields[2]); err == nil {
+				current.MAC = mac.String()
+			}
+		case len(fields) >= 2 && fields[0] == "client-hostname":
+			current.Hostname = leaseHostname(strings.Trim(strings.Join(fields[1:], " "), `"`))
+		case len(fields) >= 3 && fields[0] == "binding" && fields[1] == "state":
+			active = fields[2] == "active"
+		case len(fields) >= 2 && fields[0] == "ends":
+			current.Expires = parseISCTime(fields[1:])
+		}
+	}
+
+	var leases []DHCPLease
+	for _, key := range order {
+		if lease, ok := byAddr[key]; ok {
+			leases = append(leases, lease)
+		}
+	}
+	return leases
+}
+
+// parseISCTime 解析 ends 字段：never、epoch <秒>、<星期> <yyyy/mm/dd> <hh:mm:ss>（UTC）
+func parseISCTime(fields []string) time.Time {
+	switch {
+	case fields[0] == "never":
+		return time.Time{}
+	case fields[0] == "epoch" && len(fields) >= 2:
+		if sec, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			return time.Unix(sec, 0)
+		}
+	case len(fields) >= 3:
+		if t, err := time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2]); err == nil {
+			return t
+		}
+	}
+	// 无法解析时视为已过期，避免发布陈旧地址
+	return time.Unix(1, 0)
+}
+
+// macFromDUID 从 DUID-LLT / DUID-LL（链路层类型为以太网）中取出 MAC 地址
+func macFromDUID(duid string) string {
+	raw, err := hex.DecodeString(duid)
+	if err != nil || len(raw) < 4 || raw[2] != 0 || raw[3] != 1 {
+		return ""
+	}
+	switch {
+	case raw[0] == 0 && raw[1] == 1 && len(raw) == 14:
+		return net.HardwareAddr(raw[8:]).String()
+	case raw[0] == 0 && raw[1] == 3 && len(raw) == 10:
+		return net.HardwareAddr(raw[4:]).String()
+	}
+	return ""
+}
+
+// leaseHostname 各实现用 * 或 - 表示客户端未上报主机名
+func leaseHostname(name string) string {
+	if name == "*" || name == "-" {
+		return ""
+	}
+	return name
+}
+
+// matchDHCPLeases 按 MAC、主机名（不区分大小写）或主机名通配符筛选指定类型的租约并排序
+func matchDHCPLeases(leases []DHCPLease, match, addrType string) []DHCPLease {
+	mac, macErr := net.ParseMAC(match)
+	pattern := strings.ToLower(match)
+	_, ipv6Unicast, _ := net.ParseCIDR("2000::/3")
+
+	var matched []DHCPLease
+	for _, lease := range leases {
+		if (lease.Addr.To4() != nil) != (addrType == IPv4) {
+			continue
+		}
+		switch {
+		case macErr == nil:
+			if lease.MAC != mac.String() {
+				continue
+			}
+		case strings.ContainsAny(pattern, "*?["):
+			if ok, _ := path.Match(pattern, strings.ToLower(lease.Hostname)); !ok || lease.Hostname == "" {
+				continue
+			}
+		default:
+			if !strings.EqualFold(lease.Hostname, match) {
+				continue
+			}
+		}
+		matched = append(matched, lease)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if ga, gb := ipv6Unicast.Contains(a.Addr), ipv6Unicast.Contains(b.Addr); ga != gb {
+			return ga
+		}
+		if a.Expires.IsZero() != b.Expires.IsZero() {
+			return a.Expires.IsZero()
+		}
+		return a.Expires.After(b.Expires)
+	})
+	return matched
+}
@@ -0,0 +1,155 @@
+package helper
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const dnsmasqLeases = `1700003600 aa:bb:cc:dd:ee:01 192.168.1.10 nas 01:aa:bb:cc:dd:ee:01
+1700003600 aa:bb:cc:dd:ee:02 192.168.1.11 * *
+1699990000 aa:bb:cc:dd:ee:03 192.168.1.12 old-phone *
+0 aa:bb:cc:dd:ee:04 192.168.1.13 printer *
+duid 00:01:00:01:2b:00:00:00:11:22:33:44:55:66
+1700003600 12345678 2001:db8::10 nas 00:03:00:01:aa:bb:cc:dd:ee:01
+1700003600 12345678 fd00::10 nas 00:03:00:01:aa:bb:cc:dd:ee:01
+`
+
+const odhcpdLeases = `# br-lan 000100012b000000aabbccddee05 1 laptop 1700003600 10 128 2001:db8::20/128 fd00::20/128
+# br-lan 0004abcdef0123456789 2 tv -1 11 128 2001:db8::21/128
+# br-lan 000300016655443322aa 3 stale 0 12 128 2001:db8::22/128
+# br-lan aabbccddee06 ipv4 camera 1700003600 c0a8011e 32 192.168.1.30/32
+`
+
+const iscLeases = `# The format of this file is documented in the dhcpd.leases(5) manual page.
+lease 10.0.0.5 {
+  starts 4 2023/11/14 20:00:00;
+  ends 3 2023/11/15 21:00:00;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:55;
+  client-hostname "Office-PC";
+}
+lease 10.0.0.6 {
+  ends never;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:66;
+}
+lease 10.0.0.6 {
+  ends epoch 1700003600; # Tue Nov 14 23:13:20 2023
+  binding state free;
+  hardware ethernet 00:11:22:33:44:66;
+}
+lease 10.0.0.7 {
+  ends never;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:77;
+  client-hostname "nvr";
+}
+`
+
+func TestParseDHCPLeases(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []DHCPLease
+	}{
+		{"dnsmasq", dnsmasqLeases, []DHCPLease{
+			{MAC: "aa:bb:cc:dd:ee:01", Hostname: "nas", Addr: net.ParseIP("192.168.1.10"), Expires: time.Unix(1700003600, 0)},
+			{MAC: "aa:bb:cc:dd:ee:02", Addr: net.ParseIP("192.168.1.11"), Expires: time.Unix(1700003600, 0)},
+			{MAC: "aa:bb:cc:dd:ee:04", Hostname: "printer", Addr: net.ParseIP("192.168.1.13")},
+			{MAC: "aa:bb:cc:dd:ee:01", Hostname: "nas", Addr: net.ParseIP("2001:db8::10"), Expires: time.Unix(1700003600, 0)},
+			{MAC: "aa:bb:cc:dd:ee:01", Hostname: "nas", Addr: net.ParseIP("fd00::10"), Expires: time.Unix(1700003600, 0)},
+		}},
+		{"odhcpd", odhcpdLeases, []DHCPLease{
+			{MAC: "aa:bb:cc:dd:ee:05", Hostname: "laptop", Addr: net.ParseIP("2001:db8::20"), Expires: time.Unix(1700003600, 0)},
+			{MAC: "aa:bb:cc:dd:ee:05", Hostname: "laptop", Addr: net.ParseIP("fd00::20"), Expires: time.Unix(1700003600, 0)},
+			{Hostname: "tv", Addr: net.ParseIP("2001:db8::21")},
+			{MAC: "aa:bb:cc:dd:ee:06", Hostname: "camera", Addr: net.ParseIP("192.168.1.30"), Expires: time.Unix(1700003600, 0)},
+		}},
+		{"isc dhcpd", iscLeases, []DHCPLease{
+			{MAC: "00:11:22:33:44:55", Hostname: "Office-PC", Addr: net.ParseIP("10.0.0.5"), Expires: time.Date(2023, 11, 15, 21, 0, 0, 0, time.UTC)},
+			{MAC: "00:11:22:33:44:77", Hostname: "nvr", Addr: net.ParseIP("10.0.0.7")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDHCPLeases(tt.content, now)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("解析到 %d 条租约，期望 %d 条: %+v", len(got), len(tt.expected), got)
+			}
+			for i, lease := range got {
+				want := tt.expected[i]
+				if lease.MAC != want.MAC || lease.Hostname != want.Hostname || !lease.Addr.Equal(want.Addr) || !lease.Expires.Equal(want.Expires) {
+					t.Errorf("第 %d 条租约 = %+v，期望 %+v", i, lease, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchDHCPLeases(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	leases := append(parseDHCPLeases(dnsmasqLeases, now), parseDHCPLeases(odhcpdLeases, now)...)
+
+	tests := []struct {
+		name     string
+		match    string
+		addrType string
+		expected []string
+	}{
+		{"按 MAC 匹配 IPv4", "AA-BB-CC-DD-EE-01", IPv4, []string{"192.168.1.10"}},
+		{"按 DUID 中的 MAC 匹配 IPv6，全局地址优先", "aa:bb:cc:dd:ee:01", IPv6, []string{"2001:db8::10", "fd00::10"}},
+		{"按主机名匹配不区分大小写", "LAPTOP", IPv6, []string{"2001:db8::20", "fd00::20"}},
+		{"通配符匹配主机名，永久租约优先", "*", IPv4, []string{"192.168.1.13", "192.168.1.10", "192.168.1.30"}},
+		{"通配符前缀", "la*", IPv6, []string{"2001:db8::20", "fd00::20"}},
+		{"地址类型不符", "camera", IPv6, nil},
+		{"过期租约不参与匹配", "old-phone", IPv4, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchDHCPLeases(leases, tt.match, tt.addrType)
+			var addrs []string
+			for _, lease := range got {
+				addrs = append(addrs, lease.Addr.String())
+			}
+			if len(addrs) != len(tt.expected) {
+				t.Fatalf("匹配结果 %v，期望 %v", addrs, tt.expected)
+			}
+			for i := range addrs {
+				if addrs[i] != tt.expected[i] {
+					t.Errorf("匹配结果 %v，期望 %v", addrs, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAddrFromDHCPLease(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dhcpd.leases")
+	if err := os.WriteFile(file, []byte(iscLeases), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetAddrFromDHCPLease("nvr@"+file, IPv4); got != "10.0.0.7" {
+		t.Errorf("按主机名获取 = %q，期望 10.0.0.7", got)
+	}
+	if got := GetAddrFromDHCPLease("00:11:22:33:44:66@"+file, IPv4); got != "" {
+		t.Errorf("已释放的租约不应返回地址，得到 %q", got)
+	}
+	if got := GetAddrFromDHCPLease("nvr@"+file+".missing", IPv4); got != "" {
+		t.Errorf("指定的租约文件不存在时应返回空，得到 %q", got)
+	}
+}
+
+func TestIsDHCPLeaseBulk(t *testing.T) {
+	if !IsDHCPLeaseBulk("*@/tmp/dhcp.leases") || !IsDHCPLeaseBulk("cam-?") {
+		t.Error("含通配符的来源值应为批量模式")
+	}
+	if IsDHCPLeaseBulk("nas@/tmp/dhcp.leases") || IsDHCPLeaseBulk("aa:bb:cc:dd:ee:ff") {
+		t.Error("MAC 或主机名不应为批量模式")
+	}
+}
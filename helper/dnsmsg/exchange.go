@@ -0,0 +1,97 @@
+package dnsmsg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// maxUDPSize 接收 UDP 响应的缓冲区大小
+const maxUDPSize = 4096
+
+// Exchange 向 server 发送已编码的报文并返回原始响应
+// network 为 udp / udp4 / udp6 时，若响应被截断（TC=1）会自动改用 TCP 重试
+func Exchange(network, server string, msg []byte, timeout time.Duration) ([]byte, error) {
+	if len(msg) < headerLen {
+		return nil, errTruncatedMessage
+	}
+	if strings.HasPrefix(network, "tcp") {
+		return exchangeTCP(network, server, msg, timeout)
+	}
+
+	resp, err := exchangeUDP(network, server, msg, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint16(resp[2:])&(1<<9) != 0 {
+		return exchangeTCP(strings.Replace(network, "udp", "tcp", 1), server, msg, timeout)
+	}
+	return resp, nil
+}
+
+// exchangeUDP 通过 UDP 发送报文，忽略 ID 不匹配的响应
+func exchangeUDP(network, server string, msg []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout(network, server, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err = conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxUDPSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= headerLen && buf[0] == msg[0] && buf[1] == msg[1] {
+			return append([]byte(nil), buf[:n]...), nil
+		}
+	}
+}
+
+// exchangeTCP 通过 TCP 发送报文（两字节长度前缀）
+func exchangeTCP(network, server string, msg []byte, timeout time.Duration) ([]byte, error) {
+	if len(msg) > 0xFFFF {
+		return nil, fmt.Errorf("DNS 报文过长: %d", len(msg))
+	}
+	conn, err := net.DialTimeout(network, server, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	framed := binary.BigEndian.AppendUint16(make([]byte, 0, len(msg)+2), uint16(len(msg)))
+	if _, err = conn.Write(append(framed, msg...)); err != nil {
+		return nil, err
+	}
+	var size [2]byte
+	if _, err = io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err = io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	if len(resp) < headerLen || resp[0] != msg[0] || resp[1] != msg[1] {
+		return nil, errors.New("DNS 响应 ID 不匹配")
+	}
+	return resp, nil
+}
+
+// WithDefaultPort 为不带端口的服务器地址补全 53 端口，兼容 IPv6 字面量
+func WithDefaultPort(server string) string {
+	server = strings.TrimSpace(server)
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53")
+}
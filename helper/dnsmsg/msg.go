@@ -0,0 +1,457 @@
+// Package dnsmsg 实现 D-NET 所需的最小 DNS 报文编解码（RFC 1035）、
+// 动态更新（RFC 2136）与 TSIG 签名（RFC 8945），不依赖第三方库。
+package dnsmsg
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 记录类型
+const (
+	TypeA     uint16 = 1
+	TypeNS    uint16 = 2
+	TypeCNAME uint16 = 5
+	TypeSOA   uint16 = 6
+	TypeTXT   uint16 = 16
+	TypeAAAA  uint16 = 28
+	TypeTSIG  uint16 = 250
+	TypeANY   uint16 = 255
+)
+
+// 记录类别
+const (
+	ClassINET  uint16 = 1
+	ClassCHAOS uint16 = 3
+	ClassNONE  uint16 = 254
+	ClassANY   uint16 = 255
+)
+
+// 操作码
+const (
+	OpcodeQuery  uint8 = 0
+	OpcodeUpdate uint8 = 5
+)
+
+// 响应码
+const (
+	RcodeSuccess        uint8 = 0
+	RcodeFormatError    uint8 = 1
+	RcodeServerFailure  uint8 = 2
+	RcodeNameError      uint8 = 3
+	RcodeNotImplemented uint8 = 4
+	RcodeRefused        uint8 = 5
+	RcodeYXDomain       uint8 = 6
+	RcodeYXRRSet        uint8 = 7
+	RcodeNXRRSet        uint8 = 8
+	RcodeNotAuth        uint8 = 9
+	RcodeNotZone        uint8 = 10
+)
+
+var rcodeNames = map[uint8]string{
+	RcodeSuccess:        "NOERROR",
+	RcodeFormatError:    "FORMERR",
+	RcodeServerFailure:  "SERVFAIL",
+	RcodeNameError:      "NXDOMAIN",
+	RcodeNotImplemented: "NOTIMP",
+	RcodeRefused:        "REFUSED",
+	RcodeYXDomain:       "YXDOMAIN",
+	RcodeYXRRSet:        "YXRRSET",
+	RcodeNXRRSet:        "NXRRSET",
+	RcodeNotAuth:        "NOTAUTH",
+	RcodeNotZone:        "NOTZONE",
+}
+
+// RcodeName 返回响应码的助记符
+func RcodeName(rcode uint8) string {
+	if name, ok := rcodeNames[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+const headerLen = 12
+
+var errTruncatedMessage = errors.New("DNS 报文不完整")
+
+// Question 问题段（UPDATE 报文中为 Zone 段）
+type Question struct {
+	Name  string
+	Type  uint16
+	Class uint16
+}
+
+// RR 资源记录，Data 为 RDATA 的原始字节（其中的域名已解压缩）
+type RR struct {
+	Name  string
+	Type  uint16
+	Class uint16
+	TTL   uint32
+	Data  []byte
+}
+
+// Message DNS 报文
+// UPDATE 报文中 Question/Answer/Authority 分别对应 Zone/Prerequisite/Update 段
+type Message struct {
+	ID                 uint16
+	Response           bool
+	Opcode             uint8
+	Authoritative      bool
+	Truncated          bool
+	RecursionDesired   bool
+	RecursionAvailable bool
+	Rcode              uint8
+	Question           []Question
+	Answer             []RR
+	Authority          []RR
+	Additional         []RR
+}
+
+// NewID 生成随机报文 ID
+func NewID() uint16 {
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b[:])
+}
+
+// NewQuery 构造一个单问题的查询报文
+func NewQuery(name string, qtype, qclass uint16) *Message {
+	return &Message{
+		ID:               NewID(),
+		Opcode:           OpcodeQuery,
+		RecursionDesired: true,
+		Question:         []Question{{Name: name, Type: qtype, Class: qclass}},
+	}
+}
+
+// NewUpdate 构造指定区域的 UPDATE 报文
+func NewUpdate(zone string) *Message {
+	return &Message{
+		ID:       NewID(),
+		Opcode:   OpcodeUpdate,
+		Question: []Question{{Name: zone, Type: TypeSOA, Class: ClassINET}},
+	}
+}
+
+// Pack 将报文编码为线上格式（不做域名压缩）
+func (m *Message) Pack() ([]byte, error) {
+	b := make([]byte, headerLen, 512)
+	binary.BigEndian.PutUint16(b[0:], m.ID)
+	var flags uint16
+	if m.Response {
+		flags |= 1 << 15
+	}
+	flags |= uint16(m.Opcode&0xF) << 11
+	if m.Authoritative {
+		flags |= 1 << 10
+	}
+	if m.Truncated {
+		flags |= 1 << 9
+	}
+	if m.RecursionDesired {
+		flags |= 1 << 8
+	}
+	if m.RecursionAvailable {
+		flags |= 1 << 7
+	}
+	flags |= uint16(m.Rcode & 0xF)
+	binary.BigEndian.PutUint16(b[2:], flags)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(m.Question)))
+	binary.BigEndian.PutUint16(b[6:], uint16(len(m.Answer)))
+	binary.BigEndian.PutUint16(b[8:], uint16(len(m.Authority)))
+	binary.BigEndian.PutUint16(b[10:], uint16(len(m.Additional)))
+
+	var err error
+	for _, q := range m.Question {
+		if b, err = appendName(b, q.Name); err != nil {
+			return nil, err
+		}
+		b = binary.BigEndian.AppendUint16(b, q.Type)
+		b = binary.BigEndian.AppendUint16(b, q.Class)
+	}
+	for _, section := range [][]RR{m.Answer, m.Authority, m.Additional} {
+		for _, rr := range section {
+			if b, err = appendRR(b, rr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// appendRR 追加一条资源记录
+func appendRR(b []byte, rr RR) ([]byte, error) {
+	if len(rr.Data) > 0xFFFF {
+		return nil, fmt.Errorf("RDATA 过长: %d", len(rr.Data))
+	}
+	b, err := appendName(b, rr.Name)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint16(b, rr.Type)
+	b = binary.BigEndian.AppendUint16(b, rr.Class)
+	b = binary.BigEndian.AppendUint32(b, rr.TTL)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(rr.Data)))
+	return append(b, rr.Data...), nil
+}
+
+// appendName 追加线上格式的域名（末尾点可省略）
+func appendName(b []byte, name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return append(b, 0), nil
+	}
+	total := 1
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf("无效的域名: %s", name)
+		}
+		total += len(label) + 1
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	if total > 255 {
+		return nil, fmt.Errorf("域名过长: %s", name)
+	}
+	return append(b, 0), nil
+}
+
+// Unpack 解析线上格式的 DNS 报文
+func Unpack(b []byte) (*Message, error) {
+	m, _, err := unpack(b)
+	return m, err
+}
+
+// unpack 解析报文，并返回最后一条附加记录的起始偏移（用于 TSIG 校验）
+func unpack(b []byte) (*Message, int, error) {
+	if len(b) < headerLen {
+		return nil, 0, errTruncatedMessage
+	}
+	flags := binary.BigEndian.Uint16(b[2:])
+	m := &Message{
+		ID:                 binary.BigEndian.Uint16(b[0:]),
+		Response:           flags&(1<<15) != 0,
+		Opcode:             uint8(flags>>11) & 0xF,
+		Authoritative:      flags&(1<<10) != 0,
+		Truncated:          flags&(1<<9) != 0,
+		RecursionDesired:   flags&(1<<8) != 0,
+		RecursionAvailable: flags&(1<<7) != 0,
+		Rcode:              uint8(flags & 0xF),
+	}
+	counts := [4]int{
+		int(binary.BigEndian.Uint16(b[4:])),
+		int(binary.BigEndian.Uint16(b[6:])),
+		int(binary.BigEndian.Uint16(b[8:])),
+		int(binary.BigEndian.Uint16(b[10:])),
+	}
+
+	off := headerLen
+	for i := 0; i < counts[0]; i++ {
+		name, next, err := readName(b, off)
+		if err != nil {
+			return nil, 0, err
+		}
+		if next+4 > len(b) {
+			return nil, 0, errTruncatedMessage
+		}
+		m.Question = append(m.Question, Question{
+			Name:  name,
+			Type:  binary.BigEndian.Uint16(b[next:]),
+			Class: binary.BigEndian.Uint16(b[next+2:]),
+		})
+		off = next + 4
+	}
+
+	lastRR := 0
+	sections := []*[]RR{&m.Answer, &m.Authority, &m.Additional}
+	for s, section := range sections {
+		for i := 0; i < counts[s+1]; i++ {
+			lastRR = off
+			rr, next, err := readRR(b, off)
+			if err != nil {
+				return nil, 0, err
+			}
+			*section = append(*section, rr)
+			off = next
+		}
+	}
+	return m, lastRR, nil
+}
+
+// readRR 读取一条资源记录
+func readRR(b []byte, off int) (RR, int, error) {
+	name, off, err := readName(b, off)
+	if err != nil {
+		return RR{}, 0, err
+	}
+	if off+10 > len(b) {
+		return RR{}, 0, errTruncatedMessage
+	}
+	rr := RR{
+		Name:  name,
+		Type:  binary.BigEndian.Uint16(b[off:]),
+		Class: binary.BigEndian.Uint16(b[off+2:]),
+		TTL:   binary.BigEndian.Uint32(b[off+4:]),
+	}
+	rdlen := int(binary.BigEndian.Uint16(b[off+8:]))
+	off += 10
+	if off+rdlen > len(b) {
+		return RR{}, 0, errTruncatedMessage
+	}
+	rdata := b[off : off+rdlen]
+
+	// 含域名的 RDATA 可能使用压缩指针，这里展开为非压缩格式，便于脱离报文独立解析
+	// RDATA 为空（如 UPDATE 报文中删除记录集）时无需展开
+	switch {
+	case rdlen == 0:
+		rr.Data = nil
+	case rr.Type == TypeCNAME || rr.Type == TypeNS:
+		target, _, err := readName(b, off)
+		if err != nil {
+			return RR{}, 0, err
+		}
+		rr.Data, _ = appendName(nil, target)
+	case rr.Type == TypeSOA:
+		mname, next, err := readName(b, off)
+		if err != nil {
+			return RR{}, 0, err
+		}
+		rname, next, err := readName(b, next)
+		if err != nil {
+			return RR{}, 0, err
+		}
+		if next+20 > off+rdlen {
+			return RR{}, 0, errTruncatedMessage
+		}
+		rr.Data, _ = appendName(nil, mname)
+		rr.Data, _ = appendName(rr.Data, rname)
+		rr.Data = append(rr.Data, b[next:next+20]...)
+	default:
+		rr.Data = append([]byte(nil), rdata...)
+	}
+	return rr, off + rdlen, nil
+}
+
+// readName 读取域名（支持压缩指针），返回以点结尾的完整域名和下一个偏移
+func readName(b []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(b) {
+			return "", 0, errTruncatedMessage
+		}
+		c := int(b[off])
+		switch c & 0xC0 {
+		case 0x00:
+			if c == 0 {
+				if next < 0 {
+					next = off + 1
+				}
+				return strings.Join(labels, ".") + ".", next, nil
+			}
+			if off+1+c > len(b) {
+				return "", 0, errTruncatedMessage
+			}
+			labels = append(labels, string(b[off+1:off+1+c]))
+			off += 1 + c
+		case 0xC0:
+			if off+2 > len(b) {
+				return "", 0, errTruncatedMessage
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			jumps++
+			if jumps > 32 {
+				return "", 0, errors.New("DNS 报文域名压缩指针过多")
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3FFF)
+		default:
+			return "", 0, errors.New("不支持的 DNS 域名标签类型")
+		}
+	}
+}
+
+// AData 构造 A 记录的 RDATA
+func AData(ip net.IP) ([]byte, error) {
+	v4 := ip.To4()
+	if v4 == nil {
+		return nil, fmt.Errorf("不是有效的 IPv4 地址: %s", ip)
+	}
+	return []byte(v4), nil
+}
+
+// AAAAData 构造 AAAA 记录的 RDATA
+func AAAAData(ip net.IP) ([]byte, error) {
+	if ip.To4() != nil || ip.To16() == nil {
+		return nil, fmt.Errorf("不是有效的 IPv6 地址: %s", ip)
+	}
+	return []byte(ip.To16()), nil
+}
+
+// NameData 构造 CNAME/NS 记录的 RDATA
+func NameData(name string) ([]byte, error) {
+	return appendName(nil, name)
+}
+
+// TXTData 构造 TXT 记录的 RDATA，超过 255 字节的内容自动拆分为多个字符串
+func TXTData(text string) []byte {
+	b := make([]byte, 0, len(text)+len(text)/255+1)
+	for {
+		chunk := text
+		if len(chunk) > 255 {
+			chunk = chunk[:255]
+		}
+		b = append(b, byte(len(chunk)))
+		b = append(b, chunk...)
+		text = text[len(chunk):]
+		if text == "" {
+			return b
+		}
+	}
+}
+
+// IP 解析 A/AAAA 记录中的地址
+func (rr RR) IP() net.IP {
+	if (rr.Type == TypeA && len(rr.Data) == net.IPv4len) || (rr.Type == TypeAAAA && len(rr.Data) == net.IPv6len) {
+		return net.IP(rr.Data)
+	}
+	return nil
+}
+
+// Target 解析 CNAME/NS/SOA 记录中的首个域名
+func (rr RR) Target() string {
+	name, _, err := readName(rr.Data, 0)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// Text 解析 TXT 记录，多个字符串直接拼接
+func (rr RR) Text() string {
+	var sb strings.Builder
+	for off := 0; off < len(rr.Data); {
+		n := int(rr.Data[off])
+		if off+1+n > len(rr.Data) {
+			break
+		}
+		sb.Write(rr.Data[off+1 : off+1+n])
+		off += 1 + n
+	}
+	return sb.String()
+}
+
+// Fqdn 为域名补全末尾的点
+func Fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
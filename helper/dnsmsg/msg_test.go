@@ -0,0 +1,193 @@
+package dnsmsg
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	a, _ := AData(net.ParseIP("1.2.3.4"))
+	aaaa, _ := AAAAData(net.ParseIP("2001:db8::1"))
+	target, _ := NameData("target.example.com.")
+
+	m := NewUpdate("example.com.")
+	m.Authority = []RR{
+		{Name: "www.example.com.", Type: TypeCNAME, Class: ClassANY},
+		{Name: "www.example.com.", Type: TypeA, Class: ClassINET, TTL: 600, Data: a},
+		{Name: "v6.example.com.", Type: TypeAAAA, Class: ClassINET, TTL: 300, Data: aaaa},
+		{Name: "alias.example.com.", Type: TypeCNAME, Class: ClassINET, TTL: 60, Data: target},
+		{Name: "txt.example.com.", Type: TypeTXT, Class: ClassINET, TTL: 60, Data: TXTData("hello")},
+	}
+
+	b, err := m.Pack()
+	if err != nil {
+		t.Fatalf("Pack 失败: %v", err)
+	}
+	got, err := Unpack(b)
+	if err != nil {
+		t.Fatalf("Unpack 失败: %v", err)
+	}
+
+	if got.ID != m.ID || got.Opcode != OpcodeUpdate || len(got.Question) != 1 || got.Question[0].Name != "example.com." {
+		t.Fatalf("报文头或区域段不一致: %+v", got)
+	}
+	if len(got.Authority) != len(m.Authority) {
+		t.Fatalf("更新段数量期望 %d, 实际 %d", len(m.Authority), len(got.Authority))
+	}
+	if ip := got.Authority[1].IP(); !ip.Equal(net.ParseIP("1.2.3.4")) {
+		t.Errorf("A 记录期望 1.2.3.4, 实际 %v", ip)
+	}
+	if ip := got.Authority[2].IP(); !ip.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("AAAA 记录期望 2001:db8::1, 实际 %v", ip)
+	}
+	if target := got.Authority[3].Target(); target != "target.example.com." {
+		t.Errorf("CNAME 期望 target.example.com., 实际 %s", target)
+	}
+	if text := got.Authority[4].Text(); text != "hello" {
+		t.Errorf("TXT 期望 hello, 实际 %s", text)
+	}
+	if got.Authority[0].Class != ClassANY || len(got.Authority[0].Data) != 0 {
+		t.Errorf("删除记录集的 RR 不正确: %+v", got.Authority[0])
+	}
+}
+
+func TestUnpackCompressedNames(t *testing.T) {
+	// 手工构造带压缩指针的应答：www.example.com. CNAME -> target.example.com.（后缀指向问题段）
+	b := []byte{
+		0x12, 0x34, 0x81, 0x80, 0, 1, 0, 1, 0, 0, 0, 0,
+		3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		0, 5, 0, 1,
+		0xC0, 12, 0, 5, 0, 1, 0, 0, 0, 60, 0, 9,
+		6, 't', 'a', 'r', 'g', 'e', 't', 0xC0, 16,
+	}
+	m, err := Unpack(b)
+	if err != nil {
+		t.Fatalf("Unpack 失败: %v", err)
+	}
+	if !m.Response || !m.RecursionAvailable || m.Rcode != RcodeSuccess {
+		t.Errorf("报文头标志解析错误: %+v", m)
+	}
+	if len(m.Answer) != 1 || m.Answer[0].Name != "www.example.com." {
+		t.Fatalf("应答段解析错误: %+v", m.Answer)
+	}
+	if target := m.Answer[0].Target(); target != "target.example.com." {
+		t.Errorf("CNAME 期望 target.example.com., 实际 %s", target)
+	}
+}
+
+func TestUnpackRejectsPointerLoop(t *testing.T) {
+	b := []byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xC0, 12, 0, 1, 0, 1}
+	if _, err := Unpack(b); err == nil {
+		t.Error("期望循环压缩指针返回错误")
+	}
+}
+
+func TestTXTDataSplitsLongText(t *testing.T) {
+	long := string(bytes.Repeat([]byte("a"), 300))
+	data := TXTData(long)
+	if data[0] != 255 || int(data[256]) != 45 {
+		t.Fatalf("长 TXT 未正确拆分: 首段 %d, 次段 %d", data[0], data[256])
+	}
+	if got := (RR{Type: TypeTXT, Data: data}).Text(); got != long {
+		t.Error("拆分后的 TXT 无法还原")
+	}
+}
+
+func TestParseTSIGKey(t *testing.T) {
+	tests := []struct {
+		input     string
+		name      string
+		algorithm string
+		wantErr   bool
+	}{
+		{"ddns-key:c2VjcmV0", "ddns-key.", HmacSHA256, false},
+		{"hmac-sha512:DDNS-Key.:c2VjcmV0", "ddns-key.", HmacSHA512, false},
+		{"hmac-md5:ddns-key:c2VjcmV0", "", "", true},
+		{"ddns-key:not base64!", "", "", true},
+		{"c2VjcmV0", "", "", true},
+		{":c2VjcmV0", "", "", true},
+	}
+	for _, tt := range tests {
+		key, err := ParseTSIGKey(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTSIGKey(%q) 期望返回错误", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTSIGKey(%q) 意外错误: %v", tt.input, err)
+			continue
+		}
+		if key.Name != tt.name || key.Algorithm != tt.algorithm || string(key.Secret) != "secret" {
+			t.Errorf("ParseTSIGKey(%q) = %+v", tt.input, key)
+		}
+	}
+}
+
+func TestTSIGSignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{"hmac-sha256", "hmac-sha512"} {
+		key, err := ParseTSIGKey(algorithm + ":ddns-key:c2VjcmV0LWtleS1mb3ItdGVzdA==")
+		if err != nil {
+			t.Fatal(err)
+		}
+		now := time.Unix(1700000000, 0)
+
+		req, _ := NewUpdate("example.com.").Pack()
+		signed, reqMAC, err := key.Sign(req, nil, now)
+		if err != nil {
+			t.Fatalf("[%s] 签名失败: %v", algorithm, err)
+		}
+		if _, err := key.Verify(signed, nil, now.Add(time.Minute)); err != nil {
+			t.Fatalf("[%s] 校验请求失败: %v", algorithm, err)
+		}
+
+		// 响应签名需要链接请求 MAC
+		respMsg := &Message{ID: 0x4242, Response: true, Opcode: OpcodeUpdate}
+		resp, _ := respMsg.Pack()
+		signedResp, _, err := key.Sign(resp, reqMAC, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := key.Verify(signedResp, reqMAC, now); err != nil {
+			t.Errorf("[%s] 校验响应失败: %v", algorithm, err)
+		}
+		if _, err := key.Verify(signedResp, nil, now); err == nil {
+			t.Errorf("[%s] 缺少请求 MAC 时期望校验失败", algorithm)
+		}
+
+		// 篡改报文内容
+		tampered := append([]byte(nil), signed...)
+		tampered[headerLen+1] ^= 0x20
+		if _, err := key.Verify(tampered, nil, now); err == nil {
+			t.Errorf("[%s] 篡改后期望校验失败", algorithm)
+		}
+
+		// 超出时间窗口
+		if _, err := key.Verify(signed, nil, now.Add(time.Hour)); err == nil {
+			t.Errorf("[%s] 超时期望校验失败", algorithm)
+		}
+
+		// 错误的密钥
+		other, _ := ParseTSIGKey(algorithm + ":ddns-key:b3RoZXI=")
+		if _, err := other.Verify(signed, nil, now); err == nil {
+			t.Errorf("[%s] 错误密钥期望校验失败", algorithm)
+		}
+	}
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	tests := map[string]string{
+		"ns1.example.com":    "ns1.example.com:53",
+		"192.0.2.1:5353":     "192.0.2.1:5353",
+		"2001:db8::53":       "[2001:db8::53]:53",
+		"[2001:db8::53]:553": "[2001:db8::53]:553",
+	}
+	for input, want := range tests {
+		if got := WithDefaultPort(input); got != want {
+			t.Errorf("WithDefaultPort(%q) = %q, 期望 %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package dnsmsg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+// TSIG 算法名称（RFC 8945）
+const (
+	HmacSHA256 = "hmac-sha256."
+	HmacSHA512 = "hmac-sha512."
+)
+
+// TSIG 扩展错误码
+const (
+	tsigBadSig  = 16
+	tsigBadKey  = 17
+	tsigBadTime = 18
+)
+
+// defaultFudge 允许的时钟偏差（秒）
+const defaultFudge = 300
+
+var tsigHashes = map[string]func() hash.Hash{
+	HmacSHA256: sha256.New,
+	HmacSHA512: sha512.New,
+}
+
+// TSIGKey TSIG 共享密钥
+type TSIGKey struct {
+	Name      string // 密钥名称，如 ddns-key.
+	Algorithm string // 算法名称，如 hmac-sha256.
+	Secret    []byte // 解码后的密钥
+}
+
+// ParseTSIGKey 解析 nsupdate -y 风格的密钥描述：[算法:]名称:Base64密钥
+// 算法省略时默认 hmac-sha256，支持 hmac-sha256 / hmac-sha512
+func ParseTSIGKey(s string) (*TSIGKey, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	algorithm := HmacSHA256
+	switch len(parts) {
+	case 2:
+	case 3:
+		algorithm = Fqdn(strings.ToLower(parts[0]))
+		parts = parts[1:]
+	default:
+		return nil, errors.New("TSIG 密钥格式应为 [算法:]名称:密钥")
+	}
+	if _, ok := tsigHashes[algorithm]; !ok {
+		return nil, fmt.Errorf("不支持的 TSIG 算法: %s", strings.TrimSuffix(algorithm, "."))
+	}
+	if parts[0] == "" {
+		return nil, errors.New("TSIG 密钥名称为空")
+	}
+	secret, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(secret) == 0 {
+		return nil, errors.New("TSIG 密钥不是有效的 Base64")
+	}
+	return &TSIGKey{
+		Name:      Fqdn(strings.ToLower(parts[0])),
+		Algorithm: algorithm,
+		Secret:    secret,
+	}, nil
+}
+
+// tsigRecord TSIG RDATA 字段
+type tsigRecord struct {
+	algorithm  string
+	timeSigned uint64
+	fudge      uint16
+	mac        []byte
+	originalID uint16
+	errorCode  uint16
+	otherData  []byte
+}
+
+// Sign 为已编码的报文追加 TSIG 记录，返回签名后的报文和 MAC
+// requestMAC 仅在签名响应时传入（请求报文的 MAC）
+func (k *TSIGKey) Sign(msg []byte, requestMAC []byte, now time.Time) ([]byte, []byte, error) {
+	if len(msg) < headerLen {
+		return nil, nil, errTruncatedMessage
+	}
+	t := tsigRecord{
+		algorithm:  k.Algorithm,
+		timeSigned: uint64(now.Unix()),
+		fudge:      defaultFudge,
+		originalID: binary.BigEndian.Uint16(msg[0:]),
+	}
+	mac, err := k.digest(msg, requestMAC, &t)
+	if err != nil {
+		return nil, nil, err
+	}
+	t.mac = mac
+
+	rdata, err := t.pack()
+	if err != nil {
+		return nil, nil, err
+	}
+	signed := append([]byte(nil), msg...)
+	if signed, err = appendRR(signed, RR{Name: k.Name, Type: TypeTSIG, Class: ClassANY, Data: rdata}); err != nil {
+		return nil, nil, err
+	}
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed, mac, nil
+}
+
+// Verify 校验报文末尾的 TSIG 记录，返回报文携带的 MAC
+// 校验响应时 requestMAC 传入对应请求的 MAC，校验请求时传 nil
+func (k *TSIGKey) Verify(msg []byte, requestMAC []byte, now time.Time) ([]byte, error) {
+	m, tsigOff, err := unpack(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Additional) == 0 || m.Additional[len(m.Additional)-1].Type != TypeTSIG {
+		return nil, errors.New("报文未携带 TSIG 签名")
+	}
+	rr := m.Additional[len(m.Additional)-1]
+	if !strings.EqualFold(rr.Name, k.Name) {
+		return nil, fmt.Errorf("TSIG 密钥名称不匹配: %s", rr.Name)
+	}
+	t, err := unpackTSIG(rr.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch t.errorCode {
+	case 0:
+	case tsigBadSig:
+		return nil, errors.New("TSIG 签名校验失败 (BADSIG)")
+	case tsigBadKey:
+		return nil, errors.New("服务器不认识该 TSIG 密钥 (BADKEY)")
+	case tsigBadTime:
+		return nil, errors.New("TSIG 时间校验失败，请检查时钟 (BADTIME)")
+	default:
+		return nil, fmt.Errorf("TSIG 错误码 %d", t.errorCode)
+	}
+	if !strings.EqualFold(t.algorithm, k.Algorithm) {
+		return nil, fmt.Errorf("TSIG 算法不匹配: %s", t.algorithm)
+	}
+
+	// 去掉 TSIG 记录、ARCOUNT 减一、恢复原始 ID 后重新计算摘要
+	stripped := append([]byte(nil), msg[:tsigOff]...)
+	binary.BigEndian.PutUint16(stripped[0:], t.originalID)
+	binary.BigEndian.PutUint16(stripped[10:], binary.BigEndian.Uint16(stripped[10:])-1)
+	expected, err := k.digest(stripped, requestMAC, &t)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(expected, t.mac) {
+		return nil, errors.New("TSIG 签名不匹配")
+	}
+
+	diff := now.Unix() - int64(t.timeSigned)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > int64(t.fudge) {
+		return nil, errors.New("TSIG 签名时间超出允许范围")
+	}
+	return t.mac, nil
+}
+
+// digest 按 RFC 8945 4.3.3 计算 MAC
+func (k *TSIGKey) digest(msg []byte, requestMAC []byte, t *tsigRecord) ([]byte, error) {
+	newHash, ok := tsigHashes[strings.ToLower(k.Algorithm)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的 TSIG 算法: %s", k.Algorithm)
+	}
+	h := hmac.New(newHash, k.Secret)
+	if requestMAC != nil {
+		var size [2]byte
+		binary.BigEndian.PutUint16(size[:], uint16(len(requestMAC)))
+		h.Write(size[:])
+		h.Write(requestMAC)
+	}
+	h.Write(msg)
+
+	vars, err := appendName(nil, strings.ToLower(k.Name))
+	if err != nil {
+		return nil, err
+	}
+	vars = binary.BigEndian.AppendUint16(vars, ClassANY)
+	vars = binary.BigEndian.AppendUint32(vars, 0)
+	if vars, err = appendName(vars, strings.ToLower(t.algorithm)); err != nil {
+		return nil, err
+	}
+	vars = appendUint48(vars, t.timeSigned)
+	vars = binary.BigEndian.AppendUint16(vars, t.fudge)
+	vars = binary.BigEndian.AppendUint16(vars, t.errorCode)
+	vars = binary.BigEndian.AppendUint16(vars, uint16(len(t.otherData)))
+	vars = append(vars, t.otherData...)
+	h.Write(vars)
+	return h.Sum(nil), nil
+}
+
+// pack 编码 TSIG RDATA
+func (t *tsigRecord) pack() ([]byte, error) {
+	b, err := appendName(nil, t.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	b = appendUint48(b, t.timeSigned)
+	b = binary.BigEndian.AppendUint16(b, t.fudge)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(t.mac)))
+	b = append(b, t.mac...)
+	b = binary.BigEndian.AppendUint16(b, t.originalID)
+	b = binary.BigEndian.AppendUint16(b, t.errorCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(t.otherData)))
+	return append(b, t.otherData...), nil
+}
+
+// unpackTSIG 解析 TSIG RDATA
+func unpackTSIG(b []byte) (tsigRecord, error) {
+	var t tsigRecord
+	algorithm, off, err := readName(b, 0)
+	if err != nil {
+		return t, err
+	}
+	t.algorithm = algorithm
+	if off+10 > len(b) {
+		return t, errTruncatedMessage
+	}
+	t.timeSigned = uint64(binary.BigEndian.Uint16(b[off:]))<<32 | uint64(binary.BigEndian.Uint32(b[off+2:]))
+	t.fudge = binary.BigEndian.Uint16(b[off+6:])
+	macLen := int(binary.BigEndian.Uint16(b[off+8:]))
+	off += 10
+	if off+macLen+6 > len(b) {
+		return t, errTruncatedMessage
+	}
+	t.mac = b[off : off+macLen]
+	off += macLen
+	t.originalID = binary.BigEndian.Uint16(b[off:])
+	t.errorCode = binary.BigEndian.Uint16(b[off+2:])
+	otherLen := int(binary.BigEndian.Uint16(b[off+4:]))
+	off += 6
+	if off+otherLen > len(b) {
+		return t, errTruncatedMessage
+	}
+	t.otherData = b[off : off+otherLen]
+	return t, nil
+}
+
+// appendUint48 追加 48 位大端整数（TSIG Time Signed 字段）
+func appendUint48(b []byte, v uint64) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(v>>32))
+	return binary.BigEndian.AppendUint32(b, uint32(v))
+}
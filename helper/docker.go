@@ -1,8 +1,17 @@
 package helper
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
 // DockerEnvFile Docker容器中包含的文件
@@ -21,3 +30,141 @@ func IsRunInDocker() bool {
 
 	return false
 }
+
+// DefaultDockerSocket Docker Engine API 默认的 unix socket
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// dockerAPITimeout 列出容器等普通请求的超时时间，事件订阅为长连接不受此限制
+const dockerAPITimeout = 10 * time.Second
+
+// DockerContainer Engine API /containers/json 返回的容器信息（仅保留用到的字段）
+type DockerContainer struct {
+	ID              string            `json:"Id"`
+	Names           []string          `json:"Names"`
+	Labels          map[string]string `json:"Labels"`
+	State           string            `json:"State"`
+	NetworkSettings struct {
+		Networks map[string]DockerNetwork `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// DockerNetwork 容器在某个网络中的地址
+type DockerNetwork struct {
+	IPAddress         string `json:"IPAddress"`
+	GlobalIPv6Address string `json:"GlobalIPv6Address"`
+}
+
+// Name 返回容器名称（去掉开头的 /），没有名称时返回短 ID
+func (c DockerContainer) Name() string {
+	for _, name := range c.Names {
+		if name = strings.TrimPrefix(name, "/"); name != "" {
+			return name
+		}
+	}
+	if len(c.ID) > 12 {
+		return c.ID[:12]
+	}
+	return c.ID
+}
+
+// Addr 按网络名排序后返回容器的第一个指定类型地址，未接入对应网络时返回空
+func (c DockerContainer) Addr(addrType string) string {
+	names := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		network := c.NetworkSettings.Networks[name]
+		addr := network.IPAddress
+		if addrType == IPv6 {
+			addr = network.GlobalIPv6Address
+		}
+		if addr != "" {
+			return addr
+		}
+	}
+	return ""
+}
+
+// DockerEvent Engine API /events 推送的容器事件
+type DockerEvent struct {
+	Action string `json:"Action"` // start、die 等
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+}
+
+// newDockerClient 创建经 unix socket 访问 Engine API 的 HTTP 客户端
+func newDockerClient(socket string, timeout time.Duration) *http.Client {
+	if socket == "" {
+		socket = DefaultDockerSocket
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+}
+
+// dockerLabelFilter 构造只匹配带指定标签的 filters 参数
+func dockerLabelFilter(filters map[string][]string, label string) string {
+	if label != "" {
+		filters["label"] = []string{label}
+	}
+	data, _ := json.Marshal(filters)
+	return url.QueryEscape(string(data))
+}
+
+// ListDockerContainers 通过 Engine API 列出带指定标签的运行中容器
+func ListDockerContainers(socket, label string) ([]DockerContainer, error) {
+	client := newDockerClient(socket, dockerAPITimeout)
+	resp, err := client.Get("http://docker/containers/json?filters=" + dockerLabelFilter(map[string][]string{}, label))
+	if err != nil {
+		return nil, fmt.Errorf("连接 Docker 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("列出容器失败: %s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var containers []DockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("解析容器列表失败: %w", err)
+	}
+	return containers, nil
+}
+
+// WatchDockerEvents 订阅带指定标签容器的启动 / 停止事件，每个事件调用一次 onEvent；
+// 连接断开或 ctx 取消时返回
+func WatchDockerEvents(ctx context.Context, socket, label string, onEvent func(DockerEvent)) error {
+	filters := map[string][]string{"type": {"container"}, "event": {"start", "die"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/events?filters="+dockerLabelFilter(filters, label), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := newDockerClient(socket, 0).Do(req)
+	if err != nil {
+		return fmt.Errorf("连接 Docker 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("订阅容器事件失败: %s", resp.Status)
+	}
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event DockerEvent
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("容器事件流中断: %w", err)
+		}
+		onEvent(event)
+	}
+}
@@ -0,0 +1,112 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startFakeDockerSocket 在临时 unix socket 上启动模拟的 Docker Engine API
+func startFakeDockerSocket(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	// unix socket 路径长度有限，不使用较长的 t.TempDir()
+	dir, err := os.MkdirTemp("", "dnet-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix socket 不可用: %v", err)
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return socket
+}
+
+func TestListDockerContainers(t *testing.T) {
+	var filters string
+	socket := startFakeDockerSocket(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" {
+			http.NotFound(w, r)
+			return
+		}
+		filters = r.URL.Query().Get("filters")
+		w.Write([]byte(`[{"Id":"0123456789abcdef","Names":["/web"],"State":"running",
+			"Labels":{"dnet.ddns.domain":"app.example.com"},
+			"NetworkSettings":{"Networks":{"bridge":{"IPAddress":"172.17.0.2","GlobalIPv6Address":""},
+			"app_net":{"IPAddress":"172.20.0.3","GlobalIPv6Address":"2001:db8::3"}}}}]`))
+	}))
+
+	containers, err := ListDockerContainers(socket, "dnet.ddns.domain")
+	if err != nil {
+		t.Fatalf("ListDockerContainers() error: %v", err)
+	}
+	if filters != `{"label":["dnet.ddns.domain"]}` {
+		t.Errorf("unexpected filters: %s", filters)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(containers))
+	}
+	c := containers[0]
+	if c.Name() != "web" || c.Labels["dnet.ddns.domain"] != "app.example.com" {
+		t.Errorf("unexpected container: %+v", c)
+	}
+	// 按网络名排序取第一个地址：app_net 排在 bridge 之前
+	if got := c.Addr(IPv4); got != "172.20.0.3" {
+		t.Errorf("Addr(IPv4) = %q, want 172.20.0.3", got)
+	}
+	if got := c.Addr(IPv6); got != "2001:db8::3" {
+		t.Errorf("Addr(IPv6) = %q, want 2001:db8::3", got)
+	}
+}
+
+func TestListDockerContainers_Errors(t *testing.T) {
+	socket := startFakeDockerSocket(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"permission denied"}`, http.StatusForbidden)
+	}))
+	if _, err := ListDockerContainers(socket, ""); err == nil {
+		t.Error("expected error on non-200 response")
+	}
+	if _, err := ListDockerContainers(filepath.Join(os.TempDir(), "dnet-missing.sock"), ""); err == nil {
+		t.Error("expected error when socket does not exist")
+	}
+}
+
+func TestWatchDockerEvents(t *testing.T) {
+	socket := startFakeDockerSocket(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" {
+			http.NotFound(w, r)
+			return
+		}
+		encoder := json.NewEncoder(w)
+		for _, action := range []string{"start", "die"} {
+			encoder.Encode(map[string]interface{}{
+				"Type":   "container",
+				"Action": action,
+				"Actor":  map[string]interface{}{"ID": "abc", "Attributes": map[string]string{"name": "web"}},
+			})
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var actions []string
+	err := WatchDockerEvents(ctx, socket, "dnet.ddns.domain", func(event DockerEvent) {
+		actions = append(actions, event.Action+":"+event.Actor.Attributes["name"])
+	})
+	if err == nil {
+		t.Error("expected error when event stream ends")
+	}
+	if len(actions) != 2 || actions[0] != "start:web" || actions[1] != "die:web" {
+		t.Errorf("unexpected events: %v", actions)
+	}
+}
@@ -0,0 +1,144 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AddrExtract 接口 / 命令来源的地址提取方式，均为空时沿用默认的 IPv4 / IPv6 正则取第一个匹配
+type AddrExtract struct {
+	JSONPath string // gjson 风格路径，如 data.client、ips.1、items.#.ip，先按路径取值再提取
+	Regex    string // 自定义正则，有捕获组时取第一个捕获组
+	Index    int    // 取第 N 个匹配（从 1 开始），0 表示第一个
+}
+
+// IsZero 是否未配置任何提取方式
+func (e AddrExtract) IsZero() bool {
+	return e.JSONPath == "" && e.Regex == "" && e.Index <= 0
+}
+
+// String 用于缓存键和日志
+func (e AddrExtract) String() string {
+	return fmt.Sprintf("json=%s;regex=%s;index=%d", e.JSONPath, e.Regex, e.Index)
+}
+
+// extractAddr 按提取方式从文本中取出对应类型的地址
+func extractAddr(text string, config addrTypeConfig, extract AddrExtract) (string, error) {
+	if extract.IsZero() {
+		if result := config.regex.FindString(text); result != "" {
+			return result, nil
+		}
+		return "", errors.New("未匹配到地址")
+	}
+
+	if extract.JSONPath != "" {
+		value, err := lookupJSONPath(text, extract.JSONPath)
+		if err != nil {
+			return "", err
+		}
+		text = value
+	}
+
+	var candidates []string
+	if extract.Regex != "" {
+		re, err := regexp.Compile(extract.Regex)
+		if err != nil {
+			return "", fmt.Errorf("正则表达式无效: %w", err)
+		}
+		for _, match := range re.FindAllStringSubmatch(text, -1) {
+			if len(match) > 1 {
+				candidates = append(candidates, match[1])
+			} else {
+				candidates = append(candidates, match[0])
+			}
+		}
+	} else {
+		candidates = config.regex.FindAllString(text, -1)
+	}
+	if len(candidates) == 0 {
+		return "", errors.New("未匹配到地址")
+	}
+
+	index := 1
+	if extract.Index > 0 {
+		index = extract.Index
+	}
+	if index > len(candidates) {
+		return "", fmt.Errorf("只有 %d 个匹配，无法取第 %d 个", len(candidates), index)
+	}
+	result := strings.TrimSpace(candidates[index-1])
+	ip := net.ParseIP(result)
+	if ip == nil || (ip.To4() != nil) != (config.addrTypeName == "IPv4") {
+		return "", fmt.Errorf("提取结果不是有效的 %s 地址: %q", config.addrTypeName, result)
+	}
+	return ip.String(), nil
+}
+
+// lookupJSONPath 按 gjson 风格路径取值：点分隔字段，数字为数组下标，# 展开数组，\. 转义字段名中的点；
+// 结果为字符串时直接返回，其它类型返回 JSON 文本，展开得到的多个结果以换行拼接
+func lookupJSONPath(body, path string) (string, error) {
+	var root interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		return "", fmt.Errorf("响应不是有效的 JSON: %w", err)
+	}
+
+	values := []interface{}{root}
+	for _, key := range splitJSONPath(path) {
+		var next []interface{}
+		for _, value := range values {
+			switch node := value.(type) {
+			case map[string]interface{}:
+				if child, ok := node[key]; ok {
+					next = append(next, child)
+				}
+			case []interface{}:
+				if key == "#" {
+					next = append(next, node...)
+				} else if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node) {
+					next = append(next, node[i])
+				}
+			}
+		}
+		if len(next) == 0 {
+			return "", fmt.Errorf("JSON 路径 %s 不存在", path)
+		}
+		values = next
+	}
+
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			parts = append(parts, s)
+			continue
+		}
+		b, _ := json.Marshal(value)
+		parts = append(parts, string(b))
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// splitJSONPath 按未转义的点拆分路径
+func splitJSONPath(path string) []string {
+	var keys []string
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			sb.WriteByte(path[i])
+		case path[i] == '.':
+			keys = append(keys, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(path[i])
+		}
+	}
+	return append(keys, sb.String())
+}
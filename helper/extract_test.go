@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractAddr(t *testing.T) {
+	jsonBody := `{"client":"1.2.3.4","server":"5.6.7.8","data":{"ips":["10.0.0.1","198.51.100.7"],"v6":"2001:db8::9"},` +
+		`"items":[{"ip":"203.0.113.1"},{"ip":"203.0.113.2"}],"a.b":{"ip":"192.0.2.44"}}`
+	statusPage := `<tr><td>LAN</td><td>192.168.1.1</td></tr><tr><td>WAN IP</td><td>100.64.1.2</td></tr><tr><td>DNS</td><td>223.5.5.5</td></tr>`
+
+	tests := []struct {
+		name     string
+		text     string
+		addrType string
+		extract  AddrExtract
+		expected string
+	}{
+		{"default takes first match", jsonBody, IPv4, AddrExtract{}, "1.2.3.4"},
+		{"json path field", jsonBody, IPv4, AddrExtract{JSONPath: "server"}, "5.6.7.8"},
+		{"json path array index", jsonBody, IPv4, AddrExtract{JSONPath: "data.ips.1"}, "198.51.100.7"},
+		{"json path expand array with nth match", jsonBody, IPv4, AddrExtract{JSONPath: "items.#.ip", Index: 2}, "203.0.113.2"},
+		{"json path escaped dot", jsonBody, IPv4, AddrExtract{JSONPath: `a\.b.ip`}, "192.0.2.44"},
+		{"json path IPv6", jsonBody, IPv6, AddrExtract{JSONPath: "data.v6"}, "2001:db8::9"},
+		{"json path missing", jsonBody, IPv4, AddrExtract{JSONPath: "data.missing"}, ""},
+		{"regex capture group", statusPage, IPv4, AddrExtract{Regex: `WAN IP</td><td>([^<]+)`}, "100.64.1.2"},
+		{"nth match with default regex", statusPage, IPv4, AddrExtract{Index: 3}, "223.5.5.5"},
+		{"nth match out of range", statusPage, IPv4, AddrExtract{Index: 4}, ""},
+		{"captured text is not an address", statusPage, IPv4, AddrExtract{Regex: `<td>(LAN)</td>`}, ""},
+		{"captured address of wrong family", jsonBody, IPv6, AddrExtract{JSONPath: "client", Regex: `(.+)`}, ""},
+		{"invalid regex", statusPage, IPv4, AddrExtract{Regex: `(`}, ""},
+		{"invalid json", statusPage, IPv4, AddrExtract{JSONPath: "client"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractAddr(tt.text, getAddrTypeConfig(tt.addrType), tt.extract)
+			if got != tt.expected {
+				t.Errorf("extractAddr() = %q (err=%v), want %q", got, err, tt.expected)
+			}
+			if tt.expected == "" && err == nil {
+				t.Errorf("expected error when nothing is extracted")
+			}
+		})
+	}
+}
+
+func TestGetOrSetDynamicIPWithExtract(t *testing.T) {
+	ClearGlobalIPCache()
+	t.Cleanup(ClearGlobalIPCache)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"client":"1.2.3.4","server":"5.6.7.8"}`))
+	}))
+	defer server.Close()
+
+	if got, ok := GetOrSetDynamicIPWithExtract(DynamicIPv4URL, server.URL, AddrExtract{JSONPath: "server"}); !ok || got != "5.6.7.8" {
+		t.Fatalf("expected 5.6.7.8, got %q %v", got, ok)
+	}
+	// 相同 URL 不同提取方式使用各自的缓存
+	if got, ok := GetOrSetDynamicIPWithExtract(DynamicIPv4URL, server.URL, AddrExtract{}); !ok || got != "1.2.3.4" {
+		t.Fatalf("expected 1.2.3.4 without extraction, got %q %v", got, ok)
+	}
+	if got, ok := GetOrSetDynamicIPWithExtract(DynamicIPv4Command, "echo 'a 10.0.0.1 b 10.0.0.2'", AddrExtract{Index: 2}); !ok || got != "10.0.0.2" {
+		t.Fatalf("expected second match from command, got %q %v", got, ok)
+	}
+}
@@ -0,0 +1,380 @@
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GatewayAuto 自动发现网关（SSDP 组播 + 系统默认路由）
+const GatewayAuto = "auto"
+
+// 网关协议端口，测试时可替换为本地端口
+var (
+	ssdpPort    = 1900
+	natPMPPort  = 5351
+	gatewayWait = 2 * time.Second // 单个协议的等待时间
+)
+
+const (
+	ssdpMulticastAddr = "239.255.255.250"
+	ssdpSearchTarget  = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+
+	natPMPVersion        = 0
+	natPMPOpExternalAddr = 0
+	natPMPResultOK       = 0
+
+	pcpVersion      = 2
+	pcpOpMap        = 1
+	pcpResultOK     = 0
+	pcpMapLifetime  = 30 // 秒，取得地址后立即删除映射
+	pcpProtocolUDP  = 17
+	pcpRequestSize  = 60
+	pcpResponseSize = 60
+)
+
+// igdServiceTypes 提供 GetExternalIPAddress 的 WAN 连接服务
+var igdServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// GetAddrFromGateway 从局域网网关获取 WAN 口 IPv4 地址，依次尝试 UPnP IGD、NAT-PMP、PCP；
+// gatewayStr 为网关地址，auto 表示自动发现
+func GetAddrFromGateway(gatewayStr string) string {
+	gatewayStr = strings.TrimSpace(gatewayStr)
+	var gateway net.IP
+	if gatewayStr != "" && !strings.EqualFold(gatewayStr, GatewayAuto) {
+		if gateway = net.ParseIP(gatewayStr).To4(); gateway == nil {
+			Warn(LogTypeNetwork, "网关地址无效: %s", gatewayStr)
+			return ""
+		}
+	}
+
+	addr, responder, err := gatewayUPnPExternalIP(gateway)
+	if err == nil {
+		return addr
+	}
+	Warn(LogTypeNetwork, "通过 UPnP IGD 获取网关 WAN 地址失败: %v", err)
+
+	if gateway == nil {
+		gateway = responder
+	}
+	if gateway == nil {
+		if gateway, err = defaultGatewayIPv4(); err != nil {
+			Warn(LogTypeNetwork, "无法确定默认网关: %v", err)
+			return ""
+		}
+	}
+
+	if addr, err = gatewayNATPMPExternalIP(gateway); err == nil {
+		return addr
+	}
+	Warn(LogTypeNetwork, "通过 NAT-PMP 获取网关 WAN 地址失败! 网关: %s, 错误: %v", gateway, err)
+
+	if addr, err = gatewayPCPExternalIP(gateway); err == nil {
+		return addr
+	}
+	Warn(LogTypeNetwork, "通过 PCP 获取网关 WAN 地址失败! 网关: %s, 错误: %v", gateway, err)
+	return ""
+}
+
+// gatewayUPnPExternalIP 通过 SSDP 发现 IGD 并调用 GetExternalIPAddress，同时返回应答 SSDP 的设备地址
+func gatewayUPnPExternalIP(gateway net.IP) (string, net.IP, error) {
+	location, responder, err := ssdpDiscoverIGD(gateway)
+	if err != nil {
+		return "", nil, err
+	}
+	controlURL, serviceType, err := igdControlURL(location)
+	if err != nil {
+		return "", responder, err
+	}
+	addr, err := igdGetExternalIPAddress(controlURL, serviceType)
+	return addr, responder, err
+}
+
+// ssdpDiscoverIGD 发送 M-SEARCH，指定网关时单播，否则组播；返回设备描述地址
+func ssdpDiscoverIGD(gateway net.IP) (string, net.IP, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close()
+
+	target := &net.UDPAddr{IP: net.ParseIP(ssdpMulticastAddr), Port: ssdpPort}
+	if gateway != nil {
+		target.IP = gateway
+	}
+	request := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + net.JoinHostPort(target.IP.String(), strconv.Itoa(ssdpPort)) + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 1\r\n" +
+		"ST: " + ssdpSearchTarget + "\r\n\r\n"
+	if _, err = conn.WriteTo([]byte(request), target); err != nil {
+		return "", nil, err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(gatewayWait))
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return "", nil, fmt.Errorf("未发现 UPnP IGD 设备: %w", err)
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		_ = resp.Body.Close()
+		location := resp.Header.Get("Location")
+		if resp.StatusCode != http.StatusOK || location == "" {
+			continue
+		}
+		return location, from.(*net.UDPAddr).IP.To4(), nil
+	}
+}
+
+// igdDevice 设备描述中用到的部分
+type igdDevice struct {
+	Services []igdService `xml:"serviceList>service"`
+	Devices  []igdDevice  `xml:"deviceList>device"`
+}
+
+type igdService struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+// igdControlURL 读取设备描述，找到 WAN 连接服务的控制地址
+func igdControlURL(location string) (string, string, error) {
+	client := &http.Client{Timeout: gatewayWait}
+	resp, err := client.Get(location)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := GetHTTPResponseOrg(resp, err)
+	if err != nil {
+		return "", "", err
+	}
+
+	var root struct {
+		URLBase string    `xml:"URLBase"`
+		Device  igdDevice `xml:"device"`
+	}
+	if err = xml.Unmarshal(body, &root); err != nil {
+		return "", "", fmt.Errorf("设备描述解析失败: %w", err)
+	}
+	base, err := url.Parse(location)
+	if err != nil {
+		return "", "", err
+	}
+	if root.URLBase != "" {
+		if b, err := url.Parse(root.URLBase); err == nil {
+			base = b
+		}
+	}
+
+	for _, serviceType := range igdServiceTypes {
+		if service, ok := findIGDService(root.Device, serviceType); ok {
+			control, err := base.Parse(service.ControlURL)
+			if err != nil {
+				return "", "", err
+			}
+			return control.String(), serviceType, nil
+		}
+	}
+	return "", "", errors.New("设备未提供 WANIPConnection / WANPPPConnection 服务")
+}
+
+// findIGDService 递归查找指定类型的服务
+func findIGDService(device igdDevice, serviceType string) (igdService, bool) {
+	for _, service := range device.Services {
+		if service.ServiceType == serviceType {
+			return service, true
+		}
+	}
+	for _, child := range device.Devices {
+		if service, ok := findIGDService(child, serviceType); ok {
+			return service, true
+		}
+	}
+	return igdService{}, false
+}
+
+// igdGetExternalIPAddress 调用 SOAP 动作 GetExternalIPAddress
+func igdGetExternalIPAddress(controlURL, serviceType string) (string, error) {
+	envelope := `<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+		`<s:Body><u:GetExternalIPAddress xmlns:u="` + serviceType + `"></u:GetExternalIPAddress></s:Body></s:Envelope>`
+	req, err := http.NewRequest(http.MethodPost, controlURL, strings.NewReader(envelope))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+serviceType+`#GetExternalIPAddress"`)
+
+	client := &http.Client{Timeout: gatewayWait}
+	body, err := GetHTTPResponseOrg(client.Do(req))
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Addr string `xml:"Body>GetExternalIPAddressResponse>NewExternalIPAddress"`
+	}
+	if err = xml.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("SOAP 响应解析失败: %w", err)
+	}
+	ip := net.ParseIP(strings.TrimSpace(result.Addr)).To4()
+	if ip == nil {
+		return "", fmt.Errorf("网关返回的地址无效: %q", result.Addr)
+	}
+	return ip.String(), nil
+}
+
+// gatewayNATPMPExternalIP 发送 NAT-PMP 外部地址请求（RFC 6886）
+func gatewayNATPMPExternalIP(gateway net.IP) (string, error) {
+	resp, err := gatewayExchange(gateway, []byte{natPMPVersion, natPMPOpExternalAddr}, func(b []byte) bool {
+		// 错误响应（如仅支持 PCP 的网关返回 UNSUPP_VERSION）只有 8 字节
+		return len(b) >= 8 && b[0] == natPMPVersion && b[1] == 128+natPMPOpExternalAddr
+	})
+	if err != nil {
+		return "", err
+	}
+	if code := binary.BigEndian.Uint16(resp[2:4]); code != natPMPResultOK {
+		return "", fmt.Errorf("结果码 %d", code)
+	}
+	if len(resp) < 12 {
+		return "", errors.New("响应过短")
+	}
+	return net.IP(resp[8:12]).String(), nil
+}
+
+// gatewayPCPExternalIP 发送短时 PCP MAP 请求（RFC 6887）读取分配的外部地址，随后删除该映射
+func gatewayPCPExternalIP(gateway net.IP) (string, error) {
+	local, err := localIPv4For(gateway)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, 12)
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// 内部端口只用于标识映射，在动态端口范围内随机选取
+	internalPort := 49152 + binary.BigEndian.Uint16(nonce[:2])%16384
+
+	match := func(b []byte) bool {
+		return len(b) >= pcpResponseSize && b[0] == pcpVersion && b[1] == 0x80|pcpOpMap && bytes.Equal(b[24:36], nonce)
+	}
+	resp, err := gatewayExchange(gateway, newPCPMapRequest(local, nonce, internalPort, pcpMapLifetime), match)
+	if err != nil {
+		return "", err
+	}
+	if code := resp[3]; code != pcpResultOK {
+		return "", fmt.Errorf("结果码 %d", code)
+	}
+	ip := net.IP(resp[44:60]).To4()
+	if ip == nil {
+		return "", fmt.Errorf("网关返回的地址不是 IPv4: %s", net.IP(resp[44:60]))
+	}
+
+	// 删除映射（生命周期为 0），失败不影响结果
+	_, _ = gatewayExchange(gateway, newPCPMapRequest(local, nonce, internalPort, 0), match)
+	return ip.String(), nil
+}
+
+// newPCPMapRequest 构造 PCP MAP 请求
+func newPCPMapRequest(client net.IP, nonce []byte, internalPort uint16, lifetime uint32) []byte {
+	b := make([]byte, pcpRequestSize)
+	b[0] = pcpVersion
+	b[1] = pcpOpMap
+	binary.BigEndian.PutUint32(b[4:8], lifetime)
+	copy(b[8:24], client.To16())
+	copy(b[24:36], nonce)
+	b[36] = pcpProtocolUDP
+	binary.BigEndian.PutUint16(b[40:42], internalPort)
+	// 建议外部端口与地址留空（0 / ::ffff:0.0.0.0）
+	copy(b[44:60], net.IPv4zero.To16())
+	return b
+}
+
+// gatewayExchange 向网关 NAT-PMP / PCP 端口发送请求，重发一次，返回满足 match 的响应
+func gatewayExchange(gateway net.IP, request []byte, match func([]byte) bool) ([]byte, error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: gateway, Port: natPMPPort})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 1100)
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err = conn.Write(request); err != nil {
+			return nil, err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(gatewayWait / 2))
+		for {
+			n, readErr := conn.Read(buf)
+			if readErr != nil {
+				err = readErr
+				break
+			}
+			if match(buf[:n]) {
+				return append([]byte(nil), buf[:n]...), nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// localIPv4For 返回访问网关时使用的本机地址（UDP Dial 不会发送数据）
+func localIPv4For(gateway net.IP) (net.IP, error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: gateway, Port: natPMPPort})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
+
+// defaultGatewayIPv4 从 /proc/net/route 读取默认网关（仅 Linux）
+func defaultGatewayIPv4() (net.IP, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseDefaultGateway(f)
+}
+
+// parseDefaultGateway 解析 /proc/net/route，地址为小端十六进制
+func parseDefaultGateway(r io.Reader) (net.IP, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		ip := net.IPv4(raw[3], raw[2], raw[1], raw[0]).To4()
+		if !ip.Equal(net.IPv4zero) {
+			return ip, nil
+		}
+	}
+	return nil, errors.New("路由表中没有默认网关")
+}
@@ -0,0 +1,182 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useLocalGatewayPorts 将 SSDP / NAT-PMP 端口指向本地监听，返回对应的连接
+func useLocalGatewayPorts(t *testing.T) (ssdp, pmp net.PacketConn) {
+	t.Helper()
+	ssdp, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听 udp4: %v", err)
+	}
+	pmp, err = net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听 udp4: %v", err)
+	}
+	oldSSDP, oldPMP, oldWait := ssdpPort, natPMPPort, gatewayWait
+	ssdpPort = ssdp.LocalAddr().(*net.UDPAddr).Port
+	natPMPPort = pmp.LocalAddr().(*net.UDPAddr).Port
+	gatewayWait = 300 * time.Millisecond
+	t.Cleanup(func() {
+		ssdp.Close()
+		pmp.Close()
+		ssdpPort, natPMPPort, gatewayWait = oldSSDP, oldPMP, oldWait
+	})
+	return ssdp, pmp
+}
+
+// serveUDP 在后台处理 UDP 请求，handler 返回 nil 时不应答
+func serveUDP(conn net.PacketConn, handler func([]byte) []byte) {
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := handler(append([]byte(nil), buf[:n]...)); resp != nil {
+				conn.WriteTo(resp, from)
+			}
+		}
+	}()
+}
+
+// newFakeIGD 模拟 IGD 的设备描述与 WANIPConnection 控制接口
+func newFakeIGD(t *testing.T, externalIP string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rootDesc.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <deviceList><device>
+      <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+      <deviceList><device>
+        <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+        <serviceList><service>
+          <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+          <controlURL>/ctl/IPConn</controlURL>
+        </service></serviceList>
+      </device></deviceList>
+    </device></deviceList>
+  </device>
+</root>`)
+	})
+	mux.HandleFunc("/ctl/IPConn", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("SOAPAction") != `"urn:schemas-upnp-org:service:WANIPConnection:1#GetExternalIPAddress"` ||
+			!strings.Contains(string(body), "GetExternalIPAddress") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>
+<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">
+<NewExternalIPAddress>%s</NewExternalIPAddress>
+</u:GetExternalIPAddressResponse></s:Body></s:Envelope>`, externalIP)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetAddrFromGateway_UPnP(t *testing.T) {
+	ssdp, _ := useLocalGatewayPorts(t)
+	igd := newFakeIGD(t, "198.51.100.20")
+	serveUDP(ssdp, func(req []byte) []byte {
+		if !bytes.HasPrefix(req, []byte("M-SEARCH")) || !bytes.Contains(req, []byte(ssdpSearchTarget)) {
+			return nil
+		}
+		return []byte("HTTP/1.1 200 OK\r\nST: " + ssdpSearchTarget + "\r\nLOCATION: " + igd.URL + "/rootDesc.xml\r\n\r\n")
+	})
+
+	if got := GetAddrFromGateway("127.0.0.1"); got != "198.51.100.20" {
+		t.Fatalf("expected 198.51.100.20 from IGD, got %q", got)
+	}
+}
+
+func TestGetAddrFromGateway_FallbackToNATPMP(t *testing.T) {
+	_, pmp := useLocalGatewayPorts(t)
+	serveUDP(pmp, func(req []byte) []byte {
+		if len(req) != 2 || req[0] != natPMPVersion || req[1] != natPMPOpExternalAddr {
+			return nil
+		}
+		resp := make([]byte, 12)
+		resp[1] = 128
+		binary.BigEndian.PutUint32(resp[4:8], 3600)
+		copy(resp[8:12], net.ParseIP("198.51.100.21").To4())
+		return resp
+	})
+
+	if got := GetAddrFromGateway("127.0.0.1"); got != "198.51.100.21" {
+		t.Fatalf("expected 198.51.100.21 from NAT-PMP, got %q", got)
+	}
+}
+
+func TestGetAddrFromGateway_FallbackToPCP(t *testing.T) {
+	_, pmp := useLocalGatewayPorts(t)
+	var (
+		mu        sync.Mutex
+		lifetimes []uint32
+	)
+	serveUDP(pmp, func(req []byte) []byte {
+		switch {
+		case len(req) == 2:
+			// 仅支持 PCP 的网关对 NAT-PMP 请求返回 UNSUPP_VERSION
+			return []byte{natPMPVersion, 128, 0, 1, 0, 0, 0, 0}
+		case len(req) == pcpRequestSize && req[0] == pcpVersion && req[1] == pcpOpMap:
+			mu.Lock()
+			lifetimes = append(lifetimes, binary.BigEndian.Uint32(req[4:8]))
+			mu.Unlock()
+			resp := make([]byte, pcpResponseSize)
+			resp[0] = pcpVersion
+			resp[1] = 0x80 | pcpOpMap
+			copy(resp[4:8], req[4:8])
+			copy(resp[24:44], req[24:44])
+			copy(resp[44:60], net.ParseIP("198.51.100.22").To16())
+			return resp
+		}
+		return nil
+	})
+
+	if got := GetAddrFromGateway("127.0.0.1"); got != "198.51.100.22" {
+		t.Fatalf("expected 198.51.100.22 from PCP, got %q", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lifetimes) != 2 || lifetimes[0] != pcpMapLifetime || lifetimes[1] != 0 {
+		t.Fatalf("expected temporary mapping to be deleted, got lifetimes %v", lifetimes)
+	}
+}
+
+func TestGetAddrFromGateway_InvalidGateway(t *testing.T) {
+	if got := GetAddrFromGateway("not-an-ip"); got != "" {
+		t.Fatalf("expected empty result for invalid gateway, got %q", got)
+	}
+}
+
+func TestParseDefaultGateway(t *testing.T) {
+	route := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\n" +
+		"eth0\t0001A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\n" +
+		"eth0\t00000000\t0101A8C0\t0003\t0\t0\t0\t00000000\n"
+	ip, err := parseDefaultGateway(strings.NewReader(route))
+	if err != nil || ip.String() != "192.168.1.1" {
+		t.Fatalf("expected 192.168.1.1, got %v %v", ip, err)
+	}
+	if _, err = parseDefaultGateway(strings.NewReader("Iface\tDestination\tGateway\n")); err == nil {
+		t.Fatal("expected error without default route")
+	}
+}
@@ -9,6 +9,16 @@ const (
 	DynamicIPv6URL       = "dynamic_ipv6_url"
 	DynamicIPv6Interface = "dynamic_ipv6_interface"
 	DynamicIPv6Command   = "dynamic_ipv6_command"
+	DynamicIPv4Stun      = "dynamic_ipv4_stun" // Value 为逗号分隔的 STUN 服务器
+	DynamicIPv6Stun      = "dynamic_ipv6_stun"
+	DynamicIPv4DNS       = "dynamic_ipv4_dns" // Value 为逗号分隔的 DNS 查询，如 "myip.opendns.com @resolver1.opendns.com"
+	DynamicIPv6DNS       = "dynamic_ipv6_dns"
+	DynamicIPv4Gateway   = "dynamic_ipv4_gateway" // Value 为网关地址或 auto，通过 UPnP IGD / NAT-PMP / PCP 获取 WAN 地址
+	PushedIPv4           = "pushed_ipv4"          // 路由器通过 /nic/update 推送，Value 为主机名
+	PushedIPv6           = "pushed_ipv6"
+	DynamicIPv6Neighbor  = "dynamic_ipv6_neighbor" // Value 为设备 MAC 地址，从内核邻居表获取其全局 IPv6 地址
+	DynamicIPv4DHCP      = "dynamic_ipv4_dhcp"     // Value 为 MAC 地址或主机名[@租约文件]，从 DHCP 租约获取设备地址
+	DynamicIPv6DHCP      = "dynamic_ipv6_dhcp"
 )
 
 // globalIPCache 全局 IP 缓存结构
@@ -44,9 +54,25 @@ func (g *globalIPCache) Clear() {
 	g.cache = make(map[string]string)
 }
 
+// typedCacheKeys 同一个值可能被 IPv4 / IPv6 两种来源共用（网卡名、服务器、主机名），缓存键需带上类型
+var typedCacheKeys = map[string]bool{
+	DynamicIPv4Interface: true,
+	DynamicIPv6Interface: true,
+	DynamicIPv6Neighbor:  true,
+	DynamicIPv4DHCP:      true,
+	DynamicIPv6DHCP:      true,
+	DynamicIPv4Stun:      true,
+	DynamicIPv6Stun:      true,
+	DynamicIPv4DNS:       true,
+	DynamicIPv6DNS:       true,
+	DynamicIPv4Gateway:   true,
+	PushedIPv4:           true,
+	PushedIPv6:           true,
+}
+
 // GetIPCacheKey 的唯一标识
 func GetIPCacheKey(sourceType, sourceValue string) string {
-	if sourceType == DynamicIPv4Interface || sourceType == DynamicIPv6Interface {
+	if typedCacheKeys[sourceType] {
 		return sourceType + ":" + sourceValue
 	}
 	return sourceValue
@@ -54,13 +80,44 @@ func GetIPCacheKey(sourceType, sourceValue string) string {
 
 // GetIPCacheKeyWithRegex 的唯一标识（包含正则表达式）
 func GetIPCacheKeyWithRegex(sourceType, sourceValue, regex string) string {
-	if sourceType == DynamicIPv4Interface || sourceType == DynamicIPv6Interface {
+	if sourceType == DynamicIPv4Interface || SupportsIPv6Policy(sourceType) {
 		if regex != "" {
 			return sourceType + ":" + sourceValue + ":" + regex
 		}
 		return sourceType + ":" + sourceValue
 	}
-	return sourceValue
+	return GetIPCacheKey(sourceType, sourceValue)
+}
+
+// GetIPCacheKeyWithPolicy 的唯一标识（包含正则表达式和 IPv6 选择策略）
+func GetIPCacheKeyWithPolicy(sourceType, sourceValue, regex, policy string) string {
+	key := GetIPCacheKeyWithRegex(sourceType, sourceValue, regex)
+	if SupportsIPv6Policy(sourceType) && policy != "" {
+		return key + ":policy=" + policy
+	}
+	return key
+}
+
+// SupportsIPv6Policy 判断来源类型是否支持 IPv6 选择策略（IPv6 网卡 / 邻居表）
+func SupportsIPv6Policy(sourceType string) bool {
+	return sourceType == DynamicIPv6Interface || sourceType == DynamicIPv6Neighbor
+}
+
+// GetIPCacheKeyWithExtract 的唯一标识（包含接口 / 命令来源的提取方式）
+func GetIPCacheKeyWithExtract(sourceType, sourceValue string, extract AddrExtract) string {
+	if extract.IsZero() || !SupportsExtract(sourceType) {
+		return GetIPCacheKey(sourceType, sourceValue)
+	}
+	return sourceType + ":" + sourceValue + ":" + extract.String()
+}
+
+// SupportsExtract 判断来源类型是否支持 JSON 路径 / 自定义正则 / 第 N 个匹配
+func SupportsExtract(sourceType string) bool {
+	switch sourceType {
+	case DynamicIPv4URL, DynamicIPv6URL, DynamicIPv4Command, DynamicIPv6Command:
+		return true
+	}
+	return false
 }
 
 // ClearGlobalIPCache 清空全局 IP 缓存
@@ -82,60 +139,91 @@ func SetGlobalIPCache(sourceType, sourceValue, ip string) {
 
 // GetOrSetDynamicIPWithCache 获取或设置动态 IP，使用全局缓存避免重复获取
 func GetOrSetDynamicIPWithCache(sourceType, sourceValue string) (string, bool) {
-	sourceKey := GetIPCacheKey(sourceType, sourceValue)
-	addr, ok := GlobalIPCache.Get(sourceKey)
-	if ok {
-		return addr, ok
-	}
-	switch sourceType {
-	case DynamicIPv4URL:
-		addr = GetAddrFromUrl(sourceValue, IPv4)
-	case DynamicIPv6URL:
-		addr = GetAddrFromUrl(sourceValue, IPv6)
-	case DynamicIPv4Interface:
-		addr = GetAddrFromInterface(sourceValue, IPv4)
-	case DynamicIPv6Interface:
-		addr = GetAddrFromInterface(sourceValue, IPv6)
-	case DynamicIPv4Command:
-		addr = GetAddrFromCmd(sourceValue, IPv4)
-	case DynamicIPv6Command:
-		addr = GetAddrFromCmd(sourceValue, IPv6)
-	default:
-		return "", false
-	}
-	if addr != "" {
-		SetGlobalIPCache(sourceType, sourceValue, addr)
-		return addr, true
-	}
-	return "", false
+	return getOrFetchDynamicAddr(GetIPCacheKey(sourceType, sourceValue), sourceType, sourceValue, "", "", AddrExtract{})
 }
 
 // GetOrSetDynamicIPWithCacheAndRegex 获取或设置动态 IP，使用全局缓存避免重复获取（支持正则表达式）
 func GetOrSetDynamicIPWithCacheAndRegex(sourceType, sourceValue, regex string) (string, bool) {
-	sourceKey := GetIPCacheKeyWithRegex(sourceType, sourceValue, regex)
-	addr, ok := GlobalIPCache.Get(sourceKey)
-	if ok {
+	return getOrFetchDynamicAddr(GetIPCacheKeyWithRegex(sourceType, sourceValue, regex), sourceType, sourceValue, regex, "", AddrExtract{})
+}
+
+// GetOrSetDynamicIPWithPolicy 获取或设置动态 IP，使用全局缓存避免重复获取（支持 IPv6 网卡的选择策略）
+func GetOrSetDynamicIPWithPolicy(sourceType, sourceValue, regex, policy string) (string, bool) {
+	if !SupportsIPv6Policy(sourceType) || policy == "" {
+		return GetOrSetDynamicIPWithCacheAndRegex(sourceType, sourceValue, regex)
+	}
+	return getOrFetchDynamicAddr(GetIPCacheKeyWithPolicy(sourceType, sourceValue, regex, policy), sourceType, sourceValue, regex, policy, AddrExtract{})
+}
+
+// GetOrSetDynamicIPWithExtract 获取或设置动态 IP，使用全局缓存避免重复获取（支持接口 / 命令来源的提取方式）
+func GetOrSetDynamicIPWithExtract(sourceType, sourceValue string, extract AddrExtract) (string, bool) {
+	if extract.IsZero() || !SupportsExtract(sourceType) {
+		return GetOrSetDynamicIPWithCache(sourceType, sourceValue)
+	}
+	return getOrFetchDynamicAddr(GetIPCacheKeyWithExtract(sourceType, sourceValue, extract), sourceType, sourceValue, "", "", extract)
+}
+
+// getOrFetchDynamicAddr 推送类型直接读取推送的地址；其它类型先查全局缓存，未命中时获取并写入缓存
+func getOrFetchDynamicAddr(sourceKey, sourceType, sourceValue, regex, policy string, extract AddrExtract) (string, bool) {
+	if IsPushedType(sourceType) {
+		return getPushedAddr(sourceType, sourceValue)
+	}
+	if addr, ok := GlobalIPCache.Get(sourceKey); ok {
 		return addr, ok
 	}
+	addr := fetchDynamicAddr(sourceType, sourceValue, regex, policy, extract)
+	if addr == "" {
+		return "", false
+	}
+	GlobalIPCache.Set(sourceKey, addr)
+	return addr, true
+}
+
+// fetchDynamicAddr 按来源类型获取地址，不读写缓存；新增来源类型只需在此处添加。
+// regex 用于网卡 / 邻居表来源，policy 用于 IPv6 网卡 / 邻居表来源，extract 用于 URL / 命令来源，未知类型返回空
+func fetchDynamicAddr(sourceType, sourceValue, regex, policy string, extract AddrExtract) string {
 	switch sourceType {
 	case DynamicIPv4URL:
-		addr = GetAddrFromUrl(sourceValue, IPv4)
+		return GetAddrFromUrlWithExtract(sourceValue, IPv4, extract)
 	case DynamicIPv6URL:
-		addr = GetAddrFromUrl(sourceValue, IPv6)
+		return GetAddrFromUrlWithExtract(sourceValue, IPv6, extract)
 	case DynamicIPv4Interface:
-		addr = GetAddrFromInterfaceWithRegex(sourceValue, IPv4, regex)
+		return GetAddrFromInterfaceWithRegex(sourceValue, IPv4, regex)
 	case DynamicIPv6Interface:
-		addr = GetAddrFromInterfaceWithRegex(sourceValue, IPv6, regex)
+		if policy != "" {
+			return GetAddrFromInterfaceWithPolicy(sourceValue, regex, policy)
+		}
+		return GetAddrFromInterfaceWithRegex(sourceValue, IPv6, regex)
+	case DynamicIPv6Neighbor:
+		return GetAddrFromNeighbor(sourceValue, regex, policy)
+	case DynamicIPv4DHCP:
+		return GetAddrFromDHCPLease(sourceValue, IPv4)
+	case DynamicIPv6DHCP:
+		return GetAddrFromDHCPLease(sourceValue, IPv6)
 	case DynamicIPv4Command:
-		addr = GetAddrFromCmd(sourceValue, IPv4)
+		return GetAddrFromCmdWithExtract(sourceValue, IPv4, extract)
 	case DynamicIPv6Command:
-		addr = GetAddrFromCmd(sourceValue, IPv6)
-	default:
-		return "", false
+		return GetAddrFromCmdWithExtract(sourceValue, IPv6, extract)
+	case DynamicIPv4Stun:
+		return GetAddrFromStun(sourceValue, IPv4)
+	case DynamicIPv6Stun:
+		return GetAddrFromStun(sourceValue, IPv6)
+	case DynamicIPv4DNS:
+		return GetAddrFromDNS(sourceValue, IPv4)
+	case DynamicIPv6DNS:
+		return GetAddrFromDNS(sourceValue, IPv6)
+	case DynamicIPv4Gateway:
+		return GetAddrFromGateway(sourceValue)
 	}
-	if addr != "" {
-		GlobalIPCache.Set(sourceKey, addr)
-		return addr, true
+	return ""
+}
+
+// getPushedAddr 推送类型直接读取最后一次推送的地址，不写入全局缓存
+func getPushedAddr(sourceType, hostname string) (string, bool) {
+	pushed, ok := GetPushedIP(hostname, PushedAddrType(sourceType))
+	if !ok {
+		Warn(LogTypeSystem, "尚未收到主机 %s 推送的 %s 地址", hostname, getAddrTypeConfig(PushedAddrType(sourceType)).addrTypeName)
+		return "", false
 	}
-	return "", false
+	return pushed.Addr, true
 }
@@ -0,0 +1,133 @@
+package helper
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// IPv6PolicyAuto 推荐策略：排除临时、已弃用地址，优先 EUI-64 和首选生命周期最长的地址
+const IPv6PolicyAuto = "auto"
+
+// IPv6Policy 网卡 IPv6 地址选择策略；候选地址只有 2000::/3 全局单播地址，ULA 与链路本地地址总是不参与
+type IPv6Policy struct {
+	ExcludeTemporary  bool   // 排除临时 / 隐私地址（RFC 4941）
+	ExcludeDeprecated bool   // 排除已弃用地址
+	PreferEUI64       bool   // 优先 EUI-64 接口标识（由 MAC 生成，重启后不变）
+	PreferSuffix      net.IP // 优先指定后缀的地址，如 ::1234
+	PreferLifetime    bool   // 优先剩余首选生命周期最长的地址
+}
+
+// ParseIPv6Policy 解析逗号分隔的策略：auto、no_temporary、no_deprecated、eui64、suffix=::1234、lifetime
+func ParseIPv6Policy(policyStr string) (IPv6Policy, error) {
+	var policy IPv6Policy
+	for _, item := range strings.Split(policyStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, "=")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case IPv6PolicyAuto:
+			policy.ExcludeTemporary = true
+			policy.ExcludeDeprecated = true
+			policy.PreferEUI64 = true
+			policy.PreferLifetime = true
+		case "no_temporary":
+			policy.ExcludeTemporary = true
+		case "no_deprecated":
+			policy.ExcludeDeprecated = true
+		case "eui64":
+			policy.PreferEUI64 = true
+		case "suffix":
+			suffix := net.ParseIP(strings.TrimSpace(value))
+			if suffix == nil || suffix.To4() != nil {
+				return IPv6Policy{}, fmt.Errorf("无效的接口标识后缀: %s", value)
+			}
+			policy.PreferSuffix = suffix
+		case "lifetime":
+			policy.PreferLifetime = true
+		default:
+			return IPv6Policy{}, fmt.Errorf("未知的 IPv6 选择策略: %s", item)
+		}
+	}
+	return policy, nil
+}
+
+// ipv6Candidate 参与策略排序的地址
+type ipv6Candidate struct {
+	addr  string
+	flags AddrFlags
+	score [3]uint64 // 后缀匹配、EUI-64、首选生命周期，依次比较
+}
+
+// apply 按策略过滤并排序网卡的 IPv6 地址，返回新的 NetInterface，Address 与 Flags 仍一一对应
+func (p IPv6Policy) apply(netInterface NetInterface) NetInterface {
+	var candidates []ipv6Candidate
+	for i, addr := range netInterface.Address {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		var flags AddrFlags
+		if i < len(netInterface.Flags) {
+			flags = netInterface.Flags[i]
+		}
+		if (p.ExcludeTemporary && flags.Temporary) || (p.ExcludeDeprecated && flags.Deprecated) {
+			continue
+		}
+		c := ipv6Candidate{addr: addr, flags: flags}
+		if p.PreferSuffix != nil && hasIPv6Suffix(ip, p.PreferSuffix) {
+			c.score[0] = 1
+		}
+		if p.PreferEUI64 && isEUI64(ip) {
+			c.score[1] = 1
+		}
+		if p.PreferLifetime {
+			c.score[2] = uint64(flags.PreferredLft)
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].score, candidates[j].score
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] > b[k]
+			}
+		}
+		return false
+	})
+
+	result := NetInterface{Name: netInterface.Name}
+	for _, c := range candidates {
+		result.Address = append(result.Address, c.addr)
+		result.Flags = append(result.Flags, c.flags)
+	}
+	return result
+}
+
+// isEUI64 接口标识中间两字节为 ff:fe 即由 MAC 地址生成
+func isEUI64(ip net.IP) bool {
+	ip = ip.To16()
+	return ip != nil && ip[11] == 0xff && ip[12] == 0xfe
+}
+
+// hasIPv6Suffix 比较后缀中从第一个非零字节开始的部分，如 ::1234 只比较最后两个字节
+func hasIPv6Suffix(ip, suffix net.IP) bool {
+	ip, suffix = ip.To16(), suffix.To16()
+	start := 0
+	for start < len(suffix) && suffix[start] == 0 {
+		start++
+	}
+	if start == len(suffix) {
+		return false
+	}
+	for i := start; i < len(suffix); i++ {
+		if ip[i] != suffix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIPv6Policy(t *testing.T) {
+	policy, err := ParseIPv6Policy("auto")
+	if err != nil {
+		t.Fatalf("解析 auto 失败: %v", err)
+	}
+	if !policy.ExcludeTemporary || !policy.ExcludeDeprecated ||
+		!policy.PreferEUI64 || !policy.PreferLifetime || policy.PreferSuffix != nil {
+		t.Errorf("auto 策略不完整: %+v", policy)
+	}
+
+	policy, err = ParseIPv6Policy("no_temporary, suffix=::1234")
+	if err != nil {
+		t.Fatalf("解析组合策略失败: %v", err)
+	}
+	if !policy.ExcludeTemporary || policy.ExcludeDeprecated || policy.PreferSuffix.String() != "::1234" {
+		t.Errorf("组合策略解析错误: %+v", policy)
+	}
+
+	for _, invalid := range []string{"suffix=1.2.3.4", "suffix=abc", "newest", "no_ula"} {
+		if _, err := ParseIPv6Policy(invalid); err == nil {
+			t.Errorf("%q 应解析失败", invalid)
+		}
+	}
+}
+
+func TestIPv6PolicyApply(t *testing.T) {
+	netInterface := NetInterface{
+		Name: "eth0",
+		Address: []string{
+			"2001:db8::1c2d:3e4f:5a6b:7c8d", // 临时地址
+			"2001:db8::1234",                // 手动配置的后缀
+			"2001:db8::211:22ff:fe33:4455",  // EUI-64
+			"2001:db8:0:1::9",               // 已弃用
+			"2001:db8:0:2::9",               // 生命周期较长
+		},
+		Flags: []AddrFlags{
+			{Temporary: true, PreferredLft: 86400},
+			{PreferredLft: 3600},
+			{PreferredLft: 3600},
+			{Deprecated: true},
+			{PreferredLft: 7200},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		expected []string
+	}{
+		{"auto", "auto", []string{"2001:db8::211:22ff:fe33:4455", "2001:db8:0:2::9", "2001:db8::1234"}},
+		{"suffix first", "auto,suffix=::1234", []string{"2001:db8::1234", "2001:db8::211:22ff:fe33:4455", "2001:db8:0:2::9"}},
+		{"exclude only keeps order", "no_temporary,no_deprecated", []string{
+			"2001:db8::1234", "2001:db8::211:22ff:fe33:4455", "2001:db8:0:2::9",
+		}},
+		{"lifetime only", "lifetime", []string{
+			"2001:db8::1c2d:3e4f:5a6b:7c8d", "2001:db8:0:2::9", "2001:db8::1234", "2001:db8::211:22ff:fe33:4455", "2001:db8:0:1::9",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseIPv6Policy(tt.policy)
+			if err != nil {
+				t.Fatalf("解析策略失败: %v", err)
+			}
+			got := policy.apply(netInterface)
+			if !reflect.DeepEqual(got.Address, tt.expected) {
+				t.Errorf("apply() = %v, want %v", got.Address, tt.expected)
+			}
+			if len(got.Flags) != len(got.Address) {
+				t.Errorf("Flags 与 Address 数量不一致: %d != %d", len(got.Flags), len(got.Address))
+			}
+		})
+	}
+
+	// 全部被排除时返回空列表
+	policy, _ := ParseIPv6Policy("no_temporary")
+	only := NetInterface{Name: "eth0", Address: []string{"2001:db8::1"}, Flags: []AddrFlags{{Temporary: true}}}
+	if got := policy.apply(only); len(got.Address) != 0 {
+		t.Errorf("expected no address left, got %v", got.Address)
+	}
+}
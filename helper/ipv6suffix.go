@@ -0,0 +1,80 @@
+package helper
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// defaultIPv6PrefixLength 未配置前缀长度时按 /64 组合
+const defaultIPv6PrefixLength = 64
+
+// SupportsIPv6Suffix 判断来源类型能否作为前缀与主机后缀组合（IPv6 网卡 / 接口 / 命令获取）
+func SupportsIPv6Suffix(sourceType string) bool {
+	switch sourceType {
+	case DynamicIPv6Interface, DynamicIPv6URL, DynamicIPv6Command:
+		return true
+	}
+	return false
+}
+
+// GetIPCacheKeyWithSuffix 在来源缓存键后追加主机后缀，同一前缀来源的不同主机互不影响
+func GetIPCacheKeyWithSuffix(sourceKey, sourceType, suffix string, prefixLen int) string {
+	if suffix == "" || !SupportsIPv6Suffix(sourceType) {
+		return sourceKey
+	}
+	if prefixLen <= 0 {
+		prefixLen = defaultIPv6PrefixLength
+	}
+	return sourceKey + ":suffix=" + suffix + "/" + strconv.Itoa(prefixLen)
+}
+
+// validateIPv6Suffix 校验主机后缀为 IPv6 地址、前缀长度为 1~127（0 表示默认 /64）
+func validateIPv6Suffix(suffix string, prefixLen int) error {
+	if host := net.ParseIP(suffix); host == nil || host.To4() != nil {
+		return fmt.Errorf("主机后缀不是有效的 IPv6 地址: %s", suffix)
+	}
+	if prefixLen < 0 || prefixLen >= 128 {
+		return fmt.Errorf("前缀长度无效: %d，应为 1~127", prefixLen)
+	}
+	return nil
+}
+
+// ComposeIPv6 取 prefixAddr 的前 prefixLen 位作为前缀，其余位取自主机后缀，如 2001:db8:1:2::abcd + ::1234:5678 => 2001:db8:1:2::1234:5678
+func ComposeIPv6(prefixAddr, suffix string, prefixLen int) (string, error) {
+	if prefixLen <= 0 {
+		prefixLen = defaultIPv6PrefixLength
+	}
+	if prefixLen >= 128 {
+		return "", fmt.Errorf("前缀长度无效: %d", prefixLen)
+	}
+	prefix := net.ParseIP(prefixAddr)
+	if prefix == nil || prefix.To4() != nil {
+		return "", fmt.Errorf("前缀来源不是有效的 IPv6 地址: %s", prefixAddr)
+	}
+	host := net.ParseIP(suffix)
+	if host == nil || host.To4() != nil {
+		return "", fmt.Errorf("主机后缀不是有效的 IPv6 地址: %s", suffix)
+	}
+
+	mask := net.CIDRMask(prefixLen, 128)
+	result := make(net.IP, net.IPv6len)
+	prefix, host = prefix.To16(), host.To16()
+	for i := range result {
+		result[i] = prefix[i]&mask[i] | host[i]&^mask[i]
+	}
+	return result.String(), nil
+}
+
+// ApplyIPv6Suffix 将来源获取到的地址与主机后缀组合；未配置后缀或来源不支持时原样返回
+func ApplyIPv6Suffix(sourceType, addr, suffix string, prefixLen int) (string, bool) {
+	if suffix == "" || !SupportsIPv6Suffix(sourceType) {
+		return addr, true
+	}
+	composed, err := ComposeIPv6(addr, suffix, prefixLen)
+	if err != nil {
+		Warn(LogTypeNetwork, "组合 IPv6 前缀与主机后缀失败: %v", err)
+		return "", false
+	}
+	return composed, true
+}
@@ -0,0 +1,66 @@
+package helper
+
+import "testing"
+
+func TestComposeIPv6(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		suffix    string
+		prefixLen int
+		expected  string
+	}{
+		{"default /64", "2001:db8:1:2:aaaa:bbbb:cccc:dddd", "::1234:5678", 0, "2001:db8:1:2::1234:5678"},
+		{"suffix bits inside prefix are ignored", "2001:db8:1:2::1", "ffff::1234", 64, "2001:db8:1:2::1234"},
+		{"/56 delegated prefix", "2001:db8:1:2ff::1", "0:0:0:34::1", 56, "2001:db8:1:234::1"},
+		{"/48 keeps subnet from suffix", "2001:db8:1:2::1", "::5:0:0:0:9", 48, "2001:db8:1:5::9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComposeIPv6(tt.prefix, tt.suffix, tt.prefixLen)
+			if err != nil {
+				t.Fatalf("ComposeIPv6() error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ComposeIPv6() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+
+	for _, invalid := range [][3]string{
+		{"1.2.3.4", "::1", ""},
+		{"2001:db8::1", "abc", ""},
+		{"2001:db8::1", "0.0.0.1", ""},
+	} {
+		if _, err := ComposeIPv6(invalid[0], invalid[1], 64); err == nil {
+			t.Errorf("ComposeIPv6(%s, %s) 应返回错误", invalid[0], invalid[1])
+		}
+	}
+	if _, err := ComposeIPv6("2001:db8::1", "::1", 128); err == nil {
+		t.Error("前缀长度 128 应返回错误")
+	}
+}
+
+func TestApplyIPv6Suffix(t *testing.T) {
+	if got, ok := ApplyIPv6Suffix(DynamicIPv6Interface, "2001:db8:1:2::abcd", "::1234:5678", 64); !ok || got != "2001:db8:1:2::1234:5678" {
+		t.Errorf("expected composed address, got %q %v", got, ok)
+	}
+	// 未配置后缀或来源不支持时原样返回
+	if got, ok := ApplyIPv6Suffix(DynamicIPv6Interface, "2001:db8::1", "", 0); !ok || got != "2001:db8::1" {
+		t.Errorf("expected address unchanged without suffix, got %q %v", got, ok)
+	}
+	if got, ok := ApplyIPv6Suffix(DynamicIPv4URL, "1.2.3.4", "::1", 64); !ok || got != "1.2.3.4" {
+		t.Errorf("expected IPv4 source to ignore suffix, got %q %v", got, ok)
+	}
+	if _, ok := ApplyIPv6Suffix(DynamicIPv6URL, "2001:db8::1", "bad", 64); ok {
+		t.Error("expected invalid suffix to fail")
+	}
+
+	key := GetIPCacheKey(DynamicIPv6Interface, "eth0")
+	if GetIPCacheKeyWithSuffix(key, DynamicIPv6Interface, "::1", 64) == GetIPCacheKeyWithSuffix(key, DynamicIPv6Interface, "::2", 64) {
+		t.Error("不同主机后缀的缓存键应不同")
+	}
+	if GetIPCacheKeyWithSuffix(key, DynamicIPv6Interface, "", 0) != key {
+		t.Error("未配置后缀时缓存键应保持不变")
+	}
+}
@@ -0,0 +1,111 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// 邻居表项状态（linux/neighbour.h NUD_*）
+const (
+	nudIncomplete = 0x01
+	nudReachable  = 0x02
+	nudStale      = 0x04
+	nudDelay      = 0x08
+	nudProbe      = 0x10
+	nudFailed     = 0x20
+	nudNoARP      = 0x40
+	nudPermanent  = 0x80
+)
+
+// neighborEntry 内核邻居表中的一条 IPv6 记录
+type neighborEntry struct {
+	IP    net.IP
+	MAC   net.HardwareAddr
+	State uint16
+}
+
+// neighborStateRank 越小越可信：可达 / 静态 < 正在确认 < 已过期
+func neighborStateRank(state uint16) int {
+	switch {
+	case state&(nudReachable|nudPermanent|nudNoARP) != 0:
+		return 0
+	case state&(nudDelay|nudProbe) != 0:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// neighborAddrsByMAC 返回指定 MAC 的全局单播 IPv6 地址，按邻居状态排序
+func neighborAddrsByMAC(entries []neighborEntry, mac net.HardwareAddr) []string {
+	_, ipv6Unicast, _ := net.ParseCIDR("2000::/3")
+	var matched []neighborEntry
+	for _, entry := range entries {
+		if entry.State&(nudIncomplete|nudFailed) != 0 || !bytes.Equal(entry.MAC, mac) {
+			continue
+		}
+		if entry.IP.To4() != nil || !ipv6Unicast.Contains(entry.IP) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return neighborStateRank(matched[i].State) < neighborStateRank(matched[j].State)
+	})
+	addrs := make([]string, 0, len(matched))
+	for _, entry := range matched {
+		addrs = append(addrs, entry.IP.String())
+	}
+	return addrs
+}
+
+// parseNeighborPolicy 解析邻居表来源的选择策略。邻居表项没有临时、已弃用、生命周期等地址属性，
+// 只支持 eui64 和 suffix；auto 仅保留其中的 eui64，显式配置依赖地址属性的选项时返回错误
+func parseNeighborPolicy(policyStr string) (IPv6Policy, error) {
+	policy, err := ParseIPv6Policy(policyStr)
+	if err != nil {
+		return IPv6Policy{}, err
+	}
+	for _, item := range strings.Split(policyStr, ",") {
+		key, _, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key = strings.ToLower(strings.TrimSpace(key)); key {
+		case "no_temporary", "no_deprecated", "lifetime":
+			return IPv6Policy{}, fmt.Errorf("邻居表来源不支持 %s，仅支持 auto、eui64、suffix", key)
+		}
+	}
+	policy.ExcludeTemporary, policy.ExcludeDeprecated, policy.PreferLifetime = false, false, false
+	return policy, nil
+}
+
+// GetAddrFromNeighbor 从内核邻居表获取指定 MAC 地址设备的全局 IPv6 地址，
+// 可选按 IPv6 选择策略过滤排序，再按正则 / @n 选取
+func GetAddrFromNeighbor(macStr string, regexStr string, policyStr string) string {
+	mac, err := net.ParseMAC(macStr)
+	if err != nil {
+		Warn(LogTypeNetwork, "无效的 MAC 地址: %s", macStr)
+		return ""
+	}
+	policy, err := parseNeighborPolicy(policyStr)
+	if err != nil {
+		Warn(LogTypeNetwork, "IPv6 选择策略无效: %v", err)
+		return ""
+	}
+	entries, err := ipv6Neighbors()
+	if err != nil {
+		Warn(LogTypeNetwork, "读取 IPv6 邻居表失败: %v", err)
+		return ""
+	}
+
+	neighbor := NetInterface{Name: mac.String(), Address: neighborAddrsByMAC(entries, mac)}
+	if policyStr != "" {
+		neighbor = policy.apply(neighbor)
+	}
+	if len(neighbor.Address) == 0 {
+		Warn(LogTypeNetwork, "邻居表中没有 MAC %s 的全局 IPv6 地址，请确认设备在线且与本机处于同一链路", mac)
+		return ""
+	}
+	return findAddrInInterfacesWithRegex([]NetInterface{neighbor}, neighbor.Name, regexStr)
+}
@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNeighborAddrsByMAC(t *testing.T) {
+	nas, _ := net.ParseMAC("00:11:32:aa:bb:cc")
+	camera, _ := net.ParseMAC("b0:c5:54:01:02:03")
+	entries := []neighborEntry{
+		{IP: net.ParseIP("2001:db8::a"), MAC: nas, State: nudStale},
+		{IP: net.ParseIP("fe80::211:32ff:feaa:bbcc"), MAC: nas, State: nudReachable}, // 链路本地
+		{IP: net.ParseIP("fd00::b"), MAC: nas, State: nudReachable},                  // ULA
+		{IP: net.ParseIP("2001:db8::211:32ff:feaa:bbcc"), MAC: nas, State: nudReachable},
+		{IP: net.ParseIP("2001:db8::c"), MAC: nas, State: nudFailed},
+		{IP: net.ParseIP("2001:db8::d"), MAC: nas, State: nudDelay},
+		{IP: net.ParseIP("2001:db8::e"), MAC: camera, State: nudReachable},
+	}
+
+	got := neighborAddrsByMAC(entries, nas)
+	expected := []string{"2001:db8::211:32ff:feaa:bbcc", "2001:db8::d", "2001:db8::a"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("neighborAddrsByMAC() = %v, want %v", got, expected)
+	}
+
+	unknown, _ := net.ParseMAC("02:00:00:00:00:01")
+	if got := neighborAddrsByMAC(entries, unknown); len(got) != 0 {
+		t.Errorf("expected no address for unknown MAC, got %v", got)
+	}
+}
+
+func TestGetAddrFromNeighbor_InvalidInput(t *testing.T) {
+	if got := GetAddrFromNeighbor("not-a-mac", "", ""); got != "" {
+		t.Errorf("expected empty result for invalid MAC, got %q", got)
+	}
+	if got := GetAddrFromNeighbor("00:11:22:33:44:55", "", "newest"); got != "" {
+		t.Errorf("expected empty result for invalid policy, got %q", got)
+	}
+}
+
+func TestParseNeighborPolicy(t *testing.T) {
+	policy, err := parseNeighborPolicy("auto,suffix=::b")
+	if err != nil {
+		t.Fatalf("解析 auto 失败: %v", err)
+	}
+	if !policy.PreferEUI64 || policy.PreferSuffix == nil || policy.ExcludeTemporary || policy.ExcludeDeprecated || policy.PreferLifetime {
+		t.Errorf("邻居表的 auto 应只保留 eui64: %+v", policy)
+	}
+	for _, unsupported := range []string{"no_temporary", "eui64, no_deprecated", "LIFETIME"} {
+		if _, err := parseNeighborPolicy(unsupported); err == nil {
+			t.Errorf("%q 应解析失败", unsupported)
+		}
+	}
+}
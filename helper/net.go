@@ -7,12 +7,13 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"os/exec"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/cxbdasheng/dnet/helper/dnsmsg"
 )
 
 // Ipv4Reg IPv4正则
@@ -27,6 +28,8 @@ const (
 
 	// maxResponseBodySize 最大响应体大小（约1MB）
 	maxResponseBodySize = 1024000
+	// dnsQueryTimeout 通过 DNS 查询公网地址的超时时间
+	dnsQueryTimeout = 3 * time.Second
 	// dnsTestTimeout DNS连接测试超时时间
 	dnsTestTimeout = 1 * time.Second
 	// dnsResolverTimeout DNS解析器超时时间
@@ -290,90 +293,285 @@ func GetClientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
+// urlQuorumPrefix URL 列表中的 quorum=M 表示并发请求全部 URL，至少 M 个结果一致才采用
+const urlQuorumPrefix = "quorum="
+
 // GetAddrFromUrl 从 URL 中获取地址
 func GetAddrFromUrl(urlsStr string, addrType string) string {
+	return GetAddrFromUrlWithExtract(urlsStr, addrType, AddrExtract{})
+}
+
+// GetAddrFromUrlWithExtract 从 URL 中按提取方式（JSON 路径、自定义正则、第 N 个匹配）获取地址
+func GetAddrFromUrlWithExtract(urlsStr string, addrType string, extract AddrExtract) string {
 	// 根据地址类型获取配置
 	config := getAddrTypeConfig(addrType)
 
 	// 创建对应的 HTTP 客户端
 	client := CreateNoProxyHTTPClient(config.network)
 
-	// 遍历所有 URL
-	urls := strings.Split(urlsStr, ",")
+	urls, quorum, err := parseURLQuorum(urlsStr)
+	if err != nil {
+		Warn(LogTypeNetwork, "获取 %s 的接口配置错误: %v", config.addrTypeName, err)
+		return ""
+	}
+	if quorum > 0 {
+		return getAddrFromUrlQuorum(client, urls, quorum, config, extract)
+	}
+
+	// 依次尝试所有 URL
 	for _, url := range urls {
-		url = strings.TrimSpace(url)
-		if url == "" {
+		if result := fetchAddrFromUrl(client, url, config, extract); result != "" {
+			// 找到有效地址，返回
+			return result
+		}
+	}
+
+	// 所有 URL 都失败
+	return ""
+}
+
+// parseURLQuorum 拆分逗号分隔的 URL，并取出可选的 quorum=M 设置（0 表示未启用）
+func parseURLQuorum(urlsStr string) ([]string, int, error) {
+	var urls []string
+	quorum := 0
+	for _, item := range strings.Split(urlsStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(item), urlQuorumPrefix) {
+			n, err := strconv.Atoi(item[len(urlQuorumPrefix):])
+			if err != nil || n < 1 {
+				return nil, 0, fmt.Errorf("无效的 %s", item)
+			}
+			quorum = n
 			continue
 		}
+		urls = append(urls, item)
+	}
+	if quorum > len(urls) {
+		return nil, 0, fmt.Errorf("quorum=%d 大于接口数量 %d", quorum, len(urls))
+	}
+	return urls, quorum, nil
+}
 
-		// 发送 HTTP 请求
-		resp, err := client.Get(url)
-		if err != nil {
-			Warn(LogTypeNetwork, "通过接口获取 %s 失败! 接口地址: %s", config.addrTypeName, url)
-			Warn(LogTypeNetwork, "异常信息: %s", err)
+// fetchAddrFromUrl 请求单个 URL 并用正则提取地址，失败时记录日志并返回空
+func fetchAddrFromUrl(client *http.Client, url string, config addrTypeConfig, extract AddrExtract) string {
+	// 发送 HTTP 请求
+	resp, err := client.Get(url)
+	if err != nil {
+		Warn(LogTypeNetwork, "通过接口获取 %s 失败! 接口地址: %s", config.addrTypeName, url)
+		Warn(LogTypeNetwork, "异常信息: %s", err)
+		return ""
+	}
+
+	// 读取响应体
+	lr := io.LimitReader(resp.Body, maxResponseBodySize)
+	body, err := io.ReadAll(lr)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		Warn(LogTypeNetwork, "读取响应失败: %s", err)
+		return ""
+	}
+
+	// 提取地址
+	result, err := extractAddr(string(body), config, extract)
+	if err != nil {
+		if extract.IsZero() {
+			Warn(LogTypeNetwork, "获取 %s 结果失败! 接口: %s, 返回值: %s", config.addrTypeName, url, string(body))
+		} else {
+			Warn(LogTypeNetwork, "按提取规则获取 %s 失败! 接口: %s, 错误: %v, 返回值: %s", config.addrTypeName, url, err, string(body))
+		}
+	}
+	return result
+}
+
+// getAddrFromUrlQuorum 并发请求全部 URL，得票最多且不少于 quorum 的地址胜出；
+// 结果不一致时记录每个地址对应的接口，平票或票数不足时返回空
+func getAddrFromUrlQuorum(client *http.Client, urls []string, quorum int, config addrTypeConfig, extract AddrExtract) string {
+	results := make([]string, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			if ip := net.ParseIP(fetchAddrFromUrl(client, url, config, extract)); ip != nil {
+				results[i] = ip.String()
+			}
+		}(i, url)
+	}
+	wg.Wait()
+
+	votes := make(map[string][]string)
+	var order []string
+	for i, addr := range results {
+		if addr == "" {
 			continue
 		}
+		if _, ok := votes[addr]; !ok {
+			order = append(order, addr)
+		}
+		votes[addr] = append(votes[addr], urls[i])
+	}
+
+	winner, tie := "", false
+	for _, addr := range order {
+		switch {
+		case winner == "" || len(votes[addr]) > len(votes[winner]):
+			winner, tie = addr, false
+		case len(votes[addr]) == len(votes[winner]):
+			tie = true
+		}
+	}
+
+	if len(order) > 1 {
+		details := make([]string, 0, len(order))
+		for _, addr := range order {
+			details = append(details, fmt.Sprintf("%s (%s)", addr, strings.Join(votes[addr], ", ")))
+		}
+		Warn(LogTypeNetwork, "多个接口返回的 %s 不一致: %s", config.addrTypeName, strings.Join(details, "; "))
+	}
+	if winner == "" || tie || len(votes[winner]) < quorum {
+		agreed := 0
+		if winner != "" {
+			agreed = len(votes[winner])
+		}
+		Warn(LogTypeNetwork, "获取 %s 未达到一致要求! 需要 %d/%d 个接口一致, 实际最多 %d 个", config.addrTypeName, quorum, len(urls), agreed)
+		return ""
+	}
+	return winner
+}
+
+// dnsIPQuery 一条查询公网地址的 DNS 请求
+type dnsIPQuery struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+	server string
+}
+
+// parseDNSIPQuery 解析 dig 风格的查询，如 "myip.opendns.com @resolver1.opendns.com"、
+// "whoami.cloudflare TXT CH @1.1.1.1"；类型缺省为 A / AAAA，类别缺省为 IN
+func parseDNSIPQuery(query string, addrType string) (dnsIPQuery, error) {
+	q := dnsIPQuery{qtype: dnsmsg.TypeA, qclass: dnsmsg.ClassINET}
+	if addrType == IPv6 {
+		q.qtype = dnsmsg.TypeAAAA
+	}
+	for _, field := range strings.Fields(query) {
+		switch upper := strings.ToUpper(field); {
+		case strings.HasPrefix(field, "@"):
+			q.server = dnsmsg.WithDefaultPort(field[1:])
+		case upper == "A":
+			q.qtype = dnsmsg.TypeA
+		case upper == "AAAA":
+			q.qtype = dnsmsg.TypeAAAA
+		case upper == "TXT":
+			q.qtype = dnsmsg.TypeTXT
+		case upper == "IN":
+			q.qclass = dnsmsg.ClassINET
+		case upper == "CH" || upper == "CHAOS":
+			q.qclass = dnsmsg.ClassCHAOS
+		case q.name == "":
+			q.name = dnsmsg.Fqdn(field)
+		default:
+			return q, fmt.Errorf("无法识别的参数: %s", field)
+		}
+	}
+	if q.name == "" || q.server == "" {
+		return q, fmt.Errorf("查询需包含域名和 @服务器: %s", query)
+	}
+	return q, nil
+}
 
-		// 读取响应体
-		lr := io.LimitReader(resp.Body, maxResponseBodySize)
-		body, err := io.ReadAll(lr)
-		_ = resp.Body.Close()
+// GetAddrFromDNS 通过 DNS 查询获取公网地址，多个查询用逗号分隔，依次尝试；
+// 与 GetAddrFromUrl 一样强制使用 IPv4 / IPv6 连接解析服务器，返回的即是对应协议的出口地址
+func GetAddrFromDNS(queriesStr string, addrType string) string {
+	config := getAddrTypeConfig(addrType)
+	network := "udp4"
+	if addrType == IPv6 {
+		network = "udp6"
+	}
 
+	for _, query := range strings.Split(queriesStr, ",") {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			continue
+		}
+		q, err := parseDNSIPQuery(query, addrType)
 		if err != nil {
-			Warn(LogTypeNetwork, "读取响应失败: %s", err)
+			Warn(LogTypeNetwork, "DNS 查询配置错误: %v", err)
 			continue
 		}
-
-		// 使用正则提取地址
-		result := config.regex.FindString(string(body))
-		if result == "" {
-			Warn(LogTypeNetwork, "获取 %s 结果失败! 接口: %s, 返回值: %s", config.addrTypeName, url, string(body))
+		addr, err := queryAddrFromDNS(network, q, addrType)
+		if err != nil {
+			Warn(LogTypeNetwork, "通过 DNS 获取 %s 失败! 查询: %s, 错误: %v", config.addrTypeName, query, err)
 			continue
 		}
+		return addr
+	}
+	return ""
+}
 
-		// 找到有效地址，返回
-		return result
+// queryAddrFromDNS 发送查询并从 A / AAAA 或 TXT 应答中取出对应类型的地址
+func queryAddrFromDNS(network string, q dnsIPQuery, addrType string) (string, error) {
+	packed, err := dnsmsg.NewQuery(q.name, q.qtype, q.qclass).Pack()
+	if err != nil {
+		return "", err
+	}
+	raw, err := dnsmsg.Exchange(network, q.server, packed, dnsQueryTimeout)
+	if err != nil {
+		return "", err
+	}
+	resp, err := dnsmsg.Unpack(raw)
+	if err != nil {
+		return "", err
+	}
+	if resp.Rcode != dnsmsg.RcodeSuccess {
+		return "", fmt.Errorf("响应码 %s", dnsmsg.RcodeName(resp.Rcode))
 	}
 
-	// 所有 URL 都失败
-	return ""
+	regex := getRegexByAddrType(addrType)
+	for _, rr := range resp.Answer {
+		var candidate string
+		switch rr.Type {
+		case dnsmsg.TypeA, dnsmsg.TypeAAAA:
+			if ip := rr.IP(); ip != nil {
+				candidate = ip.String()
+			}
+		case dnsmsg.TypeTXT:
+			candidate = regex.FindString(rr.Text())
+		}
+		if ip := net.ParseIP(candidate); ip != nil && (ip.To4() != nil) == (addrType != IPv6) {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("应答中没有 %s 地址", getAddrTypeConfig(addrType).addrTypeName)
 }
 
 // GetAddrFromCmd 从命令输出中获取地址
 func GetAddrFromCmd(cmd string, addrType string) string {
+	return GetAddrFromCmdWithExtract(cmd, addrType, AddrExtract{})
+}
+
+// GetAddrFromCmdWithExtract 从命令输出中按提取方式获取地址
+func GetAddrFromCmdWithExtract(cmd string, addrType string, extract AddrExtract) string {
 	if cmd == "" {
 		Warn(LogTypeNetwork, "命令为空，无法获取地址")
 		return ""
 	}
 
-	// 获取正则表达式
-	regex := getRegexByAddrType(addrType)
-
-	// 根据操作系统选择shell
-	var execCmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		execCmd = exec.Command("powershell", "-Command", cmd)
-	} else {
-		// 优先使用bash，不存在则使用sh
-		_, err := exec.LookPath("bash")
-		if err != nil {
-			execCmd = exec.Command("sh", "-c", cmd)
-		} else {
-			execCmd = exec.Command("bash", "-c", cmd)
-		}
-	}
-
 	// 执行命令
-	out, err := execCmd.CombinedOutput()
+	out, err := newShellCommand(context.Background(), cmd).CombinedOutput()
 	if err != nil {
 		Warn(LogTypeNetwork, "执行命令失败: %s, 错误: %v", cmd, err)
 		return ""
 	}
 
-	// 使用正则提取地址
-	result := regex.FindString(string(out))
-	if result == "" {
-		Warn(LogTypeNetwork, "未能从命令输出中提取%s地址: %s", addrType, cmd)
+	// 提取地址
+	result, err := extractAddr(string(out), getAddrTypeConfig(addrType), extract)
+	if err != nil {
+		Warn(LogTypeNetwork, "未能从命令输出中提取%s地址: %s, 错误: %v", addrType, cmd, err)
 	}
 	return result
 }
@@ -479,6 +677,33 @@ func GetAddrFromInterfaceWithRegex(interfaceName string, addrType string, regexS
 	return result
 }
 
+// GetAddrFromInterfaceWithPolicy 从网络接口按 IPv6 选择策略过滤排序后，再按正则 / @n 选取地址
+func GetAddrFromInterfaceWithPolicy(interfaceName string, regexStr string, policyStr string) string {
+	policy, err := ParseIPv6Policy(policyStr)
+	if err != nil {
+		Warn(LogTypeNetwork, "IPv6 选择策略无效: %v", err)
+		return ""
+	}
+	_, ipv6, err := GetNetInterface()
+	if err != nil {
+		Warn(LogTypeNetwork, "获取网络接口失败: %v", err)
+		return ""
+	}
+	for _, netInterface := range ipv6 {
+		if netInterface.Name != interfaceName {
+			continue
+		}
+		selected := policy.apply(netInterface)
+		if len(selected.Address) == 0 {
+			Warn(LogTypeNetwork, "网卡 %s 没有符合选择策略 %s 的 IPv6 地址", interfaceName, policyStr)
+			return ""
+		}
+		return findAddrInInterfacesWithRegex([]NetInterface{selected}, interfaceName, regexStr)
+	}
+	Warn(LogTypeNetwork, "未找到IPv6接口: %s", interfaceName)
+	return ""
+}
+
 const (
 	httpClientTimeout     = 30 * time.Second
 	dialerTimeout         = 30 * time.Second
@@ -8,6 +8,14 @@ import (
 type NetInterface struct {
 	Name    string
 	Address []string
+	Flags   []AddrFlags // 与 Address 一一对应，仅 IPv6 填充
+}
+
+// AddrFlags IPv6 地址属性，Linux 下通过 netlink 读取，其它平台为零值
+type AddrFlags struct {
+	Temporary    bool   // 临时 / 隐私地址（RFC 4941）
+	Deprecated   bool   // 已弃用，首选生命周期已耗尽
+	PreferredLft uint32 // 剩余首选生命周期（秒），永久地址为 0xffffffff
 }
 
 // GetNetInterface 获得网卡地址
@@ -21,6 +29,7 @@ func GetNetInterface() (ipv4NetInterfaces []NetInterface, ipv6NetInterfaces []Ne
 
 	// https://en.wikipedia.org/wiki/IPv6_address#General_allocation
 	_, ipv6Unicast, _ := net.ParseCIDR("2000::/3")
+	ipv6Flags := ipv6AddrFlags()
 
 	for i := 0; i < len(allNetInterfaces); i++ {
 		if (allNetInterfaces[i].Flags & net.FlagUp) != 0 {
@@ -52,11 +61,16 @@ func GetNetInterface() (ipv4NetInterfaces []NetInterface, ipv6NetInterfaces []Ne
 			}
 
 			if len(ipv6) > 0 {
+				flags := make([]AddrFlags, len(ipv6))
+				for j, addr := range ipv6 {
+					flags[j] = ipv6Flags[addr]
+				}
 				ipv6NetInterfaces = append(
 					ipv6NetInterfaces,
 					NetInterface{
 						Name:    allNetInterfaces[i].Name,
 						Address: ipv6,
+						Flags:   flags,
 					},
 				)
 			}
@@ -2,10 +2,13 @@ package helper
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/cxbdasheng/dnet/helper/dnsmsg"
 )
 
 // TestIpv4Reg 测试 IPv4 正则表达式
@@ -281,6 +284,151 @@ func TestGetAddrFromUrl(t *testing.T) {
 	}
 }
 
+// TestGetAddrFromUrl_Quorum 测试多个接口一致性校验
+func TestGetAddrFromUrl_Quorum(t *testing.T) {
+	echo := func(body string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+	a1 := echo("ip=203.0.113.1")
+	a2 := echo("203.0.113.1\n")
+	portal := echo("<html>captive portal 10.0.0.1</html>")
+	b := echo("198.51.100.1")
+	broken := echo("no address")
+
+	tests := []struct {
+		name     string
+		urlsStr  string
+		expected string
+	}{
+		{"majority wins over misbehaving service", "quorum=2, " + portal + ", " + a1 + ", " + a2, "203.0.113.1"},
+		{"failed URL does not count", "quorum=2, " + broken + ", " + a1 + ", " + a2, "203.0.113.1"},
+		{"not enough agreeing answers", "quorum=3, " + portal + ", " + a1 + ", " + a2, ""},
+		{"tie is rejected", "quorum=1, " + a1 + ", " + b, ""},
+		{"quorum larger than URL count", "quorum=3, " + a1 + ", " + a2, ""},
+		{"without quorum first match wins", portal + ", " + a1, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetAddrFromUrl(tt.urlsStr, IPv4); got != tt.expected {
+				t.Errorf("GetAddrFromUrl() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseURLQuorum 测试 quorum 配置解析
+func TestParseURLQuorum(t *testing.T) {
+	urls, quorum, err := parseURLQuorum(" https://a.example.com, QUORUM=2 ,https://b.example.com,, https://c.example.com")
+	if err != nil || quorum != 2 || len(urls) != 3 || urls[1] != "https://b.example.com" {
+		t.Fatalf("unexpected result: %v %d %v", urls, quorum, err)
+	}
+	for _, invalid := range []string{"quorum=0, https://a.example.com", "quorum=x, https://a.example.com"} {
+		if _, _, err = parseURLQuorum(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+// startFakeDNSResolver 启动本地 DNS 服务：myip.example A/AAAA 返回请求方地址，
+// whoami.example TXT CH 以文本返回请求方地址，其余返回 NXDOMAIN
+func startFakeDNSResolver(t *testing.T, network, addr string) string {
+	t.Helper()
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		t.Skipf("无法监听 %s %s: %v", network, addr, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req, err := dnsmsg.Unpack(buf[:n])
+			if err != nil || len(req.Question) != 1 {
+				continue
+			}
+			q := req.Question[0]
+			resp := &dnsmsg.Message{ID: req.ID, Response: true, Question: req.Question, Rcode: dnsmsg.RcodeNameError}
+			ip := from.(*net.UDPAddr).IP
+			switch {
+			case q.Name == "myip.example." && q.Type == dnsmsg.TypeA && q.Class == dnsmsg.ClassINET && ip.To4() != nil:
+				resp.Rcode = dnsmsg.RcodeSuccess
+				resp.Answer = []dnsmsg.RR{{Name: q.Name, Type: dnsmsg.TypeA, Class: q.Class, Data: ip.To4()}}
+			case q.Name == "myip.example." && q.Type == dnsmsg.TypeAAAA && q.Class == dnsmsg.ClassINET && ip.To4() == nil:
+				resp.Rcode = dnsmsg.RcodeSuccess
+				resp.Answer = []dnsmsg.RR{{Name: q.Name, Type: dnsmsg.TypeAAAA, Class: q.Class, Data: ip.To16()}}
+			case q.Name == "whoami.example." && q.Type == dnsmsg.TypeTXT && q.Class == dnsmsg.ClassCHAOS:
+				resp.Rcode = dnsmsg.RcodeSuccess
+				resp.Answer = []dnsmsg.RR{{Name: q.Name, Type: dnsmsg.TypeTXT, Class: q.Class, Data: dnsmsg.TXTData(ip.String())}}
+			}
+			packed, err := resp.Pack()
+			if err == nil {
+				conn.WriteTo(packed, from)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// TestGetAddrFromDNS 测试通过 DNS 查询获取地址
+func TestGetAddrFromDNS(t *testing.T) {
+	server4 := startFakeDNSResolver(t, "udp4", "127.0.0.1:0")
+
+	tests := []struct {
+		name     string
+		queries  string
+		addrType string
+		expected string
+	}{
+		{"A query", "myip.example @" + server4, IPv4, "127.0.0.1"},
+		{"TXT CH query", "whoami.example TXT CH @" + server4, IPv4, "127.0.0.1"},
+		{"fallback after NXDOMAIN", "nothing.example @" + server4 + ", myip.example @" + server4, IPv4, "127.0.0.1"},
+		{"missing server", "myip.example", IPv4, ""},
+		{"unknown field", "myip.example MX @" + server4, IPv4, ""},
+		{"empty", "", IPv4, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetAddrFromDNS(tt.queries, tt.addrType); got != tt.expected {
+				t.Errorf("GetAddrFromDNS(%q) = %q, want %q", tt.queries, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("IPv6 over udp6", func(t *testing.T) {
+		server6 := startFakeDNSResolver(t, "udp6", "[::1]:0")
+		if got := GetAddrFromDNS("myip.example @"+server6, IPv6); got != "::1" {
+			t.Errorf("expected ::1, got %q", got)
+		}
+		if got := GetAddrFromDNS("whoami.example TXT CH @"+server6, IPv6); got != "::1" {
+			t.Errorf("expected ::1 from TXT, got %q", got)
+		}
+	})
+}
+
+// TestParseDNSIPQuery 测试 DNS 查询配置解析
+func TestParseDNSIPQuery(t *testing.T) {
+	q, err := parseDNSIPQuery("whoami.cloudflare txt ch @2606:4700:4700::1111", IPv4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.name != "whoami.cloudflare." || q.qtype != dnsmsg.TypeTXT || q.qclass != dnsmsg.ClassCHAOS || q.server != "[2606:4700:4700::1111]:53" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	q, err = parseDNSIPQuery("myip.opendns.com @resolver1.opendns.com", IPv6)
+	if err != nil || q.qtype != dnsmsg.TypeAAAA || q.qclass != dnsmsg.ClassINET || q.server != "resolver1.opendns.com:53" {
+		t.Errorf("expected AAAA IN query by default for IPv6, got %+v %v", q, err)
+	}
+}
+
 // TestGetAddrFromCmd 测试从命令获取地址
 func TestGetAddrFromCmd(t *testing.T) {
 	tests := []struct {
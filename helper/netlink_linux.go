@@ -0,0 +1,228 @@
+//go:build linux
+
+package helper
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// rtnetlink 组播组（linux/rtnetlink.h），syscall 包未导出
+const (
+	rtmgrpIPv4IfAddr = 0x10
+	rtmgrpIPv6IfAddr = 0x100
+)
+
+// ifaFlags IFA_FLAGS 属性（linux/if_addr.h），携带 32 位地址标志，syscall 包未导出
+const ifaFlags = 8
+
+// 邻居消息（linux/neighbour.h），syscall 包未导出
+const (
+	sizeofNdMsg = 12 // struct ndmsg { family, pad1, pad2, ifindex, state, flags, type }
+	ndaDst      = 1
+	ndaLLAddr   = 2
+)
+
+// AddrWatcher 通过 rtnetlink 订阅网卡地址的新增 / 删除事件
+type AddrWatcher struct {
+	fd int
+}
+
+// NewAddrWatcher 订阅 IPv4 / IPv6 地址变化的组播组
+func NewAddrWatcher() (*AddrWatcher, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	sa := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtmgrpIPv4IfAddr | rtmgrpIPv6IfAddr,
+	}
+	if err = syscall.Bind(fd, sa); err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+	return &AddrWatcher{fd: fd}, nil
+}
+
+// Run 阻塞读取事件，每个地址变化以网卡名回调 onChange；读取失败时返回错误
+func (w *AddrWatcher) Run(onChange func(ifName string)) error {
+	defer syscall.Close(w.fd)
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := syscall.Recvfrom(w.fd, buf, 0)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.ENOBUFS {
+				// ENOBUFS 表示事件过多被内核丢弃，继续监听即可
+				continue
+			}
+			return err
+		}
+		for _, name := range parseAddrEvents(buf[:n]) {
+			onChange(name)
+		}
+	}
+}
+
+// parseAddrEvents 解析 RTM_NEWADDR / RTM_DELADDR 消息，返回涉及的网卡名
+func parseAddrEvents(b []byte) []string {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for i := range msgs {
+		msg := &msgs[i]
+		if msg.Header.Type != syscall.RTM_NEWADDR && msg.Header.Type != syscall.RTM_DELADDR {
+			continue
+		}
+		if len(msg.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		ifa := (*syscall.IfAddrmsg)(unsafe.Pointer(&msg.Data[0]))
+		name := ""
+		if attrs, err := syscall.ParseNetlinkRouteAttr(msg); err == nil {
+			for _, attr := range attrs {
+				if attr.Attr.Type == syscall.IFA_LABEL {
+					name = string(trimNull(attr.Value))
+				}
+			}
+		}
+		if name == "" {
+			// IPv6 地址没有 IFA_LABEL，按索引查找；网卡已被删除时忽略
+			iface, err := net.InterfaceByIndex(int(ifa.Index))
+			if err != nil {
+				continue
+			}
+			name = iface.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// ipv6AddrFlags 通过 RTM_GETADDR 读取全部 IPv6 地址的属性，以地址字符串为键；读取失败时返回 nil
+func ipv6AddrFlags() map[string]AddrFlags {
+	b, err := syscall.NetlinkRIB(syscall.RTM_GETADDR, syscall.AF_INET6)
+	if err != nil {
+		Warn(LogTypeNetwork, "读取 IPv6 地址属性失败: %v", err)
+		return nil
+	}
+	return parseIPv6AddrFlags(b)
+}
+
+// parseIPv6AddrFlags 解析 RTM_NEWADDR 消息中的标志和 IFA_CACHEINFO 生命周期
+func parseIPv6AddrFlags(b []byte) map[string]AddrFlags {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return nil
+	}
+	result := make(map[string]AddrFlags)
+	for i := range msgs {
+		msg := &msgs[i]
+		if msg.Header.Type != syscall.RTM_NEWADDR || len(msg.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		ifa := (*syscall.IfAddrmsg)(unsafe.Pointer(&msg.Data[0]))
+		if ifa.Family != syscall.AF_INET6 {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(msg)
+		if err != nil {
+			continue
+		}
+		flags := uint32(ifa.Flags)
+		preferred := uint32(0xffffffff)
+		var addr net.IP
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case syscall.IFA_ADDRESS:
+				if len(attr.Value) == net.IPv6len {
+					addr = net.IP(attr.Value)
+				}
+			case ifaFlags:
+				if len(attr.Value) >= 4 {
+					flags = binary.NativeEndian.Uint32(attr.Value)
+				}
+			case syscall.IFA_CACHEINFO:
+				// struct ifa_cacheinfo { ifa_prefered, ifa_valid, cstamp, tstamp }
+				if len(attr.Value) >= 4 {
+					preferred = binary.NativeEndian.Uint32(attr.Value)
+				}
+			}
+		}
+		if addr == nil {
+			continue
+		}
+		result[addr.String()] = AddrFlags{
+			Temporary:    flags&syscall.IFA_F_TEMPORARY != 0,
+			Deprecated:   flags&syscall.IFA_F_DEPRECATED != 0 || preferred == 0,
+			PreferredLft: preferred,
+		}
+	}
+	return result
+}
+
+// ipv6Neighbors 通过 RTM_GETNEIGH 读取内核 IPv6 邻居表（等同于 ip -6 neigh）
+func ipv6Neighbors() ([]neighborEntry, error) {
+	b, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_INET6)
+	if err != nil {
+		return nil, err
+	}
+	return parseNeighbors(b), nil
+}
+
+// parseNeighbors 解析 RTM_NEWNEIGH 消息中的目的地址、链路层地址和状态
+func parseNeighbors(b []byte) []neighborEntry {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return nil
+	}
+	var entries []neighborEntry
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.RTM_NEWNEIGH || len(msg.Data) < sizeofNdMsg {
+			continue
+		}
+		if msg.Data[0] != syscall.AF_INET6 {
+			continue
+		}
+		entry := neighborEntry{State: binary.NativeEndian.Uint16(msg.Data[8:10])}
+		// syscall.ParseNetlinkRouteAttr 不支持邻居消息，手动遍历属性
+		attrs := msg.Data[sizeofNdMsg:]
+		for len(attrs) >= syscall.SizeofRtAttr {
+			attrLen := int(binary.NativeEndian.Uint16(attrs[0:2]))
+			if attrLen < syscall.SizeofRtAttr || attrLen > len(attrs) {
+				break
+			}
+			value := attrs[syscall.SizeofRtAttr:attrLen]
+			switch binary.NativeEndian.Uint16(attrs[2:4]) {
+			case ndaDst:
+				if len(value) == net.IPv6len {
+					entry.IP = net.IP(append([]byte(nil), value...))
+				}
+			case ndaLLAddr:
+				entry.MAC = net.HardwareAddr(append([]byte(nil), value...))
+			}
+			aligned := (attrLen + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+			if aligned > len(attrs) {
+				break
+			}
+			attrs = attrs[aligned:]
+		}
+		if entry.IP != nil && len(entry.MAC) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func trimNull(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
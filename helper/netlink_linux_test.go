@@ -0,0 +1,123 @@
+//go:build linux
+
+package helper
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// buildAddrMessage 构造 RTM_NEWADDR / RTM_DELADDR 消息，label 为空时不带 IFA_LABEL
+func buildAddrMessage(msgType uint16, family uint8, index uint32, label string) []byte {
+	body := make([]byte, syscall.SizeofIfAddrmsg)
+	body[0] = family
+	binary.NativeEndian.PutUint32(body[4:8], index)
+	if label != "" {
+		body = appendRtAttr(body, syscall.IFA_LABEL, append([]byte(label), 0))
+	}
+	return wrapNetlinkMessage(msgType, body)
+}
+
+// appendRtAttr 追加 4 字节对齐的路由属性
+func appendRtAttr(body []byte, attrType uint16, value []byte) []byte {
+	attrLen := syscall.SizeofRtAttr + len(value)
+	attr := make([]byte, (attrLen+3)&^3)
+	binary.NativeEndian.PutUint16(attr[0:2], uint16(attrLen))
+	binary.NativeEndian.PutUint16(attr[2:4], attrType)
+	copy(attr[4:], value)
+	return append(body, attr...)
+}
+
+// wrapNetlinkMessage 为消息体加上 netlink 头
+func wrapNetlinkMessage(msgType uint16, body []byte) []byte {
+	header := make([]byte, syscall.NLMSG_HDRLEN)
+	binary.NativeEndian.PutUint32(header[0:4], uint32(len(header)+len(body)))
+	binary.NativeEndian.PutUint16(header[4:6], msgType)
+	return append(header, body...)
+}
+
+// buildIPv6AddrMessage 构造带 IFA_FLAGS 和 IFA_CACHEINFO 的 IPv6 地址消息
+func buildIPv6AddrMessage(addr string, flags, preferred uint32) []byte {
+	body := make([]byte, syscall.SizeofIfAddrmsg)
+	body[0] = syscall.AF_INET6
+	body = appendRtAttr(body, syscall.IFA_ADDRESS, net.ParseIP(addr).To16())
+	value := make([]byte, 4)
+	binary.NativeEndian.PutUint32(value, flags)
+	body = appendRtAttr(body, ifaFlags, value)
+	cacheInfo := make([]byte, 16)
+	binary.NativeEndian.PutUint32(cacheInfo[0:4], preferred)
+	binary.NativeEndian.PutUint32(cacheInfo[4:8], 0xffffffff)
+	body = appendRtAttr(body, syscall.IFA_CACHEINFO, cacheInfo)
+	return wrapNetlinkMessage(syscall.RTM_NEWADDR, body)
+}
+
+func TestParseAddrEvents(t *testing.T) {
+	loopback, err := net.InterfaceByName("lo")
+	if err != nil {
+		t.Skipf("没有 lo 网卡: %v", err)
+	}
+
+	var b []byte
+	b = append(b, buildAddrMessage(syscall.RTM_NEWADDR, syscall.AF_INET, 999999, "ppp0")...)
+	b = append(b, buildAddrMessage(syscall.RTM_DELADDR, syscall.AF_INET6, uint32(loopback.Index), "")...)
+	b = append(b, buildAddrMessage(syscall.RTM_NEWADDR, syscall.AF_INET6, 999999, "")...) // 已删除的网卡
+	b = append(b, buildAddrMessage(syscall.RTM_NEWLINK, syscall.AF_INET, 1, "eth0")...)
+
+	names := parseAddrEvents(b)
+	if len(names) != 2 || names[0] != "ppp0" || names[1] != "lo" {
+		t.Fatalf("expected [ppp0 lo], got %v", names)
+	}
+}
+
+func TestParseIPv6AddrFlags(t *testing.T) {
+	var b []byte
+	b = append(b, buildIPv6AddrMessage("2001:db8::1", syscall.IFA_F_TEMPORARY, 3600)...)
+	b = append(b, buildIPv6AddrMessage("2001:db8::2", syscall.IFA_F_PERMANENT, 0xffffffff)...)
+	b = append(b, buildIPv6AddrMessage("2001:db8::3", 0, 0)...)
+	b = append(b, buildAddrMessage(syscall.RTM_NEWADDR, syscall.AF_INET, 1, "eth0")...)
+
+	flags := parseIPv6AddrFlags(b)
+	expected := map[string]AddrFlags{
+		"2001:db8::1": {Temporary: true, PreferredLft: 3600},
+		"2001:db8::2": {PreferredLft: 0xffffffff},
+		"2001:db8::3": {Deprecated: true},
+	}
+	if len(flags) != len(expected) {
+		t.Fatalf("expected %d addresses, got %v", len(expected), flags)
+	}
+	for addr, want := range expected {
+		if got := flags[addr]; got != want {
+			t.Errorf("%s: got %+v, want %+v", addr, got, want)
+		}
+	}
+}
+
+// buildNeighMessage 构造 RTM_NEWNEIGH 消息
+func buildNeighMessage(family uint8, state uint16, addr net.IP, mac string) []byte {
+	body := make([]byte, sizeofNdMsg)
+	body[0] = family
+	binary.NativeEndian.PutUint16(body[8:10], state)
+	body = appendRtAttr(body, ndaDst, addr)
+	if mac != "" {
+		hw, _ := net.ParseMAC(mac)
+		body = appendRtAttr(body, ndaLLAddr, hw)
+	}
+	return wrapNetlinkMessage(syscall.RTM_NEWNEIGH, body)
+}
+
+func TestParseNeighbors(t *testing.T) {
+	var b []byte
+	b = append(b, buildNeighMessage(syscall.AF_INET6, nudReachable, net.ParseIP("2001:db8::1").To16(), "00:11:22:33:44:55")...)
+	b = append(b, buildNeighMessage(syscall.AF_INET6, nudIncomplete, net.ParseIP("2001:db8::2").To16(), "")...) // 未解析出 MAC
+	b = append(b, buildNeighMessage(syscall.AF_INET, nudReachable, net.ParseIP("192.0.2.1").To4(), "00:11:22:33:44:55")...)
+
+	entries := parseNeighbors(b)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", entries)
+	}
+	if entries[0].IP.String() != "2001:db8::1" || entries[0].MAC.String() != "00:11:22:33:44:55" || entries[0].State != nudReachable {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+}
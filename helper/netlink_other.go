@@ -0,0 +1,28 @@
+//go:build !linux
+
+package helper
+
+import "errors"
+
+// AddrWatcher 非 Linux 平台不支持监听网卡地址变化
+type AddrWatcher struct{}
+
+// NewAddrWatcher 非 Linux 平台返回错误，调用方仅按间隔同步
+func NewAddrWatcher() (*AddrWatcher, error) {
+	return nil, errors.New("当前平台不支持监听网卡地址变化")
+}
+
+// Run 非 Linux 平台不会被调用
+func (w *AddrWatcher) Run(onChange func(ifName string)) error {
+	return errors.New("当前平台不支持监听网卡地址变化")
+}
+
+// ipv6AddrFlags 非 Linux 平台无法读取地址属性
+func ipv6AddrFlags() map[string]AddrFlags {
+	return nil
+}
+
+// ipv6Neighbors 非 Linux 平台不支持读取邻居表
+func ipv6Neighbors() ([]neighborEntry, error) {
+	return nil, errors.New("当前平台不支持读取 IPv6 邻居表")
+}
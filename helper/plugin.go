@@ -0,0 +1,167 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exec 插件协议：dnet 启动配置的命令，向 stdin 写入一个 JSON 请求，从 stdout 读取一个 JSON 响应。
+// 插件退出码非 0 或响应中 error 非空视为整体失败；stderr 仅用于日志
+const (
+	PluginProtocolVersion = 1
+
+	PluginActionList   = "list"   // 查询域名下的当前记录 / 源站
+	PluginActionUpsert = "upsert" // 创建或更新记录 / 源站
+	PluginActionDelete = "delete" // 删除记录
+
+	PluginStatusUpdated   = "updated"   // 已创建或更新
+	PluginStatusUnchanged = "unchanged" // 远端已是该值
+	PluginStatusDeleted   = "deleted"   // 已删除
+	PluginStatusFailed    = "failed"    // 失败，原因见 error
+)
+
+// PluginTimeoutENV 插件单次调用的超时时间（秒）
+const PluginTimeoutENV = "PLUGIN_TIMEOUT"
+
+// defaultPluginTimeout 未设置 PluginTimeoutENV 时的超时时间
+const defaultPluginTimeout = 30 * time.Second
+
+// PluginRequest 写入插件 stdin 的请求
+type PluginRequest struct {
+	Version  int            `json:"version"`
+	Kind     string         `json:"kind"`   // ddns / dcdn
+	Action   string         `json:"action"` // list / upsert / delete
+	Domain   string         `json:"domain"`
+	TTL      string         `json:"ttl,omitempty"`
+	CDNType  string         `json:"cdn_type,omitempty"`
+	Secret   string         `json:"secret,omitempty"`   // 配置中的 AccessSecret，原样透传
+	Endpoint string         `json:"endpoint,omitempty"` // 配置中的自定义 API 地址，原样透传
+	Records  []PluginRecord `json:"records"`
+}
+
+// PluginRecord 请求与响应中的一条记录（DDNS）或一个源站（DCDN）
+type PluginRecord struct {
+	ID        string `json:"id,omitempty"` // 请求中的记录序号，插件在结果中原样返回，用于区分同类型的多条记录
+	Type      string `json:"type"`         // DDNS 为记录类型 A / AAAA / CNAME / TXT，DCDN 为源站类型
+	Value     string `json:"value,omitempty"`
+	Priority  string `json:"priority,omitempty"`
+	Weight    string `json:"weight,omitempty"`
+	Port      string `json:"port,omitempty"`
+	HttpsPort string `json:"https_port,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+}
+
+// PluginResult 插件对请求中一条记录的处理结果，按 id 与请求记录对应，未返回 id 时按 type 对应
+type PluginResult struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type"`
+	Status   string `json:"status"` // updated / unchanged / deleted / failed
+	OldValue string `json:"old_value,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PluginResponse 插件写入 stdout 的响应
+type PluginResponse struct {
+	Error   string         `json:"error,omitempty"`
+	Records []PluginRecord `json:"records,omitempty"` // 远端当前的记录，list 必填，其它动作可选
+	Results []PluginResult `json:"results,omitempty"` // upsert / delete 的逐条结果
+	CName   string         `json:"cname,omitempty"`   // DCDN：服务分配的 CNAME，有变化时写回配置
+}
+
+// Result 查找请求记录的处理结果：插件返回了 id 时按 id 查找，否则按记录类型查找（兼容不返回 id 的插件）
+func (r PluginResponse) Result(record PluginRecord) (PluginResult, bool) {
+	for _, result := range r.Results {
+		if result.ID != "" {
+			if result.ID == record.ID {
+				return result, true
+			}
+			continue
+		}
+		if strings.EqualFold(result.Type, record.Type) {
+			return result, true
+		}
+	}
+	return PluginResult{}, false
+}
+
+// PluginTimeout 返回插件调用的超时时间
+func PluginTimeout() time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv(PluginTimeoutENV)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultPluginTimeout
+}
+
+// RunPlugin 通过 shell 启动插件命令，写入请求并解析响应，超过 timeout 后终止进程
+func RunPlugin(command string, timeout time.Duration, request PluginRequest) (PluginResponse, error) {
+	if strings.TrimSpace(command) == "" {
+		return PluginResponse{}, errors.New("插件命令为空")
+	}
+	request.Version = PluginProtocolVersion
+	if request.Records == nil {
+		request.Records = []PluginRecord{}
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := newShellCommand(ctx, command)
+	// 插件派生的子进程可能继续占用输出管道，超时后最多再等待 2 秒
+	cmd.WaitDelay = 2 * time.Second
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		Debug(LogTypeSystem, "插件输出 [命令=%s, 动作=%s, stderr=%s]", command, request.Action, msg)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return PluginResponse{}, fmt.Errorf("插件执行超时（%s）", timeout)
+	}
+	if err != nil {
+		if msg := lastLine(stderr.String()); msg != "" {
+			return PluginResponse{}, fmt.Errorf("插件执行失败: %v: %s", err, msg)
+		}
+		return PluginResponse{}, fmt.Errorf("插件执行失败: %w", err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return PluginResponse{}, fmt.Errorf("插件响应不是有效的 JSON: %w", err)
+	}
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// newShellCommand 按操作系统选择 shell 执行命令：Windows 使用 PowerShell，其它系统优先 bash，不存在则使用 sh
+func newShellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "powershell", "-Command", command)
+	}
+	if _, err := exec.LookPath("bash"); err == nil {
+		return exec.CommandContext(ctx, "bash", "-c", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// lastLine 返回输出中最后一个非空行，用于错误信息
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
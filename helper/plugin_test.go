@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePluginScript 在临时目录中写入可执行的插件脚本，返回脚本路径
+func writePluginScript(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("插件脚本测试依赖 sh")
+	}
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPlugin(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+	plugin := writePluginScript(t, `cat > "$1"
+echo '{"results":[{"type":"A","status":"updated","old_value":"1.1.1.1"}],"records":[{"type":"A","value":"1.1.1.1"}]}'
+`)
+
+	response, err := RunPlugin(plugin+" "+requestFile, 5*time.Second, PluginRequest{
+		Kind: "ddns", Action: PluginActionUpsert, Domain: "www.example.com",
+		Records: []PluginRecord{{Type: "A", Value: "2.2.2.2"}},
+	})
+	if err != nil {
+		t.Fatalf("RunPlugin() 返回错误: %v", err)
+	}
+	result, ok := response.Result(PluginRecord{Type: "a"})
+	if !ok || result.Status != PluginStatusUpdated || result.OldValue != "1.1.1.1" {
+		t.Errorf("结果不正确: %+v", response)
+	}
+	if _, ok := response.Result(PluginRecord{Type: "AAAA"}); ok {
+		t.Error("不应找到 AAAA 的结果")
+	}
+
+	// 返回了 id 时按 id 对应同类型的多条记录
+	byID := PluginResponse{Results: []PluginResult{
+		{ID: "0", Type: "A", Status: PluginStatusUnchanged},
+		{ID: "1", Type: "A", Status: PluginStatusUpdated},
+	}}
+	if result, ok := byID.Result(PluginRecord{ID: "1", Type: "A"}); !ok || result.Status != PluginStatusUpdated {
+		t.Errorf("应按 id 找到第二条 A 记录的结果: %+v", result)
+	}
+	if _, ok := byID.Result(PluginRecord{ID: "2", Type: "A"}); ok {
+		t.Error("id 不匹配时不应按类型找到结果")
+	}
+
+	data, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request PluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("插件收到的请求不是有效的 JSON: %s", data)
+	}
+	if request.Version != PluginProtocolVersion || request.Action != PluginActionUpsert || request.Domain != "www.example.com" ||
+		len(request.Records) != 1 || request.Records[0].Value != "2.2.2.2" {
+		t.Errorf("插件收到的请求不正确: %+v", request)
+	}
+}
+
+func TestRunPlugin_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		wantErr string
+	}{
+		{"退出码非 0", "cat >/dev/null\necho 'token invalid' >&2\nexit 3\n", 5 * time.Second, "token invalid"},
+		{"响应不是 JSON", "cat >/dev/null\necho 'ok'\n", 5 * time.Second, "不是有效的 JSON"},
+		{"响应 error 非空", "cat >/dev/null\necho '{\"error\":\"zone not found\"}'\n", 5 * time.Second, "zone not found"},
+		{"超时", "exec sleep 5\n", 200 * time.Millisecond, "超时"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := writePluginScript(t, tt.script)
+			start := time.Now()
+			_, err := RunPlugin(plugin, tt.timeout, PluginRequest{Action: PluginActionList})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("期望错误包含 %q, 实际: %v", tt.wantErr, err)
+			}
+			if time.Since(start) > 4*time.Second {
+				t.Errorf("插件未在超时后终止，耗时 %s", time.Since(start))
+			}
+		})
+	}
+
+	if _, err := RunPlugin("  ", time.Second, PluginRequest{}); err == nil {
+		t.Error("插件命令为空时应返回错误")
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	t.Setenv(PluginTimeoutENV, "")
+	if got := PluginTimeout(); got != defaultPluginTimeout {
+		t.Errorf("默认超时 = %s, 期望 %s", got, defaultPluginTimeout)
+	}
+	t.Setenv(PluginTimeoutENV, "5")
+	if got := PluginTimeout(); got != 5*time.Second {
+		t.Errorf("PluginTimeout() = %s, 期望 5s", got)
+	}
+	t.Setenv(PluginTimeoutENV, "abc")
+	if got := PluginTimeout(); got != defaultPluginTimeout {
+		t.Errorf("无效值应使用默认超时, 实际 %s", got)
+	}
+}
@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// PushedIP 路由器等客户端通过 /nic/update 推送的地址
+type PushedIP struct {
+	Addr     string    `json:"addr"`
+	ClientIP string    `json:"client_ip,omitempty"` // 推送来源
+	Updated  time.Time `json:"updated"`             // 最后一次推送时间
+}
+
+// pushedIPStore 推送地址存储，key=主机名:地址类型；不参与一轮周期的 GlobalIPCache，读取时总是最新值
+type pushedIPStore struct {
+	mu    sync.RWMutex
+	addrs map[string]PushedIP
+}
+
+var pushedIPs = &pushedIPStore{addrs: make(map[string]PushedIP)}
+
+func pushedIPKey(hostname, addrType string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(hostname), ".")) + ":" + addrType
+}
+
+// IsPushedType 判断是否为推送类型
+func IsPushedType(sourceType string) bool {
+	return sourceType == PushedIPv4 || sourceType == PushedIPv6
+}
+
+// PushedAddrType 返回推送类型对应的地址类型
+func PushedAddrType(sourceType string) string {
+	if sourceType == PushedIPv6 {
+		return IPv6
+	}
+	return IPv4
+}
+
+// SetPushedIP 保存推送的地址，返回地址是否发生变化
+func SetPushedIP(hostname, addrType, addr, clientIP string) bool {
+	key := pushedIPKey(hostname, addrType)
+	pushedIPs.mu.Lock()
+	defer pushedIPs.mu.Unlock()
+	old, ok := pushedIPs.addrs[key]
+	pushedIPs.addrs[key] = PushedIP{Addr: addr, ClientIP: clientIP, Updated: time.Now()}
+	return !ok || old.Addr != addr
+}
+
+// GetPushedIP 读取最后一次推送的地址
+func GetPushedIP(hostname, addrType string) (PushedIP, bool) {
+	pushedIPs.mu.RLock()
+	defer pushedIPs.mu.RUnlock()
+	ip, ok := pushedIPs.addrs[pushedIPKey(hostname, addrType)]
+	return ip, ok
+}
+
+// PushedIPs 返回全部推送地址的快照，用于持久化
+func PushedIPs() map[string]PushedIP {
+	pushedIPs.mu.RLock()
+	defer pushedIPs.mu.RUnlock()
+	if len(pushedIPs.addrs) == 0 {
+		return nil
+	}
+	addrs := make(map[string]PushedIP, len(pushedIPs.addrs))
+	for key, ip := range pushedIPs.addrs {
+		addrs[key] = ip
+	}
+	return addrs
+}
+
+// RestorePushedIPs 恢复上次运行保存的推送地址，已在本次运行中推送过的地址不会被覆盖
+func RestorePushedIPs(addrs map[string]PushedIP) {
+	pushedIPs.mu.Lock()
+	defer pushedIPs.mu.Unlock()
+	for key, ip := range addrs {
+		if _, ok := pushedIPs.addrs[key]; !ok {
+			pushedIPs.addrs[key] = ip
+		}
+	}
+}
+
+// ClearPushedIPs 清空推送地址（用于测试）
+func ClearPushedIPs() {
+	pushedIPs.mu.Lock()
+	defer pushedIPs.mu.Unlock()
+	pushedIPs.addrs = make(map[string]PushedIP)
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RegistrySchema 提供商配置说明需实现的方法，注册表据此排序和过滤
+type RegistrySchema interface {
+	SortOrder() int // 界面展示顺序，越小越靠前
+	IsHidden() bool // 不在界面中展示（如模拟测试），但仍可通过配置文件使用
+}
+
+// RegistryEntry 已注册的提供商
+type RegistryEntry[T any, S RegistrySchema] struct {
+	ID     string   // 配置中的 Service 值
+	New    func() T // 构造函数，每轮同步创建新实例
+	Schema S
+}
+
+// Registry DDNS 与 DCDN 共用的提供商注册表，T 为提供商接口，S 为配置说明
+type Registry[T any, S RegistrySchema] struct {
+	name    string // 用于 panic 信息，如 ddns、dcdn
+	mu      sync.RWMutex
+	entries map[string]RegistryEntry[T, S]
+}
+
+// NewRegistry 创建提供商注册表
+func NewRegistry[T any, S RegistrySchema](name string) *Registry[T, S] {
+	return &Registry[T, S]{name: name, entries: make(map[string]RegistryEntry[T, S])}
+}
+
+// Register 注册提供商；缺少 ID、构造函数或重复注册会 panic
+func (r *Registry[T, S]) Register(id string, newFunc func() T, schema S) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == "" || newFunc == nil {
+		panic(r.name + ": 注册提供商缺少 ID 或构造函数")
+	}
+	if _, exists := r.entries[id]; exists {
+		panic(r.name + ": 重复注册提供商 " + id)
+	}
+	r.entries[id] = RegistryEntry[T, S]{ID: id, New: newFunc, Schema: schema}
+}
+
+// Lookup 按 Service 查找已注册的提供商
+func (r *Registry[T, S]) Lookup(id string) (RegistryEntry[T, S], bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.entries[id]
+	return p, ok
+}
+
+// List 返回按展示顺序排列的所有已注册提供商
+func (r *Registry[T, S]) List() []RegistryEntry[T, S] {
+	r.mu.RLock()
+	list := make([]RegistryEntry[T, S], 0, len(r.entries))
+	for _, p := range r.entries {
+		list = append(list, p)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Schema.SortOrder() != list[j].Schema.SortOrder() {
+			return list[i].Schema.SortOrder() < list[j].Schema.SortOrder()
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// JSON 返回供 Web 界面使用的提供商说明（按展示顺序的 JSON 对象，不含隐藏项）
+func (r *Registry[T, S]) JSON() string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, p := range r.List() {
+		if p.Schema.IsHidden() {
+			continue
+		}
+		id, _ := json.Marshal(p.ID)
+		schema, err := json.Marshal(p.Schema)
+		if err != nil {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(id)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// FieldLabel 去掉界面标签末尾的冒号用于错误信息，标签为空时使用默认名称
+func FieldLabel(label, fallback string) string {
+	label = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(label), "：:"))
+	if label == "" {
+		return fallback + " "
+	}
+	return label + " "
+}
@@ -0,0 +1,58 @@
+package helper
+
+import "testing"
+
+type testSchema struct {
+	Name   string `json:"name"`
+	Order  int    `json:"-"`
+	Hidden bool   `json:"-"`
+}
+
+func (s testSchema) SortOrder() int {
+	return s.Order
+}
+
+func (s testSchema) IsHidden() bool {
+	return s.Hidden
+}
+
+// TestRegistry 测试提供商注册表的查找、排序与 JSON 输出
+func TestRegistry(t *testing.T) {
+	r := NewRegistry[string, testSchema]("test")
+	r.Register("b", func() string { return "b" }, testSchema{Name: "B", Order: 2})
+	r.Register("a", func() string { return "a" }, testSchema{Name: "A", Order: 2})
+	r.Register("c", func() string { return "c" }, testSchema{Name: "C", Order: 1})
+	r.Register("mock", func() string { return "mock" }, testSchema{Name: "Mock", Hidden: true})
+
+	if p, ok := r.Lookup("a"); !ok || p.New() != "a" {
+		t.Errorf("查找 a 失败: %+v", p)
+	}
+	if _, ok := r.Lookup("missing"); ok {
+		t.Error("不应找到未注册的提供商")
+	}
+
+	list := r.List()
+	if len(list) != 4 || list[0].ID != "mock" || list[1].ID != "c" || list[2].ID != "a" || list[3].ID != "b" {
+		t.Errorf("排序不正确: %+v", list)
+	}
+	if got, want := r.JSON(), `{"c":{"name":"C"},"a":{"name":"A"},"b":{"name":"B"}}`; got != want {
+		t.Errorf("JSON() = %s, 期望 %s", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	r.Register("a", func() string { return "a" }, testSchema{})
+}
+
+// TestFieldLabel 测试界面标签转换为错误信息中的字段名
+func TestFieldLabel(t *testing.T) {
+	if got := FieldLabel("插件命令：", "AccessKey"); got != "插件命令 " {
+		t.Errorf("FieldLabel() = %q", got)
+	}
+	if got := FieldLabel("", "AccessKey"); got != "AccessKey " {
+		t.Errorf("FieldLabel() = %q", got)
+	}
+}
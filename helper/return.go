@@ -25,6 +25,34 @@ func ReturnError(w http.ResponseWriter, msg string) {
 	}
 }
 
+// ReturnErrorWithStatus 返回错误信息并设置 HTTP 状态码（供 REST API 使用）
+func ReturnErrorWithStatus(w http.ResponseWriter, statusCode int, msg string) {
+	result := &Result{}
+
+	result.Status = false
+	result.Msg = msg
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		Warn(LogTypeSystem, "返回错误响应序列化失败: %v", err)
+	}
+}
+
+// ReturnErrorWithData 返回错误信息并附带数据（如提示前端补充输入）
+func ReturnErrorWithData(w http.ResponseWriter, msg string, data interface{}) {
+	result := &Result{}
+
+	result.Status = false
+	result.Msg = msg
+	result.Data = data
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		Warn(LogTypeSystem, "返回错误响应序列化失败: %v", err)
+	}
+}
+
 // ReturnSuccess 返回成功信息
 func ReturnSuccess(w http.ResponseWriter, msg string, data interface{}) {
 	result := &Result{}
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AddrSource 一条记录 / 源站的地址来源配置，保存配置前据此校验
+type AddrSource struct {
+	Type    string // 来源类型，如 dynamic_ipv4_url
+	Value   string
+	Extract AddrExtract
+	// IPv6 选择策略，仅用于 IPv6 网卡 / 邻居表来源
+	IPv6Policy string
+	// 主机后缀与前缀长度，仅用于可组合前缀的 IPv6 来源
+	IPv6Suffix   string
+	PrefixLength int
+}
+
+// Validate 校验来源的附加配置，避免无效配置保存后每轮同步都获取失败
+func (s AddrSource) Validate() error {
+	if s.Type == DynamicIPv4URL || s.Type == DynamicIPv6URL {
+		if _, _, err := parseURLQuorum(s.Value); err != nil {
+			return err
+		}
+	}
+	if SupportsIPv6Policy(s.Type) && s.IPv6Policy != "" {
+		parse := ParseIPv6Policy
+		if s.Type == DynamicIPv6Neighbor {
+			parse = parseNeighborPolicy
+		}
+		if _, err := parse(s.IPv6Policy); err != nil {
+			return err
+		}
+	}
+	if SupportsIPv6Suffix(s.Type) && s.IPv6Suffix != "" {
+		if err := validateIPv6Suffix(s.IPv6Suffix, s.PrefixLength); err != nil {
+			return err
+		}
+	}
+	if SupportsExtract(s.Type) {
+		if err := s.Extract.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate 校验提取方式：自定义正则能够编译，匹配序号不为负数
+func (e AddrExtract) Validate() error {
+	if e.Regex != "" {
+		if _, err := regexp.Compile(e.Regex); err != nil {
+			return fmt.Errorf("提取正则无效: %v", err)
+		}
+	}
+	if e.Index < 0 {
+		return fmt.Errorf("匹配序号不能为负数: %d", e.Index)
+	}
+	return nil
+}
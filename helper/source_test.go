@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAddrSourceValidate 测试保存前的地址来源校验
+func TestAddrSourceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  AddrSource
+		wantErr string
+	}{
+		{"默认提取方式", AddrSource{Type: DynamicIPv4URL, Value: "https://ip.example.com"}, ""},
+		{"有效提取方式", AddrSource{Type: DynamicIPv4Command, Value: "ip addr", Extract: AddrExtract{Regex: `inet (\S+)/`, Index: 2}}, ""},
+		{"提取正则无效", AddrSource{Type: DynamicIPv6URL, Value: "https://ip.example.com", Extract: AddrExtract{Regex: "("}}, "提取正则无效"},
+		{"匹配序号为负数", AddrSource{Type: DynamicIPv4URL, Value: "https://ip.example.com", Extract: AddrExtract{Index: -1}}, "匹配序号不能为负数"},
+		{"多数一致", AddrSource{Type: DynamicIPv4URL, Value: "https://a.example.com, https://b.example.com, quorum=2"}, ""},
+		{"quorum 无效", AddrSource{Type: DynamicIPv4URL, Value: "https://a.example.com, quorum=x"}, "无效的 quorum=x"},
+		{"quorum 大于接口数量", AddrSource{Type: DynamicIPv6URL, Value: "https://a.example.com, quorum=2"}, "大于接口数量"},
+		{"IPv6 选择策略", AddrSource{Type: DynamicIPv6Interface, Value: "eth0", IPv6Policy: "auto,suffix=::1"}, ""},
+		{"已移除的策略选项", AddrSource{Type: DynamicIPv6Interface, Value: "eth0", IPv6Policy: "no_ula"}, "未知的 IPv6 选择策略"},
+		{"邻居表不支持的策略选项", AddrSource{Type: DynamicIPv6Neighbor, Value: "aa:bb:cc:dd:ee:ff", IPv6Policy: "no_temporary"}, "邻居表来源不支持"},
+		{"邻居表策略", AddrSource{Type: DynamicIPv6Neighbor, Value: "aa:bb:cc:dd:ee:ff", IPv6Policy: "auto"}, ""},
+		{"主机后缀", AddrSource{Type: DynamicIPv6URL, Value: "https://ip.example.com", IPv6Suffix: "::1234:5678", PrefixLength: 56}, ""},
+		{"主机后缀不是 IPv6", AddrSource{Type: DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "1.2.3.4"}, "主机后缀不是有效的 IPv6 地址"},
+		{"前缀长度过大", AddrSource{Type: DynamicIPv6Command, Value: "ip -6 addr", IPv6Suffix: "::1", PrefixLength: 128}, "前缀长度无效"},
+		{"前缀长度为负数", AddrSource{Type: DynamicIPv6Interface, Value: "eth0", IPv6Suffix: "::1", PrefixLength: -1}, "前缀长度无效"},
+		{"不支持提取方式的来源忽略", AddrSource{Type: DynamicIPv4Interface, Value: "eth0", Extract: AddrExtract{Regex: "("}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.source.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("意外错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("期望错误包含 %q, 实际: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package helper
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// STUN 协议常量（RFC 5389）
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingSuccess  = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunHeaderSize      = 20
+	stunAttrMapped      = 0x0001 // MAPPED-ADDRESS（兼容 RFC 3489 旧服务器）
+	stunAttrXorMapped   = 0x0020 // XOR-MAPPED-ADDRESS
+	stunFamilyIPv4      = 0x01
+	stunFamilyIPv6      = 0x02
+	stunDefaultPort     = "3478"
+	stunAttemptTimeout  = 1500 * time.Millisecond
+	stunAttemptsPerHost = 2 // UDP 可能丢包，每个服务器最多发送 2 次
+)
+
+// GetAddrFromStun 通过 STUN 服务器获取 NAT 映射后的公网地址，多个服务器用逗号分隔，依次尝试
+func GetAddrFromStun(serversStr string, addrType string) string {
+	config := getAddrTypeConfig(addrType)
+	network := "udp4"
+	if addrType == IPv6 {
+		network = "udp6"
+	}
+
+	for _, server := range strings.Split(serversStr, ",") {
+		server = normalizeStunServer(server)
+		if server == "" {
+			continue
+		}
+		ip, err := stunQuery(network, server)
+		if err != nil {
+			Warn(LogTypeNetwork, "通过 STUN 获取 %s 失败! 服务器: %s, 错误: %v", config.addrTypeName, server, err)
+			continue
+		}
+		return ip.String()
+	}
+	return ""
+}
+
+// normalizeStunServer 去掉 stun: 前缀，缺省端口时补全 3478
+func normalizeStunServer(server string) string {
+	server = strings.TrimSpace(server)
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "stun://"), "stun:")
+	if server == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		return net.JoinHostPort(strings.Trim(server, "[]"), stunDefaultPort)
+	}
+	return server
+}
+
+// stunQuery 发送 Binding 请求并解析映射地址
+func stunQuery(network, server string) (net.IP, error) {
+	conn, err := net.DialTimeout(network, server, stunAttemptTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	request, txID, err := newStunBindingRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	for attempt := 0; attempt < stunAttemptsPerHost; attempt++ {
+		if _, err = conn.Write(request); err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(stunAttemptTimeout)
+		_ = conn.SetReadDeadline(deadline)
+		for {
+			n, readErr := conn.Read(buf)
+			if readErr != nil {
+				err = readErr
+				break
+			}
+			ip, parseErr := parseStunResponse(buf[:n], txID)
+			if parseErr != nil {
+				// 忽略不属于本次请求的报文，继续等待
+				err = parseErr
+				continue
+			}
+			if (network == "udp4") != (ip.To4() != nil) {
+				return nil, fmt.Errorf("返回的地址类型不匹配: %s", ip)
+			}
+			return ip, nil
+		}
+	}
+	return nil, err
+}
+
+// newStunBindingRequest 构造不带属性的 Binding 请求，返回报文和事务 ID
+func newStunBindingRequest() ([]byte, []byte, error) {
+	msg := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(msg[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint16(msg[2:4], 0)
+	binary.BigEndian.PutUint32(msg[4:8], stunMagicCookie)
+	if _, err := rand.Read(msg[8:20]); err != nil {
+		return nil, nil, err
+	}
+	return msg, msg[8:20], nil
+}
+
+// parseStunResponse 解析 Binding 成功响应，优先使用 XOR-MAPPED-ADDRESS
+func parseStunResponse(msg []byte, txID []byte) (net.IP, error) {
+	if len(msg) < stunHeaderSize {
+		return nil, errors.New("响应过短")
+	}
+	if binary.BigEndian.Uint16(msg[0:2]) != stunBindingSuccess {
+		return nil, fmt.Errorf("非 Binding 成功响应: 0x%04x", binary.BigEndian.Uint16(msg[0:2]))
+	}
+	if binary.BigEndian.Uint32(msg[4:8]) != stunMagicCookie || !bytes.Equal(msg[8:20], txID) {
+		return nil, errors.New("事务 ID 不匹配")
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:4]))
+	if stunHeaderSize+length > len(msg) {
+		return nil, errors.New("响应长度不正确")
+	}
+
+	var mapped net.IP
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+		switch attrType {
+		case stunAttrXorMapped:
+			if ip := decodeStunAddress(value, msg[4:20], true); ip != nil {
+				return ip, nil
+			}
+		case stunAttrMapped:
+			mapped = decodeStunAddress(value, nil, false)
+		}
+		// 属性按 4 字节对齐
+		padded := (attrLen + 3) &^ 3
+		if 4+padded > len(attrs) {
+			break
+		}
+		attrs = attrs[4+padded:]
+	}
+	if mapped != nil {
+		return mapped, nil
+	}
+	return nil, errors.New("响应中没有映射地址")
+}
+
+// decodeStunAddress 解析地址属性；XOR 时 IPv4 与 magic cookie 异或，IPv6 与 cookie+事务 ID 异或
+func decodeStunAddress(value []byte, xorKey []byte, xor bool) net.IP {
+	if len(value) < 4 {
+		return nil
+	}
+	var size int
+	switch value[1] {
+	case stunFamilyIPv4:
+		size = net.IPv4len
+	case stunFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return nil
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if xor {
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+	return ip
+}
@@ -0,0 +1,147 @@
+package helper
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+// startStunResponder 启动本地 STUN 服务，将请求方地址以 XOR-MAPPED-ADDRESS（或 MAPPED-ADDRESS）返回；
+// dropFirst 为 true 时丢弃第一个请求，模拟 UDP 丢包
+func startStunResponder(t *testing.T, network, addr string, xor, dropFirst bool) string {
+	t.Helper()
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		t.Skipf("无法监听 %s %s: %v", network, addr, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		dropped := false
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize || binary.BigEndian.Uint16(buf[0:2]) != stunBindingRequest {
+				continue
+			}
+			if dropFirst && !dropped {
+				dropped = true
+				continue
+			}
+			conn.WriteTo(buildStunResponse(buf[4:20], from.(*net.UDPAddr), xor), from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func buildStunResponse(cookieAndTxID []byte, from *net.UDPAddr, xor bool) []byte {
+	ip := from.IP.To4()
+	family := byte(stunFamilyIPv4)
+	if ip == nil {
+		ip = from.IP.To16()
+		family = stunFamilyIPv6
+	}
+	value := make([]byte, 4+len(ip))
+	value[1] = family
+	port := uint16(from.Port)
+	addr := append(net.IP(nil), ip...)
+	attrType := uint16(stunAttrMapped)
+	if xor {
+		attrType = stunAttrXorMapped
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range addr {
+			addr[i] ^= cookieAndTxID[i]
+		}
+	}
+	binary.BigEndian.PutUint16(value[2:4], port)
+	copy(value[4:], addr)
+
+	// 先放一个未知属性（长度 5，需要填充），验证对齐处理
+	unknown := []byte{0x80, 0x22, 0x00, 0x05, 'd', 'n', 'e', 't', '!', 0, 0, 0}
+	attr := make([]byte, 4, 4+len(value))
+	binary.BigEndian.PutUint16(attr[0:2], attrType)
+	binary.BigEndian.PutUint16(attr[2:4], uint16(len(value)))
+	attr = append(attr, value...)
+
+	body := append(unknown, attr...)
+	msg := make([]byte, stunHeaderSize, stunHeaderSize+len(body))
+	binary.BigEndian.PutUint16(msg[0:2], stunBindingSuccess)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(body)))
+	copy(msg[4:20], cookieAndTxID)
+	return append(msg, body...)
+}
+
+func TestGetAddrFromStun_IPv4(t *testing.T) {
+	server := startStunResponder(t, "udp4", "127.0.0.1:0", true, false)
+
+	// 第一个服务器不可用时继续尝试下一个
+	if got := GetAddrFromStun("stun:127.0.0.1:1, "+server, IPv4); got != "127.0.0.1" {
+		t.Fatalf("expected 127.0.0.1, got %q", got)
+	}
+}
+
+func TestGetAddrFromStun_RetryAndLegacyMappedAddress(t *testing.T) {
+	server := startStunResponder(t, "udp4", "127.0.0.1:0", false, true)
+
+	if got := GetAddrFromStun(server, IPv4); got != "127.0.0.1" {
+		t.Fatalf("expected 127.0.0.1 after retransmit, got %q", got)
+	}
+}
+
+func TestGetAddrFromStun_IPv6(t *testing.T) {
+	server := startStunResponder(t, "udp6", "[::1]:0", true, false)
+
+	if got := GetAddrFromStun(server, IPv6); got != "::1" {
+		t.Fatalf("expected ::1, got %q", got)
+	}
+}
+
+func TestParseStunResponse_RejectsOtherTransaction(t *testing.T) {
+	_, txID, err := newStunBindingRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint32(header[0:4], stunMagicCookie)
+	copy(header[4:], txID)
+	resp := buildStunResponse(header, &net.UDPAddr{IP: net.ParseIP("203.0.113.9"), Port: 5000}, true)
+
+	if ip, err := parseStunResponse(resp, txID); err != nil || ip.String() != "203.0.113.9" {
+		t.Fatalf("expected 203.0.113.9, got %v %v", ip, err)
+	}
+	other := append([]byte(nil), txID...)
+	other[0] ^= 0xff
+	if _, err := parseStunResponse(resp, other); err == nil {
+		t.Fatal("expected mismatched transaction ID to be rejected")
+	}
+}
+
+func TestNormalizeStunServer(t *testing.T) {
+	tests := map[string]string{
+		"stun.example.com":             "stun.example.com:3478",
+		" stun:stun.example.com:19302": "stun.example.com:19302",
+		"stun://[2001:db8::1]":         "[2001:db8::1]:3478",
+		"":                             "",
+	}
+	for in, want := range tests {
+		if got := normalizeStunServer(in); got != want {
+			t.Errorf("normalizeStunServer(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGetOrSetDynamicIPWithCache_Stun(t *testing.T) {
+	ClearGlobalIPCache()
+	t.Cleanup(ClearGlobalIPCache)
+	server := startStunResponder(t, "udp4", "127.0.0.1:0", true, false)
+
+	if got, ok := GetOrSetDynamicIPWithCache(DynamicIPv4Stun, server); !ok || got != "127.0.0.1" {
+		t.Fatalf("expected 127.0.0.1, got %q %v", got, ok)
+	}
+	if got, ok := GlobalIPCache.Get(DynamicIPv4Stun + ":" + server); !ok || got != "127.0.0.1" {
+		t.Fatalf("expected typed cache key to be set, got %q %v", got, ok)
+	}
+}
@@ -0,0 +1,4 @@
+package helper
+
+// VersionENV 当前版本号的环境变量，由 main 在启动时设置
+const VersionENV = "DNET_VERSION"
@@ -0,0 +1,259 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 默认保留策略
+const (
+	DefaultMaxEntries = 5000                // 最多保留的条目数
+	DefaultMaxAge     = 30 * 24 * time.Hour // 最长保留时间
+	DefaultPageSize   = 20
+	MaxPageSize       = 200
+)
+
+// 记录来源
+const (
+	KindDDNS = "DDNS"
+	KindDCDN = "DCDN"
+)
+
+// Entry 单条同步历史
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`             // DDNS / DCDN
+	Provider   string    `json:"provider"`         // 提供商（配置中的 Service）
+	Name       string    `json:"name"`             // 服务名称（自定义名称或域名）
+	Domain     string    `json:"domain"`           // 域名
+	RecordType string    `json:"record_type"`      // DDNS 记录类型或 DCDN 源站类型
+	Source     string    `json:"source,omitempty"` // DCDN 源站配置值（URL、网卡名等）
+	OldValue   string    `json:"old_value"`
+	NewValue   string    `json:"new_value"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Query 查询条件，零值表示不过滤
+type Query struct {
+	Kind     string
+	Domain   string // 域名（不区分大小写，包含匹配）
+	Status   string
+	Since    time.Time
+	Until    time.Time
+	Page     int // 从 1 开始
+	PageSize int
+}
+
+// Page 分页查询结果，按时间倒序
+type Page struct {
+	Total    int     `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Items    []Entry `json:"items"`
+}
+
+// Store 同步历史存储：内存中保存全部条目，追加写入 JSON Lines 文件，
+// 超出保留策略时重写文件
+type Store struct {
+	mu         sync.RWMutex
+	path       string
+	maxEntries int
+	maxAge     time.Duration
+	entries    []Entry // 按时间正序
+	now        func() time.Time
+}
+
+// NewStore 创建历史存储并加载已有文件；path 为空时仅保存在内存中
+func NewStore(path string, maxEntries int, maxAge time.Duration) (*Store, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	s := &Store{
+		path:       path,
+		maxEntries: maxEntries,
+		maxAge:     maxAge,
+		now:        time.Now,
+	}
+	if err := s.load(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// load 读取历史文件，跳过无法解析的行
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			s.entries = append(s.entries, e)
+		}
+	}
+	sort.SliceStable(s.entries, func(i, j int) bool {
+		return s.entries[i].Time.Before(s.entries[j].Time)
+	})
+	if s.prune() {
+		return s.rewrite()
+	}
+	return scanner.Err()
+}
+
+// Add 追加历史条目，Time 为空时使用当前时间
+func (s *Store) Add(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for i := range entries {
+		if entries[i].Time.IsZero() {
+			entries[i].Time = now
+		}
+	}
+	s.entries = append(s.entries, entries...)
+	if s.prune() {
+		return s.rewrite()
+	}
+	return s.appendFile(entries)
+}
+
+// prune 按保留策略删除过期和超量的条目，返回是否有删除
+func (s *Store) prune() bool {
+	cutoff := s.now().Add(-s.maxAge)
+	start := 0
+	for start < len(s.entries) && s.entries[start].Time.Before(cutoff) {
+		start++
+	}
+	if over := len(s.entries) - start - s.maxEntries; over > 0 {
+		start += over
+	}
+	if start == 0 {
+		return false
+	}
+	s.entries = append([]Entry(nil), s.entries[start:]...)
+	return true
+}
+
+func (s *Store) appendFile(entries []Entry) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := encodeLines(entries)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite 重写整个历史文件（先写临时文件再重命名）
+func (s *Store) rewrite() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := encodeLines(s.entries)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func encodeLines(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Query 按条件分页查询，结果按时间倒序
+func (s *Store) Query(q Query) Page {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+	domain := strings.ToLower(strings.TrimSpace(q.Domain))
+
+	s.mu.RLock()
+	matched := make([]Entry, 0)
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		e := s.entries[i]
+		if q.Kind != "" && !strings.EqualFold(e.Kind, q.Kind) {
+			continue
+		}
+		if domain != "" && !strings.Contains(strings.ToLower(e.Domain), domain) {
+			continue
+		}
+		if q.Status != "" && e.Status != q.Status {
+			continue
+		}
+		if !q.Since.IsZero() && e.Time.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && e.Time.After(q.Until) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	s.mu.RUnlock()
+
+	page := Page{Total: len(matched), Page: q.Page, PageSize: q.PageSize, Items: []Entry{}}
+	// 先按页数比较，避免超大页码相乘溢出为负数
+	if q.Page-1 <= len(matched)/q.PageSize {
+		start := (q.Page - 1) * q.PageSize
+		end := start + q.PageSize
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Items = matched[start:end]
+	}
+	return page
+}
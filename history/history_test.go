@@ -0,0 +1,99 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, path string, maxEntries int, maxAge time.Duration, now time.Time) *Store {
+	t.Helper()
+	s, err := NewStore(path, maxEntries, maxAge)
+	if err != nil {
+		t.Fatalf("创建历史存储失败: %v", err)
+	}
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestStoreQueryFiltersAndPagination(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, "", 0, 0, base.Add(time.Hour))
+	s.Add(
+		Entry{Time: base, Kind: KindDDNS, Domain: "a.example.com", RecordType: "A", NewValue: "1.1.1.1", Status: "成功"},
+		Entry{Time: base.Add(time.Minute), Kind: KindDDNS, Domain: "b.example.com", RecordType: "AAAA", Status: "失败", Error: "timeout"},
+		Entry{Time: base.Add(2 * time.Minute), Kind: KindDCDN, Domain: "cdn.example.com", RecordType: "dynamic_ipv4_url", Status: "成功"},
+		Entry{Time: base.Add(3 * time.Minute), Kind: KindDDNS, Domain: "A.example.com", RecordType: "A", NewValue: "2.2.2.2", Status: "成功"},
+	)
+
+	page := s.Query(Query{Domain: "a.example", Status: "成功"})
+	if page.Total != 2 || page.Items[0].NewValue != "2.2.2.2" {
+		t.Fatalf("域名/状态过滤或倒序不正确: %+v", page)
+	}
+
+	page = s.Query(Query{Kind: "dcdn"})
+	if page.Total != 1 || page.Items[0].Domain != "cdn.example.com" {
+		t.Fatalf("类型过滤不正确: %+v", page)
+	}
+
+	page = s.Query(Query{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)})
+	if page.Total != 2 {
+		t.Fatalf("时间范围过滤不正确: %+v", page)
+	}
+
+	page = s.Query(Query{Page: 2, PageSize: 3})
+	if page.Total != 4 || len(page.Items) != 1 || page.Items[0].Time != base {
+		t.Fatalf("分页不正确: %+v", page)
+	}
+	if page = s.Query(Query{Page: 5}); len(page.Items) != 0 || page.Items == nil {
+		t.Fatalf("超出范围的页应返回空数组: %+v", page)
+	}
+	if page = s.Query(Query{Page: 9223372036854775807, PageSize: 3}); len(page.Items) != 0 {
+		t.Fatalf("超大页码应返回空页: %+v", page)
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	s := newTestStore(t, "", 3, 24*time.Hour, now)
+	s.Add(Entry{Time: now.Add(-48 * time.Hour), Domain: "old.example.com"})
+	for i := 0; i < 4; i++ {
+		s.Add(Entry{Time: now.Add(time.Duration(i) * time.Second), Domain: "new.example.com"})
+	}
+
+	page := s.Query(Query{})
+	if page.Total != 3 {
+		t.Fatalf("期望保留 3 条, 实际 %d", page.Total)
+	}
+	if s.Query(Query{Domain: "old"}).Total != 0 {
+		t.Error("过期条目应被清理")
+	}
+}
+
+func TestStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnet.history.jsonl")
+	now := time.Now()
+	s := newTestStore(t, path, 2, 0, now)
+	s.Add(Entry{Domain: "a.example.com", Status: "成功"})
+	s.Add(Entry{Domain: "b.example.com", Status: "成功"})
+
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Fatalf("期望追加写入 2 行: %q %v", data, err)
+	}
+
+	// 超出条数后重写文件
+	s.Add(Entry{Domain: "c.example.com", Status: "失败"})
+	data, _ = os.ReadFile(path)
+	if strings.Count(string(data), "\n") != 2 || strings.Contains(string(data), "a.example.com") {
+		t.Fatalf("超出保留条数后应重写文件: %q", data)
+	}
+
+	reloaded := newTestStore(t, path, 2, 0, now)
+	page := reloaded.Query(Query{})
+	if page.Total != 2 || page.Items[0].Domain != "c.example.com" || page.Items[0].Time.IsZero() {
+		t.Fatalf("重新加载结果不正确: %+v", page)
+	}
+}
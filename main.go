@@ -21,6 +21,7 @@ import (
 	"github.com/cxbdasheng/dnet/ddns"
 	"github.com/cxbdasheng/dnet/helper"
 	"github.com/cxbdasheng/dnet/helper/update"
+	"github.com/cxbdasheng/dnet/history"
 	"github.com/cxbdasheng/dnet/web"
 	"github.com/kardianos/service"
 )
@@ -43,6 +44,9 @@ var serviceType = flag.String("s", "", "Service management (install|uninstall|re
 // 重置密码
 var newPassword = flag.String("resetPassword", "", "Reset password to the one entered")
 
+// 关闭两步验证
+var disable2FA = flag.String("disable2FA", "", "Disable two-factor authentication for the given username")
+
 // 自定义 DNS 服务器
 var customDNS = flag.String("dns", "", "Custom DNS server address, example: 8.8.8.8")
 
@@ -105,7 +109,7 @@ func main() {
 	}
 
 	// 设置版本号
-	os.Setenv(web.VersionEnv, version)
+	os.Setenv(helper.VersionENV, version)
 
 	// 设置端口
 	os.Setenv(config.DNETPort, *listen)
@@ -124,6 +128,15 @@ func main() {
 		}
 		return
 	}
+	// 关闭两步验证（验证器丢失时使用）
+	if *disable2FA != "" {
+		if err := configRepo.DisableTwoFactor(*disable2FA); err == nil {
+			helper.Info(helper.LogTypeSystem, "两步验证已关闭")
+		} else {
+			helper.Fatalf(helper.LogTypeSystem, "关闭两步验证失败: %v\n", err)
+		}
+		return
+	}
 	// 设置自定义DNS
 	if *customDNS != "" {
 		helper.SetDNS(*customDNS)
@@ -174,6 +187,16 @@ func runWebServer() error {
 	return http.Serve(l, mux)
 }
 func run() {
+	// 恢复上次运行的同步状态，避免重启后重复请求服务商
+	syncRunner.UseStateStore(config.NewFileStateStore(config.GetStateFilePath()))
+
+	// 加载同步历史
+	historyStore, err := history.NewStore(config.GetHistoryFilePath(), history.DefaultMaxEntries, history.DefaultMaxAge)
+	if err != nil {
+		helper.Warn(helper.LogTypeSystem, "读取同步历史失败: %v", err)
+	}
+	syncRunner.UseHistory(historyStore)
+	webServer.UseHistory(historyStore)
 
 	if !*noWebService {
 		go func() {
@@ -190,6 +213,10 @@ func run() {
 	// 初始化备用DNS
 	helper.InitBackupDNS(*customDNS)
 
+	// 网卡地址变化（Linux）、Docker 容器启停时立即同步
+	syncRunner.WatchInterfaces()
+	syncRunner.WatchDocker()
+
 	// 等待网络连接
 	syncRunner.RunTimer(intervalProvider())
 }
@@ -0,0 +1,199 @@
+package signer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+
+const (
+	AWSAlgorithm      = "AWS4-HMAC-SHA256"
+	AWSDateTimeFormat = "20060102T150405Z"
+	AWSDateFormat     = "20060102"
+	AWSHeaderDate     = "X-Amz-Date"
+	AWSHeaderToken    = "X-Amz-Security-Token"
+)
+
+// awsUnsignedHeaders 不参与签名的请求头（与官方 SDK 保持一致）
+var awsUnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"content-length":  true,
+}
+
+// AWSSigV4Signer AWS Signature Version 4 签名器
+type AWSSigV4Signer struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // 临时凭证的会话令牌，可为空
+	Region       string // 如 us-east-1
+	Service      string // 如 route53
+	Now          func() time.Time
+}
+
+// NewAWSSigV4Signer 创建 SigV4 签名器
+func NewAWSSigV4Signer(accessKey, secretKey, region, service string) *AWSSigV4Signer {
+	return &AWSSigV4Signer{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		Service:   service,
+		Now:       time.Now,
+	}
+}
+
+// Sign 为请求签名：写入 X-Amz-Date（已存在时沿用）和 Authorization 头
+// payload 为请求体原文，需与实际发送的内容一致
+func (s *AWSSigV4Signer) Sign(r *http.Request, payload []byte) {
+	amzDate := r.Header.Get(AWSHeaderDate)
+	if amzDate == "" {
+		now := time.Now
+		if s.Now != nil {
+			now = s.Now
+		}
+		amzDate = now().UTC().Format(AWSDateTimeFormat)
+		r.Header.Set(AWSHeaderDate, amzDate)
+	}
+	if s.SessionToken != "" {
+		r.Header.Set(AWSHeaderToken, s.SessionToken)
+	}
+	date := amzDate
+	if len(date) >= len(AWSDateFormat) {
+		date = date[:len(AWSDateFormat)]
+	}
+
+	// 1. 规范请求
+	canonicalHeaders, signedHeaders := awsCanonicalHeaders(r)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		awsCanonicalURI(r.URL.EscapedPath()),
+		awsCanonicalQuery(r.URL.RawQuery),
+		canonicalHeaders,
+		signedHeaders,
+		sha256Hex(string(payload)),
+	}, "\n")
+
+	// 2. 待签名字符串
+	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", date, s.Region, s.Service)
+	stringToSign := strings.Join([]string{
+		AWSAlgorithm,
+		amzDate,
+		credentialScope,
+		sha256Hex(canonicalRequest),
+	}, "\n")
+
+	// 3. 派生签名密钥并计算签名
+	signingKey := hmacSha256([]byte("AWS4"+s.SecretKey), date)
+	signingKey = hmacSha256(signingKey, s.Region)
+	signingKey = hmacSha256(signingKey, s.Service)
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	// 4. 写入 Authorization
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		AWSAlgorithm, s.AccessKey, credentialScope, signedHeaders, signature))
+}
+
+// awsCanonicalURI 规范化路径：逐段进行 URI 编码（非 S3 服务对已编码路径再次编码）
+func awsCanonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsCanonicalQuery 规范化查询字符串：按编码后的键、值排序
+func awsCanonicalQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	type pair struct{ k, v string }
+	var pairs []pair
+	for _, part := range strings.Split(rawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		pairs = append(pairs, pair{escape(awsUnescape(k)), escape(awsUnescape(v))})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].k != pairs[j].k {
+			return pairs[i].k < pairs[j].k
+		}
+		return pairs[i].v < pairs[j].v
+	})
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.k + "=" + p.v
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsUnescape 解码查询参数，失败时保留原文
+func awsUnescape(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b, _ := hex.DecodeString(s[i+1 : i+3])
+			buf.WriteByte(b[0])
+			i += 2
+		case c == '+':
+			buf.WriteByte(' ')
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// awsCanonicalHeaders 返回规范请求头和签名头列表，host 总是参与签名
+func awsCanonicalHeaders(r *http.Request) (string, string) {
+	headers := make(map[string]string)
+	for k, values := range r.Header {
+		name := strings.ToLower(k)
+		if awsUnsignedHeaders[name] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	headers["host"] = host
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var canonical strings.Builder
+	for _, k := range keys {
+		canonical.WriteString(k)
+		canonical.WriteByte(':')
+		canonical.WriteString(headers[k])
+		canonical.WriteByte('\n')
+	}
+	return canonical.String(), strings.Join(keys, ";")
+}
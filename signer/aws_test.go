@@ -0,0 +1,118 @@
+package signer
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// AWS SigV4 官方测试套件（aws-sig-v4-test-suite）使用的凭证
+const (
+	awsTestAccessKey = "AKIDEXAMPLE"
+	awsTestSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	awsTestDate      = "20150830T123600Z"
+)
+
+func newAWSTestRequest(t *testing.T, method, url string) *http.Request {
+	t.Helper()
+	r, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("创建请求失败: %v", err)
+	}
+	r.Header.Set(AWSHeaderDate, awsTestDate)
+	return r
+}
+
+func awsSignature(authorization string) string {
+	_, sig, _ := strings.Cut(authorization, "Signature=")
+	return sig
+}
+
+// TestAWSSigV4Signer_TestSuite 官方测试向量
+func TestAWSSigV4Signer_TestSuite(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:   "get-vanilla",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/",
+			want:   "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "post-vanilla",
+			method: http.MethodPost,
+			url:    "https://example.amazonaws.com/",
+			want:   "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want:   "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "get-vanilla-empty-query-key",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/?Param1=value1",
+			want:   "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb",
+		},
+		{
+			name:        "post-x-www-form-urlencoded",
+			method:      http.MethodPost,
+			url:         "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Param1=value1",
+			want:        "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newAWSTestRequest(t, tt.method, tt.url)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			NewAWSSigV4Signer(awsTestAccessKey, awsTestSecretKey, "us-east-1", "service").Sign(r, []byte(tt.body))
+
+			auth := r.Header.Get("Authorization")
+			if got := awsSignature(auth); got != tt.want {
+				t.Errorf("signature = %s, want %s", got, tt.want)
+			}
+			if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=") {
+				t.Errorf("unexpected Authorization: %s", auth)
+			}
+		})
+	}
+}
+
+// TestAWSSigV4Signer_SignedHeaders 签名头包含 host 与 x-amz-*，不包含 User-Agent
+func TestAWSSigV4Signer_SignedHeaders(t *testing.T) {
+	r := newAWSTestRequest(t, http.MethodPost, "https://route53.amazonaws.com/2013-04-01/hostedzone/Z1/rrset/")
+	r.Header.Set("User-Agent", "dnet")
+	r.Header.Set("Content-Type", "application/xml")
+	s := NewAWSSigV4Signer(awsTestAccessKey, awsTestSecretKey, "us-east-1", "route53")
+	s.SessionToken = "session"
+	s.Sign(r, []byte("<xml/>"))
+
+	auth := r.Header.Get("Authorization")
+	if !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected SignedHeaders: %s", auth)
+	}
+	if r.Header.Get(AWSHeaderToken) != "session" {
+		t.Error("expected session token header")
+	}
+}
+
+func TestAWSCanonicalQuery(t *testing.T) {
+	got := awsCanonicalQuery("name=www.example.com.&type=A&maxitems=10&a=b%20c")
+	want := "a=b%20c&maxitems=10&name=www.example.com.&type=A"
+	if got != want {
+		t.Errorf("awsCanonicalQuery() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,349 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/dcdn"
+	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// registerAPIV1Routes 注册版本化 REST 接口，供脚本/自动化工具按条目增删改查
+//
+//	GET    /api/v1/ddns/groups        列出全部 DDNS 配置组（敏感信息脱敏）
+//	POST   /api/v1/ddns/groups        新建配置组，id 为空时自动分配
+//	GET    /api/v1/ddns/groups/{id}   查询单个配置组
+//	PUT    /api/v1/ddns/groups/{id}   创建或整体替换配置组
+//	DELETE /api/v1/ddns/groups/{id}   删除配置组
+//
+// DCDN 对应 /api/v1/dcdn 与 /api/v1/dcdn/{id}；POST /api/v1/sync 立即触发同步
+func (s *Server) registerAPIV1Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/ddns/groups", s.Auth(s.apiListDDNSGroups))
+	mux.HandleFunc("POST /api/v1/ddns/groups", s.Auth(s.apiCreateDDNSGroup))
+	mux.HandleFunc("GET /api/v1/ddns/groups/{id}", s.Auth(s.apiGetDDNSGroup))
+	mux.HandleFunc("PUT /api/v1/ddns/groups/{id}", s.Auth(s.apiPutDDNSGroup))
+	mux.HandleFunc("DELETE /api/v1/ddns/groups/{id}", s.Auth(s.apiDeleteDDNSGroup))
+
+	mux.HandleFunc("GET /api/v1/dcdn", s.Auth(s.apiListDCDN))
+	mux.HandleFunc("POST /api/v1/dcdn", s.Auth(s.apiCreateDCDN))
+	mux.HandleFunc("GET /api/v1/dcdn/{id}", s.Auth(s.apiGetDCDN))
+	mux.HandleFunc("PUT /api/v1/dcdn/{id}", s.Auth(s.apiPutDCDN))
+	mux.HandleFunc("DELETE /api/v1/dcdn/{id}", s.Auth(s.apiDeleteDCDN))
+
+	mux.HandleFunc("POST /api/v1/sync", s.Auth(s.SyncAPI))
+}
+
+// decodeAPIBody 严格解析请求体，未知字段视为错误，避免拼写错误被静默忽略
+func decodeAPIBody(request *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// nextNumericID 返回比现有数字 ID 大 1 的新 ID，与 Web 界面的编号方式一致
+func nextNumericID(ids []string) string {
+	max := 0
+	for _, id := range ids {
+		if n, err := strconv.Atoi(id); err == nil && n > max {
+			max = n
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
+
+// loadConfigForAPI 加载配置，失败时直接写入错误响应
+func (s *Server) loadConfigForAPI(writer http.ResponseWriter, logType helper.LogType) (config.Config, bool) {
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.Error(logType, "获取配置失败: %v", err)
+		helper.ReturnErrorWithStatus(writer, http.StatusInternalServerError, "获取配置失败")
+		return conf, false
+	}
+	return conf, true
+}
+
+// saveConfigForAPI 保存配置，失败时直接写入错误响应
+func (s *Server) saveConfigForAPI(writer http.ResponseWriter, logType helper.LogType, conf *config.Config) bool {
+	if err := s.configRepo.Save(conf); err != nil {
+		helper.Error(logType, "保存配置失败: %v", err)
+		helper.ReturnErrorWithStatus(writer, http.StatusInternalServerError, "保存配置失败")
+		return false
+	}
+	return true
+}
+
+func findDDNSGroup(groups []config.DNSGroup, id string) int {
+	for i := range groups {
+		if groups[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findCDN(cdns []config.CDN, id string) int {
+	for i := range cdns {
+		if cdns[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) apiListDDNSGroups(writer http.ResponseWriter, request *http.Request) {
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDDNS)
+	if !ok {
+		return
+	}
+	groups := make([]config.DNSGroup, 0, len(conf.DDNSConfig.DDNS))
+	for _, group := range conf.DDNSConfig.DDNS {
+		groups = append(groups, config.MaskDNSGroup(group))
+	}
+	helper.ReturnSuccess(writer, "", groups)
+}
+
+func (s *Server) apiGetDDNSGroup(writer http.ResponseWriter, request *http.Request) {
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDDNS)
+	if !ok {
+		return
+	}
+	idx := findDDNSGroup(conf.DDNSConfig.DDNS, request.PathValue("id"))
+	if idx < 0 {
+		helper.ReturnErrorWithStatus(writer, http.StatusNotFound, "配置组不存在")
+		return
+	}
+	helper.ReturnSuccess(writer, "", config.MaskDNSGroup(conf.DDNSConfig.DDNS[idx]))
+}
+
+func (s *Server) apiCreateDDNSGroup(writer http.ResponseWriter, request *http.Request) {
+	var group config.DNSGroup
+	if err := decodeAPIBody(request, &group); err != nil {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求格式错误: "+err.Error())
+		return
+	}
+	s.saveDDNSGroup(writer, strings.TrimSpace(group.ID), group, true)
+}
+
+func (s *Server) apiPutDDNSGroup(writer http.ResponseWriter, request *http.Request) {
+	var group config.DNSGroup
+	if err := decodeAPIBody(request, &group); err != nil {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求格式错误: "+err.Error())
+		return
+	}
+	id := request.PathValue("id")
+	if group.ID != "" && group.ID != id {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求体中的 id 与路径不一致")
+		return
+	}
+	s.saveDDNSGroup(writer, id, group, false)
+}
+
+// saveDDNSGroup 新建或替换单个配置组：恢复脱敏字段 → 校验 → 保存 → 触发同步
+func (s *Server) saveDDNSGroup(writer http.ResponseWriter, id string, group config.DNSGroup, createOnly bool) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDDNS)
+	if !ok {
+		return
+	}
+	groups := conf.DDNSConfig.DDNS
+	if id == "" {
+		ids := make([]string, 0, len(groups))
+		for _, g := range groups {
+			ids = append(ids, g.ID)
+		}
+		id = nextNumericID(ids)
+	}
+	group.ID = id
+
+	idx := findDDNSGroup(groups, id)
+	if idx >= 0 && createOnly {
+		helper.ReturnErrorWithStatus(writer, http.StatusConflict, "配置组已存在: "+id)
+		return
+	}
+
+	// 恢复脱敏字段的原始值（客户端可能回传 GET 得到的脱敏数据）
+	group = config.RestoreSensitiveFieldsForDDNS(config.DDNSConfig{DDNS: []config.DNSGroup{group}}, conf.DDNSConfig).DDNS[0]
+	if err := ddns.ValidateGroup(&group); err != nil {
+		helper.Warn(helper.LogTypeDDNS, "配置校验失败: %v", err)
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	next := make([]config.DNSGroup, len(groups), len(groups)+1)
+	copy(next, groups)
+	if idx >= 0 {
+		next[idx] = group
+	} else {
+		next = append(next, group)
+	}
+	conf.DDNSConfig.DDNS = next
+	if !s.saveConfigForAPI(writer, helper.LogTypeDDNS, &conf) {
+		return
+	}
+	helper.Info(helper.LogTypeDDNS, "通过 API 保存配置组 [ID=%s, 域名=%s]", group.ID, group.Domain)
+	s.syncer.TriggerDDNSSyncAsync()
+
+	helper.ReturnSuccess(writer, "配置保存成功", config.MaskDNSGroup(group))
+}
+
+func (s *Server) apiDeleteDDNSGroup(writer http.ResponseWriter, request *http.Request) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDDNS)
+	if !ok {
+		return
+	}
+	id := request.PathValue("id")
+	idx := findDDNSGroup(conf.DDNSConfig.DDNS, id)
+	if idx < 0 {
+		helper.ReturnErrorWithStatus(writer, http.StatusNotFound, "配置组不存在")
+		return
+	}
+	groups := conf.DDNSConfig.DDNS
+	next := make([]config.DNSGroup, 0, len(groups)-1)
+	next = append(next, groups[:idx]...)
+	conf.DDNSConfig.DDNS = append(next, groups[idx+1:]...)
+	if !s.saveConfigForAPI(writer, helper.LogTypeDDNS, &conf) {
+		return
+	}
+	helper.Info(helper.LogTypeDDNS, "通过 API 删除配置组 [ID=%s, 域名=%s]", id, groups[idx].Domain)
+	s.syncer.TriggerDDNSSyncAsync()
+
+	helper.ReturnSuccess(writer, "配置已删除", nil)
+}
+
+func (s *Server) apiListDCDN(writer http.ResponseWriter, request *http.Request) {
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDCDN)
+	if !ok {
+		return
+	}
+	cdns := make([]config.CDN, 0, len(conf.DCDNConfig.DCDN))
+	for _, cdn := range conf.DCDNConfig.DCDN {
+		cdns = append(cdns, config.MaskCDN(cdn))
+	}
+	helper.ReturnSuccess(writer, "", cdns)
+}
+
+func (s *Server) apiGetDCDN(writer http.ResponseWriter, request *http.Request) {
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDCDN)
+	if !ok {
+		return
+	}
+	idx := findCDN(conf.DCDNConfig.DCDN, request.PathValue("id"))
+	if idx < 0 {
+		helper.ReturnErrorWithStatus(writer, http.StatusNotFound, "配置不存在")
+		return
+	}
+	helper.ReturnSuccess(writer, "", config.MaskCDN(conf.DCDNConfig.DCDN[idx]))
+}
+
+func (s *Server) apiCreateDCDN(writer http.ResponseWriter, request *http.Request) {
+	var cdn config.CDN
+	if err := decodeAPIBody(request, &cdn); err != nil {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求格式错误: "+err.Error())
+		return
+	}
+	s.saveCDN(writer, strings.TrimSpace(cdn.ID), cdn, true)
+}
+
+func (s *Server) apiPutDCDN(writer http.ResponseWriter, request *http.Request) {
+	var cdn config.CDN
+	if err := decodeAPIBody(request, &cdn); err != nil {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求格式错误: "+err.Error())
+		return
+	}
+	id := request.PathValue("id")
+	if cdn.ID != "" && cdn.ID != id {
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "请求体中的 id 与路径不一致")
+		return
+	}
+	s.saveCDN(writer, id, cdn, false)
+}
+
+// saveCDN 新建或替换单个 CDN 配置：恢复脱敏字段 → 校验 → 保存 → 触发同步
+func (s *Server) saveCDN(writer http.ResponseWriter, id string, cdn config.CDN, createOnly bool) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDCDN)
+	if !ok {
+		return
+	}
+	cdns := conf.DCDNConfig.DCDN
+	if id == "" {
+		ids := make([]string, 0, len(cdns))
+		for _, c := range cdns {
+			ids = append(ids, c.ID)
+		}
+		id = nextNumericID(ids)
+	}
+	cdn.ID = id
+
+	idx := findCDN(cdns, id)
+	if idx >= 0 && createOnly {
+		helper.ReturnErrorWithStatus(writer, http.StatusConflict, "配置已存在: "+id)
+		return
+	}
+	// CNAME 由同步流程回填，客户端未提供时保留旧值（域名变化时由 RestoreSensitiveFields 清空）
+	if idx >= 0 && cdn.CName == "" {
+		cdn.CName = cdns[idx].CName
+	}
+
+	// 恢复脱敏字段的原始值（客户端可能回传 GET 得到的脱敏数据）
+	cdn = config.RestoreSensitiveFields(config.DCDNConfig{DCDN: []config.CDN{cdn}}, conf.DCDNConfig).DCDN[0]
+	if err := dcdn.ValidateCDN(&cdn); err != nil {
+		helper.Warn(helper.LogTypeDCDN, "配置校验失败: %v", err)
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	next := make([]config.CDN, len(cdns), len(cdns)+1)
+	copy(next, cdns)
+	if idx >= 0 {
+		next[idx] = cdn
+	} else {
+		next = append(next, cdn)
+	}
+	conf.DCDNConfig.DCDN = next
+	if !s.saveConfigForAPI(writer, helper.LogTypeDCDN, &conf) {
+		return
+	}
+	helper.Info(helper.LogTypeDCDN, "通过 API 保存配置 [ID=%s, 域名=%s]", cdn.ID, cdn.Domain)
+	s.syncer.TriggerDCDNSyncAsync()
+
+	helper.ReturnSuccess(writer, "配置保存成功", config.MaskCDN(cdn))
+}
+
+func (s *Server) apiDeleteDCDN(writer http.ResponseWriter, request *http.Request) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, ok := s.loadConfigForAPI(writer, helper.LogTypeDCDN)
+	if !ok {
+		return
+	}
+	id := request.PathValue("id")
+	idx := findCDN(conf.DCDNConfig.DCDN, id)
+	if idx < 0 {
+		helper.ReturnErrorWithStatus(writer, http.StatusNotFound, "配置不存在")
+		return
+	}
+	cdns := conf.DCDNConfig.DCDN
+	next := make([]config.CDN, 0, len(cdns)-1)
+	next = append(next, cdns[:idx]...)
+	conf.DCDNConfig.DCDN = append(next, cdns[idx+1:]...)
+	if !s.saveConfigForAPI(writer, helper.LogTypeDCDN, &conf) {
+		return
+	}
+	helper.Info(helper.LogTypeDCDN, "通过 API 删除配置 [ID=%s, 域名=%s]", id, cdns[idx].Domain)
+	s.syncer.TriggerDCDNSyncAsync()
+
+	helper.ReturnSuccess(writer, "配置已删除", nil)
+}
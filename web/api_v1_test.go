@@ -0,0 +1,196 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/dcdn"
+	"github.com/cxbdasheng/dnet/ddns"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+type stubSyncer struct {
+	ddnsTriggered int
+	dcdnTriggered int
+	pushedHosts   [][]string
+}
+
+func (s *stubSyncer) TriggerDCDNSyncAsync() { s.dcdnTriggered++ }
+func (s *stubSyncer) TriggerDDNSSyncAsync() { s.ddnsTriggered++ }
+func (s *stubSyncer) TriggerPushedSyncAsync(hostnames []string) {
+	s.pushedHosts = append(s.pushedHosts, hostnames)
+}
+
+// newAPITestServer 构造已登录的测试服务，返回路由与依赖
+func newAPITestServer(t *testing.T, conf config.Config) (*http.ServeMux, *stubRepository, *stubSyncer) {
+	t.Helper()
+	resetAuthStateForTest()
+	t.Cleanup(resetAuthStateForTest)
+	if conf.Username == "" {
+		conf.Username = "admin"
+	}
+	globalSessions.put(Session{Token: "test-token", Username: conf.Username, Expires: time.Now().Add(time.Hour)})
+
+	repo := &stubRepository{conf: conf}
+	syncer := &stubSyncer{}
+	mux := http.NewServeMux()
+	NewServer(repo, syncer).RegisterRoutes(mux)
+	return mux, repo, syncer
+}
+
+func doAPIRequest(mux *http.ServeMux, method, path, body string, loggedIn bool) *httptest.ResponseRecorder {
+	token := ""
+	if loggedIn {
+		token = "test-token"
+	}
+	return serve(mux, newRequestWithCookie(method, path, body, token))
+}
+
+func newRequestWithCookie(method, path, body, token string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: CookieName, Value: token})
+	}
+	return req
+}
+
+func serve(mux *http.ServeMux, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAPIV1_DDNSGroupCRUD(t *testing.T) {
+	mux, repo, syncer := newAPITestServer(t, config.Config{
+		DDNSConfig: config.DDNSConfig{DDNS: []config.DNSGroup{{
+			ID: "1", Domain: "a.example.com", Service: ddns.ProviderAliDNS,
+			AccessKey: "LTAI-original-key", AccessSecret: "original-secret-value",
+		}}},
+	})
+
+	// 列表中的敏感字段已脱敏
+	rec := doAPIRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", true)
+	var listed []config.DNSGroup
+	raw, _ := json.Marshal(decodeResult(t, rec).Data)
+	json.Unmarshal(raw, &listed)
+	if len(listed) != 1 || listed[0].AccessSecret == "original-secret-value" {
+		t.Fatalf("expected masked list, got %+v", listed)
+	}
+
+	// 回传脱敏值更新，原始密钥应被保留
+	listed[0].Domain = "b.example.com"
+	body, _ := json.Marshal(listed[0])
+	rec = doAPIRequest(mux, http.MethodPut, "/api/v1/ddns/groups/1", string(body), true)
+	if result := decodeResult(t, rec); rec.Code != http.StatusOK || !result.Status {
+		t.Fatalf("PUT failed: %d %+v", rec.Code, result)
+	}
+	if got := repo.conf.DDNSConfig.DDNS[0]; got.Domain != "b.example.com" || got.AccessSecret != "original-secret-value" {
+		t.Fatalf("expected sensitive fields restored, got %+v", got)
+	}
+
+	// 新建时自动分配 ID
+	rec = doAPIRequest(mux, http.MethodPost, "/api/v1/ddns/groups",
+		`{"domain":"c.example.com","service":"cloudflare","access_key":"token","records":[{"type":"A","ip_type":"static_ipv4","value":"1.2.3.4"}]}`, true)
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("POST failed: %+v", result)
+	}
+	if len(repo.conf.DDNSConfig.DDNS) != 2 || repo.conf.DDNSConfig.DDNS[1].ID != "2" {
+		t.Fatalf("expected new group with id 2, got %+v", repo.conf.DDNSConfig.DDNS)
+	}
+
+	// 重复创建返回冲突
+	rec = doAPIRequest(mux, http.MethodPost, "/api/v1/ddns/groups", `{"id":"2","domain":"d.example.com","service":"cloudflare","access_key":"token"}`, true)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+
+	// 删除
+	rec = doAPIRequest(mux, http.MethodDelete, "/api/v1/ddns/groups/1", "", true)
+	if result := decodeResult(t, rec); !result.Status || len(repo.conf.DDNSConfig.DDNS) != 1 {
+		t.Fatalf("DELETE failed: %+v %+v", result, repo.conf.DDNSConfig.DDNS)
+	}
+	if rec = doAPIRequest(mux, http.MethodGet, "/api/v1/ddns/groups/1", "", true); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+	if syncer.ddnsTriggered != 3 {
+		t.Fatalf("expected 3 sync triggers, got %d", syncer.ddnsTriggered)
+	}
+}
+
+func TestAPIV1_DDNSGroupValidation(t *testing.T) {
+	mux, repo, syncer := newAPITestServer(t, config.Config{})
+
+	cases := map[string]string{
+		"unknown provider": `{"domain":"a.example.com","service":"nope"}`,
+		"unknown field":    `{"domain":"a.example.com","service":"cloudflare","access_key":"token","acess_secret":"typo"}`,
+		"bad endpoint":     `{"domain":"a.example.com","service":"cloudflare","access_key":"token","endpoint":"api.example.com"}`,
+	}
+	for name, body := range cases {
+		rec := doAPIRequest(mux, http.MethodPut, "/api/v1/ddns/groups/1", body, true)
+		if rec.Code != http.StatusBadRequest || decodeResult(t, rec).Status {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+	if rec := doAPIRequest(mux, http.MethodPut, "/api/v1/ddns/groups/1", `{"id":"2"}`, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("mismatched id: expected 400, got %d", rec.Code)
+	}
+	if len(repo.conf.DDNSConfig.DDNS) != 0 || syncer.ddnsTriggered != 0 {
+		t.Fatal("invalid requests must not be saved or trigger sync")
+	}
+}
+
+func TestAPIV1_DCDNCRUD(t *testing.T) {
+	mux, repo, syncer := newAPITestServer(t, config.Config{
+		DCDNConfig: config.DCDNConfig{DCDN: []config.CDN{{
+			ID: "1", Domain: "cdn.example.com", CName: "cdn.example.com.w.kunlun.com", Service: dcdn.ProviderTencent,
+			AccessKey: "AKID-original-key", AccessSecret: "original-secret-value",
+			Sources: []config.Source{{Type: "ipv4", Value: "1.2.3.4"}},
+		}}},
+	})
+
+	rec := doAPIRequest(mux, http.MethodGet, "/api/v1/dcdn/1", "", true)
+	var got config.CDN
+	raw, _ := json.Marshal(decodeResult(t, rec).Data)
+	json.Unmarshal(raw, &got)
+	if got.AccessKey == "AKID-original-key" {
+		t.Fatal("expected masked access key")
+	}
+
+	got.CName = ""
+	got.Sources = []config.Source{{Type: "ipv4", Value: "5.6.7.8"}}
+	body, _ := json.Marshal(got)
+	rec = doAPIRequest(mux, http.MethodPut, "/api/v1/dcdn/1", string(body), true)
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("PUT failed: %+v", result)
+	}
+	saved := repo.conf.DCDNConfig.DCDN[0]
+	if saved.AccessKey != "AKID-original-key" || saved.CName != "cdn.example.com.w.kunlun.com" || saved.Sources[0].Value != "5.6.7.8" {
+		t.Fatalf("unexpected saved cdn: %+v", saved)
+	}
+
+	rec = doAPIRequest(mux, http.MethodDelete, "/api/v1/dcdn/1", "", true)
+	if result := decodeResult(t, rec); !result.Status || len(repo.conf.DCDNConfig.DCDN) != 0 {
+		t.Fatalf("DELETE failed: %+v", result)
+	}
+	if syncer.dcdnTriggered != 2 {
+		t.Fatalf("expected 2 sync triggers, got %d", syncer.dcdnTriggered)
+	}
+}
+
+func TestAPIV1_RequiresLogin(t *testing.T) {
+	mux, _, _ := newAPITestServer(t, config.Config{})
+
+	rec := doAPIRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", false)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	var result helper.Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Status {
+		t.Fatalf("expected JSON error body, got %q", rec.Body.String())
+	}
+}
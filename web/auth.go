@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cxbdasheng/dnet/helper"
@@ -67,19 +68,31 @@ func (s *Server) Auth(f ViewFunc) ViewFunc {
 			return
 		}
 
-		// 检查登录Cookie
-		cookieInWeb, err := r.Cookie(CookieName)
-		if err != nil {
-			http.Redirect(w, r, "./login", http.StatusTemporaryRedirect)
+		// 接口支持 Authorization: Bearer <API 令牌>，按权限范围校验
+		if token, ok := bearerToken(r); ok {
+			s.authAPIToken(w, r, token, f)
 			return
 		}
 
-		// 验证token
-		if IsValidToken(cookieInWeb.Value) {
-			f(w, r) // 执行被装饰的函数
+		// 检查登录会话及账号角色
+		if account, ok := s.sessionAccount(r); ok {
+			if !allowedForRole(account, r) {
+				forbidden(w, r, account)
+				return
+			}
+			f(w, withAccount(r, account)) // 执行被装饰的函数
 			return
 		}
 
-		http.Redirect(w, r, "./login", http.StatusTemporaryRedirect)
+		unauthorized(w, r)
 	}
 }
+
+// unauthorized 未登录时页面跳转到登录页，接口返回 401（相对跳转对多级路径的接口无效）
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		helper.ReturnErrorWithStatus(w, http.StatusUnauthorized, "未登录或登录已过期")
+		return
+	}
+	http.Redirect(w, r, "./login", http.StatusTemporaryRedirect)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/dcdn"
 	"github.com/cxbdasheng/dnet/helper"
 )
 
@@ -50,13 +51,15 @@ func (s *Server) handleDCDNGet(writer http.ResponseWriter, request *http.Request
 	ipv4, ipv6, _ := helper.GetNetInterface()
 
 	err = tmpl.Execute(writer, struct {
-		DCDNConf template.JS
-		IPv4     []helper.NetInterface
-		IPv6     []helper.NetInterface
+		DCDNConf  template.JS
+		Providers template.JS
+		IPv4      []helper.NetInterface
+		IPv6      []helper.NetInterface
 	}{
-		DCDNConf: template.JS(config.GetDCDNConfigJSON(conf.DCDNConfig)),
-		IPv4:     ipv4,
-		IPv6:     ipv6,
+		DCDNConf:  template.JS(config.GetDCDNConfigJSON(conf.DCDNConfig)),
+		Providers: template.JS(dcdn.ProvidersJSON()),
+		IPv4:      ipv4,
+		IPv6:      ipv6,
 	})
 	if err != nil {
 		// 检查是否是客户端主动关闭连接（broken pipe 或 connection reset）
@@ -79,6 +82,8 @@ func (s *Server) handleDCDNPost(writer http.ResponseWriter, request *http.Reques
 		return
 	}
 
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
 	conf, err := s.configRepo.Load()
 	if err != nil {
 		helper.Error(helper.LogTypeDCDN, "获取配置失败: %v", err)
@@ -89,6 +94,15 @@ func (s *Server) handleDCDNPost(writer http.ResponseWriter, request *http.Reques
 	// 恢复脱敏字段的原始值（如果前端发送的是脱敏数据）
 	configData = config.RestoreSensitiveFields(configData, conf.DCDNConfig)
 
+	// 按提供商的配置说明校验，未注册的服务直接拒绝（不再回退到阿里云）
+	for i := range configData.DCDN {
+		if err := dcdn.ValidateCDN(&configData.DCDN[i]); err != nil {
+			helper.Warn(helper.LogTypeDCDN, "配置校验失败: %v", err)
+			helper.ReturnError(writer, err.Error())
+			return
+		}
+	}
+
 	// CacheTimes 由「系统设置」页面管理，此接口不携带，需保留旧值
 	configData.CacheTimes = conf.DCDNConfig.CacheTimes
 
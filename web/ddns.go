@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/ddns"
 	"github.com/cxbdasheng/dnet/helper"
 )
 
@@ -51,13 +52,15 @@ func (s *Server) handleDDNSGet(writer http.ResponseWriter, request *http.Request
 
 	ipv4, ipv6, _ := helper.GetNetInterface()
 	err = tmpl.Execute(writer, struct {
-		DDNSConf template.JS
-		IPv4     []helper.NetInterface
-		IPv6     []helper.NetInterface
+		DDNSConf  template.JS
+		Providers template.JS
+		IPv4      []helper.NetInterface
+		IPv6      []helper.NetInterface
 	}{
-		DDNSConf: template.JS(config.GetDDNSConfigJSON(conf.DDNSConfig)),
-		IPv4:     ipv4,
-		IPv6:     ipv6,
+		DDNSConf:  template.JS(config.GetDDNSConfigJSON(conf.DDNSConfig)),
+		Providers: template.JS(ddns.ProvidersJSON()),
+		IPv4:      ipv4,
+		IPv6:      ipv6,
 	})
 	if err != nil {
 		// 检查是否是客户端主动关闭连接（broken pipe 或 connection reset）
@@ -79,6 +82,8 @@ func (s *Server) handleDDNSPost(writer http.ResponseWriter, request *http.Reques
 		return
 	}
 
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
 	conf, err := s.configRepo.Load()
 	if err != nil {
 		helper.Error(helper.LogTypeDDNS, "获取配置失败: %v", err)
@@ -89,8 +94,19 @@ func (s *Server) handleDDNSPost(writer http.ResponseWriter, request *http.Reques
 	// 恢复脱敏字段的原始值（如果前端发送的是脱敏数据）
 	configData = config.RestoreSensitiveFieldsForDDNS(configData, conf.DDNSConfig)
 
+	// 按提供商的配置说明校验，未注册的服务直接拒绝
+	for i := range configData.DDNS {
+		if err := ddns.ValidateGroup(&configData.DDNS[i]); err != nil {
+			helper.Warn(helper.LogTypeDDNS, "配置校验失败: %v", err)
+			helper.ReturnError(writer, err.Error())
+			return
+		}
+	}
+
 	// CacheTimes 由「系统设置」页面管理，此接口不携带，需保留旧值
 	configData.CacheTimes = conf.DDNSConfig.CacheTimes
+	// Docker 容器发现仅通过配置文件设置，同样保留
+	configData.Docker = conf.DDNSConfig.Docker
 
 	// 更新 DDNS 配置
 	conf.DDNSConfig = configData
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cxbdasheng/dnet/helper"
+	"github.com/cxbdasheng/dnet/history"
+)
+
+// HistoryAPI 查询同步历史
+// 参数：kind(DDNS/DCDN)、domain、status、since、until（RFC3339、日期或 Unix 秒）、page、page_size
+func (s *Server) HistoryAPI(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		helper.ReturnError(writer, "不支持的请求方法")
+		return
+	}
+	if s.history == nil {
+		helper.ReturnError(writer, "同步历史未启用")
+		return
+	}
+
+	query, err := parseHistoryQuery(request)
+	if err != nil {
+		helper.ReturnError(writer, err.Error())
+		return
+	}
+	helper.ReturnSuccess(writer, "", s.history.Query(query))
+}
+
+// parseHistoryQuery 解析查询参数
+func parseHistoryQuery(request *http.Request) (history.Query, error) {
+	values := request.URL.Query()
+	q := history.Query{
+		Kind:   strings.TrimSpace(values.Get("kind")),
+		Domain: strings.TrimSpace(values.Get("domain")),
+		Status: strings.TrimSpace(values.Get("status")),
+	}
+
+	var err error
+	if q.Since, err = parseHistoryTime(values.Get("since"), false); err != nil {
+		return q, errors.New("参数 since 格式不正确")
+	}
+	if q.Until, err = parseHistoryTime(values.Get("until"), true); err != nil {
+		return q, errors.New("参数 until 格式不正确")
+	}
+	if q.Page, err = parseHistoryInt(values.Get("page")); err != nil {
+		return q, errors.New("参数 page 格式不正确")
+	}
+	if q.PageSize, err = parseHistoryInt(values.Get("page_size")); err != nil {
+		return q, errors.New("参数 page_size 格式不正确")
+	}
+	return q, nil
+}
+
+// parseHistoryTime 支持 RFC3339、2006-01-02（until 取当天结束）和 Unix 秒
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, nil
+	}
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+func parseHistoryInt(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/history"
+)
+
+func TestHistoryAPI_FiltersAndPaginates(t *testing.T) {
+	store, err := history.NewStore("", 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	now := time.Now()
+	store.Add(
+		history.Entry{Time: now.Add(-2 * time.Hour), Kind: history.KindDDNS, Domain: "home.example.com", Status: "成功"},
+		history.Entry{Time: now.Add(-time.Hour), Kind: history.KindDDNS, Domain: "home.example.com", Status: "失败"},
+		history.Entry{Time: now, Kind: history.KindDDNS, Domain: "home.example.com", Status: "成功"},
+		history.Entry{Time: now, Kind: history.KindDCDN, Domain: "cdn.example.com", Status: "成功"},
+	)
+
+	server := NewServer(&stubRepository{}, nil)
+	server.UseHistory(store)
+
+	since := now.Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+	req := httptest.NewRequest(http.MethodGet, "/api/history?domain=home&status=%E6%88%90%E5%8A%9F&page_size=1&since="+since, nil)
+	recorder := httptest.NewRecorder()
+	server.HistoryAPI(recorder, req)
+
+	result := decodeResult(t, recorder)
+	if !result.Status {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	raw, _ := json.Marshal(result.Data)
+	var page history.Page
+	if err := json.Unmarshal(raw, &page); err != nil {
+		t.Fatalf("failed to decode page: %v", err)
+	}
+	if page.Total != 1 || page.PageSize != 1 || len(page.Items) != 1 || page.Items[0].Status != "成功" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestHistoryAPI_RejectsInvalidParams(t *testing.T) {
+	store, _ := history.NewStore("", 0, 0)
+	server := NewServer(&stubRepository{}, nil)
+	server.UseHistory(store)
+
+	for _, query := range []string{"since=yesterday", "page=-1", "page_size=abc"} {
+		recorder := httptest.NewRecorder()
+		server.HistoryAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/history?"+query, nil))
+		if result := decodeResult(t, recorder); result.Status {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
+
+func TestHistoryAPI_HugePage(t *testing.T) {
+	store, _ := history.NewStore("", 0, 0)
+	store.Add(history.Entry{Time: time.Now(), Kind: history.KindDDNS, Domain: "home.example.com", Status: "成功"})
+	server := NewServer(&stubRepository{}, nil)
+	server.UseHistory(store)
+
+	recorder := httptest.NewRecorder()
+	server.HistoryAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/history?page=9223372036854775807&page_size=200", nil))
+	result := decodeResult(t, recorder)
+	if !result.Status {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	raw, _ := json.Marshal(result.Data)
+	var page struct {
+		Total int             `json:"total"`
+		Items []history.Entry `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &page); err != nil {
+		t.Fatalf("failed to decode page: %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 0 {
+		t.Fatalf("expected empty page, got %+v", page)
+	}
+}
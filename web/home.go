@@ -9,8 +9,6 @@ import (
 	"github.com/cxbdasheng/dnet/helper"
 )
 
-const VersionEnv = "DNET_VERSION"
-
 //go:embed home.html
 var homeEmbedFile embed.FS
 
@@ -34,7 +32,7 @@ func (s *Server) handleHomeGet(writer http.ResponseWriter, request *http.Request
 	err = tmpl.Execute(writer, struct {
 		Version string
 	}{
-		Version: os.Getenv(VersionEnv),
+		Version: os.Getenv(helper.VersionENV),
 	})
 	if err != nil {
 		helper.Error(helper.LogTypeSystem, "渲染首页失败 [路径=%s]: %v", request.URL.Path, err)
@@ -40,10 +40,6 @@ const (
 	LoginLockDuration = 30 * time.Minute // 登录失败锁定时间
 )
 
-// currentCookie 当前系统Cookie实例（单例模式）
-var currentCookie = &http.Cookie{}
-var currentCookieMu sync.RWMutex
-
 // serverStartTime 服务启动时间
 var serverStartTime = time.Now()
 
@@ -61,6 +57,7 @@ var globalLoginDetector = &LoginDetector{}
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Code     string `json:"code"` // 两步验证码或恢复码
 }
 
 func (s *Server) Login(writer http.ResponseWriter, request *http.Request) {
@@ -93,7 +90,7 @@ func (s *Server) handleLoginGet(writer http.ResponseWriter, _ *http.Request) {
 		Version   string
 	}{
 		EmptyUser: conf.Username == "" || conf.Password == "",
-		Version:   os.Getenv(VersionEnv),
+		Version:   os.Getenv(helper.VersionENV),
 	}
 
 	if err = tmpl.Execute(writer, data); err != nil {
@@ -142,9 +139,13 @@ func (s *Server) handleLoginPost(writer http.ResponseWriter, request *http.Reque
 	}
 
 	// 验证登录信息
-	if loginReq.Username == conf.Username && conf.VerifyPassword(loginReq.Password) {
+	if account, ok := conf.Authenticate(loginReq.Username, loginReq.Password); ok {
+		if account.TwoFactor.Enabled() && !s.verifyLoginCode(writer, request, &conf, account, loginReq.Code) {
+			return
+		}
+		s.upgradePasswordHash(&conf, account, loginReq.Password)
 		// 登录成功处理
-		if err := s.handleLoginSuccess(writer, &conf); err != nil {
+		if err := s.handleLoginSuccess(writer, &conf, account); err != nil {
 			helper.Error(helper.LogTypeAuth, "登录成功处理失败: %v", err)
 			helper.ReturnError(writer, "登录处理失败")
 			return
@@ -188,34 +189,92 @@ func (s *Server) handleInitialSetup(conf *config.Config, loginReq LoginRequest,
 	return nil
 }
 
-// handleLoginSuccess 处理登录成功
-func (s *Server) handleLoginSuccess(writer http.ResponseWriter, conf *config.Config) error {
+// verifyLoginCode 校验两步验证码，未填写时提示前端输入；验证码与恢复码都只能使用一次
+func (s *Server) verifyLoginCode(writer http.ResponseWriter, request *http.Request, conf *config.Config, account config.Account, code string) bool {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		helper.ReturnErrorWithData(writer, "请输入两步验证码", map[string]bool{"need_code": true})
+		return false
+	}
+
+	// 串行化校验与保存，并发请求不能重复使用同一个验证码
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+	if latest, err := s.configRepo.Load(); err == nil {
+		if latestAccount, ok := latest.FindAccount(account.Username); ok {
+			*conf, account = latest, latestAccount
+		}
+	}
+
+	tf, ok := account.TwoFactor.Verify(code, time.Now())
+	if !ok {
+		attempts, locked := globalLoginDetector.RecordFailure(time.Now())
+		if locked {
+			helper.Warn(helper.LogTypeAuth, "登录尝试已锁定 %v，失败次数: %d", LoginLockDuration, attempts)
+			helper.ReturnError(writer, "登录失败次数过多，请稍后再试")
+			return false
+		}
+		helper.Warn(helper.LogTypeAuth, "两步验证失败 - 用户: %s, IP: %s, 失败次数: %d", account.Username, helper.GetClientIP(request), attempts)
+		helper.ReturnErrorWithData(writer, "两步验证码错误", map[string]bool{"need_code": true})
+		return false
+	}
+
+	// 保存已用的验证码时间窗口或作废已用的恢复码，防止重放
+	conf.SetAccountTwoFactor(account.Username, tf)
+	if err := s.configRepo.Save(conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "保存两步验证状态失败: %v", err)
+		helper.ReturnError(writer, "登录处理失败")
+		return false
+	}
+	if len(tf.RecoveryCodes) != len(account.TwoFactor.RecoveryCodes) {
+		helper.Warn(helper.LogTypeAuth, "用户 %s 使用恢复码登录，剩余恢复码: %d", account.Username, len(tf.RecoveryCodes))
+	}
+	return true
+}
+
+// upgradePasswordHash 登录成功后将旧格式的密码哈希升级为 bcrypt，失败时不影响本次登录
+func (s *Server) upgradePasswordHash(conf *config.Config, account config.Account, password string) {
+	if !account.NeedsPasswordRehash() {
+		return
+	}
+	hashedPwd, err := conf.GeneratePassword(password)
+	if err != nil {
+		helper.Warn(helper.LogTypeAuth, "密码哈希升级失败: %v", err)
+		return
+	}
+	conf.SetAccountPassword(account.Username, hashedPwd)
+	if err = s.configRepo.Save(conf); err != nil {
+		helper.Warn(helper.LogTypeAuth, "密码哈希升级后保存配置失败: %v", err)
+		return
+	}
+	helper.Info(helper.LogTypeAuth, "已将用户 %s 的密码哈希升级为 bcrypt", account.Username)
+}
+
+// handleLoginSuccess 处理登录成功，为当前浏览器创建独立会话
+func (s *Server) handleLoginSuccess(writer http.ResponseWriter, conf *config.Config, account config.Account) error {
 	// 重置登录检测器
 	globalLoginDetector.Reset()
 
-	// 计算Cookie过期时间
-	var expires time.Time
+	// 计算会话有效期
+	ttl := CookieMaxAgePublic
 	if conf.NotAllowWanAccess {
-		expires = time.Now().Add(CookieMaxAgePrivate)
-	} else {
-		expires = time.Now().Add(CookieMaxAgePublic)
+		ttl = CookieMaxAgePrivate
 	}
+	session := globalSessions.Create(account.Username, ttl)
 
 	// 生成并设置Cookie
 	newCookie := &http.Cookie{
 		Name:     CookieName,
-		Value:    generateToken(),
+		Value:    session.Token,
 		Path:     "/",
-		Expires:  expires,
+		Expires:  session.Expires,
 		HttpOnly: true,
 		Secure:   false, // 根据需要调整
 		SameSite: http.SameSiteStrictMode,
 	}
 
-	setCurrentCookie(newCookie)
-
 	http.SetCookie(writer, newCookie)
-	helper.Info(helper.LogTypeAuth, "用户登录成功: %s, Cookie 过期时间: %v", conf.Username, expires)
+	helper.Info(helper.LogTypeAuth, "用户登录成功: %s (%s), Cookie 过期时间: %v", account.Username, account.Role, session.Expires)
 
 	helper.ReturnSuccess(writer, "用户登录成功", newCookie.Value)
 	return nil
@@ -271,49 +330,6 @@ func (d *LoginDetector) clearExpiredLock(now time.Time) {
 	helper.Info(helper.LogTypeAuth, "登录锁定已解除，可重新尝试登录")
 }
 
-func setCurrentCookie(cookie *http.Cookie) {
-	currentCookieMu.Lock()
-	defer currentCookieMu.Unlock()
-
-	if cookie == nil {
-		currentCookie = &http.Cookie{}
-		return
-	}
-
-	cookieCopy := *cookie
-	currentCookie = &cookieCopy
-}
-
-func getCurrentCookieSnapshot() *http.Cookie {
-	currentCookieMu.RLock()
-	defer currentCookieMu.RUnlock()
-
-	if currentCookie == nil {
-		return nil
-	}
-
-	cookieCopy := *currentCookie
-	return &cookieCopy
-}
-
-// GetCurrentCookie 获取当前Cookie（用于其他模块验证）
-func GetCurrentCookie() *http.Cookie {
-	return getCurrentCookieSnapshot()
-}
-
-// IsValidToken 验证令牌是否有效
-func IsValidToken(token string) bool {
-	c := getCurrentCookieSnapshot()
-
-	if c == nil || c.Value == "" {
-		return false
-	}
-	if time.Now().After(c.Expires) {
-		return false
-	}
-	return c.Value == token
-}
-
 // generateToken 生成安全的登录令牌
 func generateToken() string {
 	randomBytes := make([]byte, TokenLength)
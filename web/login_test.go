@@ -1,6 +1,8 @@
 package web
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -29,9 +31,13 @@ func (r *stubRepository) ResetPassword(string) error {
 	return nil
 }
 
+func (r *stubRepository) DisableTwoFactor(string) error {
+	return nil
+}
+
 func resetAuthStateForTest() {
 	globalLoginDetector.Reset()
-	setCurrentCookie(&http.Cookie{})
+	globalSessions.Clear()
 }
 
 func decodeResult(t *testing.T, recorder *httptest.ResponseRecorder) helper.Result {
@@ -88,62 +94,120 @@ func TestHandleLoginPost_LocksImmediatelyAtMaxFailedAttempts(t *testing.T) {
 	}
 }
 
-func TestLogoutClearsCurrentCookie(t *testing.T) {
+func TestHandleLoginPost_UpgradesLegacyPasswordHash(t *testing.T) {
 	resetAuthStateForTest()
 	t.Cleanup(resetAuthStateForTest)
 
-	token := "token-to-clear"
-	setCurrentCookie(&http.Cookie{
-		Name:    CookieName,
-		Value:   token,
-		Path:    "/",
-		Expires: time.Now().Add(time.Hour),
-	})
+	legacy := sha256.Sum256([]byte("legacy-password"))
+	repo := &stubRepository{
+		conf: config.Config{
+			User: config.User{
+				Username: "admin",
+				Password: hex.EncodeToString(legacy[:]),
+			},
+		},
+	}
+	server := &Server{configRepo: repo}
 
-	if !IsValidToken(token) {
-		t.Fatal("expected token to be valid before logout")
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"admin","password":"legacy-password"}`))
+	server.handleLoginPost(recorder, request)
+
+	if result := decodeResult(t, recorder); !result.Status {
+		t.Fatalf("expected legacy password login to succeed, got %+v", result)
+	}
+	if repo.conf.Password == hex.EncodeToString(legacy[:]) || repo.conf.NeedsPasswordRehash() {
+		t.Fatalf("expected password hash to be rewritten in new format, got %q", repo.conf.Password)
+	}
+	if !repo.conf.VerifyPassword("legacy-password") {
+		t.Fatal("expected upgraded hash to verify the same password")
+	}
+}
+
+func TestLogoutDeletesOnlyCurrentSession(t *testing.T) {
+	resetAuthStateForTest()
+	t.Cleanup(resetAuthStateForTest)
+
+	first := globalSessions.Create("admin", time.Hour)
+	second := globalSessions.Create("admin", time.Hour)
+	if !IsValidToken(first.Token) || !IsValidToken(second.Token) {
+		t.Fatal("expected both sessions to be valid before logout")
 	}
 
 	server := &Server{}
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	request.AddCookie(&http.Cookie{Name: CookieName, Value: first.Token})
 	server.Logout(recorder, request)
 
 	if recorder.Code != http.StatusFound {
 		t.Fatalf("logout status = %d, want %d", recorder.Code, http.StatusFound)
 	}
-	if IsValidToken(token) {
+	if IsValidToken(first.Token) {
 		t.Fatal("expected token to be invalid after logout")
 	}
-
-	current := GetCurrentCookie()
-	if current == nil {
-		t.Fatal("expected current cookie snapshot after logout")
-	}
-	if current.Value != "" {
-		t.Fatalf("current cookie value = %q, want empty", current.Value)
+	if !IsValidToken(second.Token) {
+		t.Fatal("expected other browser session to stay valid")
 	}
-	if current.MaxAge != -1 {
-		t.Fatalf("current cookie MaxAge = %d, want -1", current.MaxAge)
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "" || cookies[0].MaxAge != -1 {
+		t.Fatalf("expected expired cookie, got %+v", cookies)
 	}
 }
 
-func TestGetCurrentCookieReturnsSnapshot(t *testing.T) {
+func TestHandleLoginPost_IndependentSessions(t *testing.T) {
 	resetAuthStateForTest()
 	t.Cleanup(resetAuthStateForTest)
 
-	setCurrentCookie(&http.Cookie{
-		Name:    CookieName,
-		Value:   "snapshot-token",
-		Path:    "/",
-		Expires: time.Now().Add(time.Hour),
-	})
+	conf := config.Config{User: config.User{Username: "admin"}}
+	hashedPassword, err := conf.GeneratePassword("admin-password")
+	if err != nil {
+		t.Fatalf("GeneratePassword() error = %v", err)
+	}
+	conf.Password = hashedPassword
+	if err = conf.AddAccount("alice", "alice-password", config.RoleViewer); err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+	server := &Server{configRepo: &stubRepository{conf: conf}}
+
+	login := func(body string) string {
+		recorder := httptest.NewRecorder()
+		server.handleLoginPost(recorder, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+		result := decodeResult(t, recorder)
+		if !result.Status {
+			t.Fatalf("login %s failed: %+v", body, result)
+		}
+		return result.Data.(string)
+	}
+	firstBrowser := login(`{"username":"admin","password":"admin-password"}`)
+	secondBrowser := login(`{"username":"admin","password":"admin-password"}`)
+	viewer := login(`{"username":"alice","password":"alice-password"}`)
+
+	for _, token := range []string{firstBrowser, secondBrowser, viewer} {
+		if !IsValidToken(token) {
+			t.Fatalf("expected session %s to stay valid", token)
+		}
+	}
+	if session, _ := globalSessions.Get(viewer); session.Username != "alice" {
+		t.Fatalf("expected viewer session for alice, got %+v", session)
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	store := NewSessionStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
 
-	snapshot := GetCurrentCookie()
-	snapshot.Value = "mutated"
+	session := store.Create("admin", time.Minute)
+	if _, ok := store.Get(session.Token); !ok {
+		t.Fatal("expected fresh session to be valid")
+	}
 
-	current := GetCurrentCookie()
-	if current.Value != "snapshot-token" {
-		t.Fatalf("current cookie value = %q, want snapshot-token", current.Value)
+	now = now.Add(2 * time.Minute)
+	if _, ok := store.Get(session.Token); ok {
+		t.Fatal("expected expired session to be rejected")
+	}
+	if len(store.sessions) != 0 {
+		t.Fatalf("expected expired session to be removed, got %d", len(store.sessions))
 	}
 }
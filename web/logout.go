@@ -14,7 +14,9 @@ func (s *Server) Logout(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   -1,
 		HttpOnly: true,
 	}
-	setCurrentCookie(expiredCookie)
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		globalSessions.Delete(cookie.Value)
+	}
 	// 设置过期的 Cookie
 	http.SetCookie(w, expiredCookie)
 
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// NicUpdate 兼容 DynDNS2 协议的推送接口：GET /nic/update?hostname=&myip=
+//
+// 路由器使用 HTTP Basic 认证，用户名任意，密码为拥有 push-ip 权限的 API 令牌（也支持 Bearer）。
+// hostname 需与 pushed_ipv4 / pushed_ipv6 记录的值一致；myip 可包含逗号分隔的 IPv4 和 IPv6，
+// 也可使用 myipv6 单独传入 IPv6；均未提供时使用请求来源 IP。
+// 响应为纯文本返回码（good / nochg / badauth / nohost / notfqdn / 911），每个主机名一行。
+func (s *Server) NicUpdate(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	clientIP := helper.GetClientIP(request)
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		fmt.Fprintln(writer, "911")
+		return
+	}
+	if !s.authPushToken(request, &conf, clientIP) {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="D-NET"`)
+		writer.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintln(writer, "badauth")
+		return
+	}
+
+	query := request.URL.Query()
+	ipv4, ipv6 := parsePushedAddrs(query.Get("myip"), query.Get("myipv6"))
+	if ipv4 == "" && ipv6 == "" {
+		ipv4, ipv6 = parsePushedAddrs(clientIP, "")
+	}
+
+	var changedHosts []string
+	hostnames := strings.Split(query.Get("hostname"), ",")
+	for _, hostname := range hostnames {
+		hostname = strings.TrimSpace(hostname)
+		if hostname == "" || strings.ContainsAny(hostname, " /:") {
+			fmt.Fprintln(writer, "notfqdn")
+			continue
+		}
+		if !pushedHostUsed(&conf, hostname) {
+			helper.Warn(helper.LogTypeSystem, "收到未被任何配置使用的推送 [主机=%s, IP=%s]", hostname, clientIP)
+			fmt.Fprintln(writer, "nohost")
+			continue
+		}
+
+		changed := false
+		var addrs []string
+		if ipv4 != "" {
+			changed = helper.SetPushedIP(hostname, helper.IPv4, ipv4, clientIP) || changed
+			addrs = append(addrs, ipv4)
+		}
+		if ipv6 != "" {
+			changed = helper.SetPushedIP(hostname, helper.IPv6, ipv6, clientIP) || changed
+			addrs = append(addrs, ipv6)
+		}
+		if !changed {
+			fmt.Fprintln(writer, "nochg "+strings.Join(addrs, ","))
+			continue
+		}
+
+		helper.Info(helper.LogTypeSystem, "收到推送的新地址 [主机=%s, 地址=%s, IP=%s]", hostname, strings.Join(addrs, ","), clientIP)
+		changedHosts = append(changedHosts, hostname)
+		fmt.Fprintln(writer, "good "+strings.Join(addrs, ","))
+	}
+
+	// 地址变化时立即同步使用这些主机名的配置，无需等待下一个周期；其它配置不受影响
+	if len(changedHosts) > 0 {
+		s.syncer.TriggerPushedSyncAsync(changedHosts)
+	}
+}
+
+// authPushToken 从 Basic 认证密码或 Bearer 头中读取 API 令牌，并校验 push-ip 权限
+func (s *Server) authPushToken(request *http.Request, conf *config.Config, clientIP string) bool {
+	plain, ok := bearerToken(request)
+	if !ok {
+		_, plain, ok = request.BasicAuth()
+	}
+	if !ok || plain == "" {
+		helper.Warn(helper.LogTypeAuth, "推送请求缺少认证信息 [IP=%s]", clientIP)
+		return false
+	}
+	token, ok := conf.FindAPIToken(plain)
+	if !ok {
+		helper.Warn(helper.LogTypeAuth, "推送请求使用了无效的 API 令牌 [IP=%s]", clientIP)
+		return false
+	}
+	if !token.HasScope(config.ScopePushIP) {
+		helper.Warn(helper.LogTypeAuth, "API 令牌 [%s] 缺少 %s 权限 [IP=%s]", token.Name, config.ScopePushIP, clientIP)
+		return false
+	}
+	return true
+}
+
+// parsePushedAddrs 从 myip / myipv6 中解析 IPv4 和 IPv6 地址，无效值被忽略
+func parsePushedAddrs(values ...string) (ipv4, ipv6 string) {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			ip := net.ParseIP(strings.TrimSpace(part))
+			switch {
+			case ip == nil:
+			case ip.To4() != nil:
+				if ipv4 == "" {
+					ipv4 = ip.String()
+				}
+			default:
+				if ipv6 == "" {
+					ipv6 = ip.String()
+				}
+			}
+		}
+	}
+	return ipv4, ipv6
+}
+
+// pushedHostUsed 判断主机名是否被 DDNS 记录或 DCDN 源站使用
+func pushedHostUsed(conf *config.Config, hostname string) bool {
+	for _, group := range conf.DDNSConfig.DDNS {
+		for _, record := range group.Records {
+			if helper.IsPushedType(record.IPType) && strings.EqualFold(record.Value, hostname) {
+				return true
+			}
+		}
+	}
+	for _, cdn := range conf.DCDNConfig.DCDN {
+		for _, source := range cdn.Sources {
+			if helper.IsPushedType(source.Type) && strings.EqualFold(source.Value, hostname) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+func newNicTestServer(t *testing.T, scopes ...string) (*http.ServeMux, *stubSyncer, string) {
+	t.Helper()
+	helper.ClearPushedIPs()
+	t.Cleanup(helper.ClearPushedIPs)
+
+	plain, token, err := config.NewAPIToken("router", scopes)
+	if err != nil {
+		t.Fatalf("NewAPIToken returned error: %v", err)
+	}
+	mux, _, syncer := newAPITestServer(t, config.Config{
+		DDNSConfig: config.DDNSConfig{DDNS: []config.DNSGroup{{
+			ID: "1", Domain: "a.example.com", Service: "cloudflare",
+			Records: []config.DNSRecord{
+				{Type: "A", IPType: helper.PushedIPv4, Value: "Router"},
+				{Type: "AAAA", IPType: helper.PushedIPv6, Value: "router"},
+			},
+		}}},
+		DCDNConfig: config.DCDNConfig{DCDN: []config.CDN{{
+			ID: "c1", Domain: "cdn.example.com", Service: "cloudflare",
+			Sources: []config.Source{{Type: helper.PushedIPv4, Value: "office"}},
+		}}},
+		APITokens: []config.APIToken{token},
+	})
+	return mux, syncer, plain
+}
+
+func doNicUpdate(mux *http.ServeMux, query, password string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/nic/update?"+query, nil)
+	if password != "" {
+		req.SetBasicAuth("anything", password)
+	}
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestNicUpdate_PushTriggersSync(t *testing.T) {
+	mux, syncer, plain := newNicTestServer(t, config.ScopePushIP)
+
+	rec := doNicUpdate(mux, "hostname=router&myip=203.0.113.7,2001:db8::7", plain)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "good 203.0.113.7,2001:db8::7" {
+		t.Fatalf("expected good response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if len(syncer.pushedHosts) != 1 || strings.Join(syncer.pushedHosts[0], ",") != "router" {
+		t.Fatalf("expected sync of configs using the pushed host, got %v", syncer.pushedHosts)
+	}
+	if syncer.ddnsTriggered != 0 || syncer.dcdnTriggered != 0 {
+		t.Fatalf("push should not trigger a full sync, got ddns=%d dcdn=%d", syncer.ddnsTriggered, syncer.dcdnTriggered)
+	}
+	if addr, ok := helper.GetOrSetDynamicIPWithCache(helper.PushedIPv4, "ROUTER"); !ok || addr != "203.0.113.7" {
+		t.Fatalf("expected pushed IPv4 to be readable, got %q %v", addr, ok)
+	}
+	if addr, ok := helper.GetOrSetDynamicIPWithCache(helper.PushedIPv6, "router"); !ok || addr != "2001:db8::7" {
+		t.Fatalf("expected pushed IPv6 to be readable, got %q %v", addr, ok)
+	}
+
+	// 相同地址再次推送不触发同步
+	rec = doNicUpdate(mux, "hostname=router&myip=203.0.113.7&myipv6=2001:db8::7", plain)
+	if !strings.HasPrefix(rec.Body.String(), "nochg ") || len(syncer.pushedHosts) != 1 {
+		t.Fatalf("expected nochg without sync, got %q syncs=%v", rec.Body.String(), syncer.pushedHosts)
+	}
+}
+
+func TestNicUpdate_DCDNConsumerAndClientIP(t *testing.T) {
+	mux, syncer, plain := newNicTestServer(t, config.ScopePushIP)
+
+	rec := doNicUpdate(mux, "hostname=office", plain)
+	if strings.TrimSpace(rec.Body.String()) != "good 192.0.2.1" {
+		t.Fatalf("expected remote address to be used when myip is missing, got %q", rec.Body.String())
+	}
+	if len(syncer.pushedHosts) != 1 || strings.Join(syncer.pushedHosts[0], ",") != "office" {
+		t.Fatalf("expected sync of configs using the pushed host, got %v", syncer.pushedHosts)
+	}
+}
+
+func TestNicUpdate_Errors(t *testing.T) {
+	mux, syncer, plain := newNicTestServer(t, config.ScopeReadOnly)
+
+	rec := doNicUpdate(mux, "hostname=router&myip=203.0.113.7", "")
+	if rec.Code != http.StatusUnauthorized || strings.TrimSpace(rec.Body.String()) != "badauth" || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected badauth without credentials, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec = doNicUpdate(mux, "hostname=router&myip=203.0.113.7", plain); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected token without push-ip scope to be rejected, got %d", rec.Code)
+	}
+
+	mux, syncer, plain = newNicTestServer(t, config.ScopePushIP)
+	rec = doNicUpdate(mux, "hostname=unknown,router&myip=203.0.113.7", plain)
+	if got := rec.Body.String(); got != "nohost\ngood 203.0.113.7\n" {
+		t.Fatalf("expected per-host responses, got %q", got)
+	}
+	if len(syncer.pushedHosts) != 1 || strings.Join(syncer.pushedHosts[0], ",") != "router" {
+		t.Fatalf("expected sync only for known host, got %v", syncer.pushedHosts)
+	}
+}
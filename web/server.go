@@ -2,18 +2,29 @@ package web
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/history"
 )
 
 type SyncService interface {
 	TriggerDCDNSyncAsync()
 	TriggerDDNSSyncAsync()
+	// TriggerPushedSyncAsync 仅同步使用了这些推送主机名的配置
+	TriggerPushedSyncAsync(hostnames []string)
+}
+
+// HistoryQuerier 同步历史查询接口
+type HistoryQuerier interface {
+	Query(q history.Query) history.Page
 }
 
 type Server struct {
 	configRepo config.Repository
 	syncer     SyncService
+	history    HistoryQuerier
+	apiMu      sync.Mutex // 串行化配置的「读取-修改-保存」（REST 接口与页面表单）
 }
 
 func NewServer(configRepo config.Repository, syncer SyncService) *Server {
@@ -23,6 +34,11 @@ func NewServer(configRepo config.Repository, syncer SyncService) *Server {
 	}
 }
 
+// UseHistory 设置同步历史查询来源，未设置时 /api/history 返回错误
+func (s *Server) UseHistory(h HistoryQuerier) {
+	s.history = h
+}
+
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/static/", s.AuthAssert(staticFsFunc))
 	mux.HandleFunc("/favicon.ico", s.AuthAssert(faviconFsFunc))
@@ -38,6 +54,18 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/settings", s.Auth(s.Settings))
 	mux.HandleFunc("/logs/count", s.Auth(s.LogsCount))
 	mux.HandleFunc("/logs", s.Auth(s.Logs))
+	mux.HandleFunc("/api/history", s.Auth(s.HistoryAPI))
+	mux.HandleFunc("/api/tokens", s.Auth(s.APITokens))
+	mux.HandleFunc("DELETE /api/tokens/{id}", s.Auth(s.RevokeAPIToken))
+	mux.HandleFunc("/api/users", s.Auth(s.Users))
+	mux.HandleFunc("DELETE /api/users/{username}", s.Auth(s.DeleteUser))
+	mux.HandleFunc("GET /api/2fa", s.Auth(s.TwoFactorStatus))
+	mux.HandleFunc("POST /api/2fa/setup", s.Auth(s.TwoFactorSetup))
+	mux.HandleFunc("POST /api/2fa/enable", s.Auth(s.TwoFactorEnable))
+	mux.HandleFunc("POST /api/2fa/recovery-codes", s.Auth(s.TwoFactorRecoveryCodes))
+	mux.HandleFunc("POST /api/2fa/disable", s.Auth(s.TwoFactorDisable))
+	s.registerAPIV1Routes(mux)
+	mux.HandleFunc("/nic/update", s.AuthAssert(s.NicUpdate))
 	mux.HandleFunc("/login", s.AuthAssert(s.Login))
 	mux.HandleFunc("/logout", s.AuthAssert(s.Logout))
 }
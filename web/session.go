@@ -0,0 +1,187 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// Session 登录会话，每个浏览器独立持有
+type Session struct {
+	Token    string
+	Username string
+	Expires  time.Time
+}
+
+// SessionStore 内存中的会话存储，过期会话在访问和创建时清理
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+	now      func() time.Time
+}
+
+// NewSessionStore 创建会话存储
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]Session), now: time.Now}
+}
+
+// globalSessions 全局会话存储实例
+var globalSessions = NewSessionStore()
+
+// Create 为用户创建新会话
+func (st *SessionStore) Create(username string, ttl time.Duration) Session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.pruneLocked()
+	session := Session{
+		Token:    generateToken(),
+		Username: username,
+		Expires:  st.now().Add(ttl),
+	}
+	st.sessions[session.Token] = session
+	return session
+}
+
+// Get 获取未过期的会话
+func (st *SessionStore) Get(token string) (Session, bool) {
+	if token == "" {
+		return Session{}, false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	session, ok := st.sessions[token]
+	if !ok {
+		return Session{}, false
+	}
+	if st.now().After(session.Expires) {
+		delete(st.sessions, token)
+		return Session{}, false
+	}
+	return session, true
+}
+
+// Delete 删除会话（退出登录）
+func (st *SessionStore) Delete(token string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, token)
+}
+
+// DeleteUser 删除用户的全部会话，返回删除数量
+func (st *SessionStore) DeleteUser(username string) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	count := 0
+	for token, session := range st.sessions {
+		if session.Username == username {
+			delete(st.sessions, token)
+			count++
+		}
+	}
+	return count
+}
+
+// RenameUser 用户名修改后迁移已有会话
+func (st *SessionStore) RenameUser(oldName, newName string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for token, session := range st.sessions {
+		if session.Username == oldName {
+			session.Username = newName
+			st.sessions[token] = session
+		}
+	}
+}
+
+// Clear 清空全部会话
+func (st *SessionStore) Clear() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sessions = make(map[string]Session)
+}
+
+func (st *SessionStore) put(session Session) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sessions[session.Token] = session
+}
+
+func (st *SessionStore) pruneLocked() {
+	now := st.now()
+	for token, session := range st.sessions {
+		if now.After(session.Expires) {
+			delete(st.sessions, token)
+		}
+	}
+}
+
+// IsValidToken 验证会话令牌是否有效
+func IsValidToken(token string) bool {
+	_, ok := globalSessions.Get(token)
+	return ok
+}
+
+type accountContextKey struct{}
+
+// withAccount 将当前登录账号写入请求上下文
+func withAccount(r *http.Request, account config.Account) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), accountContextKey{}, account))
+}
+
+// currentAccount 读取当前登录账号，API 令牌访问时不存在
+func currentAccount(r *http.Request) (config.Account, bool) {
+	account, ok := r.Context().Value(accountContextKey{}).(config.Account)
+	return account, ok
+}
+
+// sessionAccount 根据 Cookie 找到会话对应的账号；账号已被删除时同时清理会话
+func (s *Server) sessionAccount(r *http.Request) (config.Account, bool) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return config.Account{}, false
+	}
+	session, ok := globalSessions.Get(cookie.Value)
+	if !ok {
+		return config.Account{}, false
+	}
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		return config.Account{}, false
+	}
+	account, ok := conf.FindAccount(session.Username)
+	if !ok {
+		globalSessions.Delete(session.Token)
+		return config.Account{}, false
+	}
+	account.Password = ""
+	return account, true
+}
+
+// allowedForRole 只读账号只能访问 GET / HEAD 请求，且不能管理账号和 API 令牌，
+// 也不能查看 Webhook 配置（地址和请求体中通常带有机器人令牌等凭证）；
+// 两步验证只作用于本人账号，所有角色均可设置
+func allowedForRole(account config.Account, r *http.Request) bool {
+	if account.IsAdmin() || strings.HasPrefix(r.URL.Path, "/api/2fa") {
+		return true
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/tokens") || strings.HasPrefix(r.URL.Path, "/api/users") ||
+		r.URL.Path == "/webhook" {
+		return false
+	}
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// forbidden 返回 403，并记录被拒绝的操作
+func forbidden(w http.ResponseWriter, r *http.Request, account config.Account) {
+	helper.Warn(helper.LogTypeAuth, "只读账号 %s 无权执行 %s %s [IP=%s]", account.Username, r.Method, r.URL.Path, helper.GetClientIP(r))
+	helper.ReturnErrorWithStatus(w, http.StatusForbidden, "当前账号为只读角色，无权执行此操作")
+}
@@ -89,17 +89,23 @@ func (s *Server) handleSettingsGet(writer http.ResponseWriter, request *http.Req
 		ddnsCacheTimes = cliDDNS
 	}
 
+	// 展示当前登录账号（主账号或其他账号）
+	account, _ := currentAccount(request)
 	err = tmpl.Execute(writer, struct {
 		config.User
 		config.Settings
+		IsAdmin              bool
+		IsPrimary            bool
 		DCDNCacheTimes       int
 		DDNSCacheTimes       int
 		EveryLocked          bool
 		DCDNCacheTimesLocked bool
 		DDNSCacheTimesLocked bool
 	}{
-		conf.User,
+		config.User{Username: account.Username},
 		settings,
+		account.IsAdmin(),
+		account.Username == conf.Username,
 		dcdnCacheTimes,
 		ddnsCacheTimes,
 		everyLocked,
@@ -118,6 +124,8 @@ func (s *Server) handleSettingsPost(writer http.ResponseWriter, request *http.Re
 		helper.ReturnError(writer, "请求格式错误")
 		return
 	}
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
 	conf, err := s.configRepo.Load()
 	if err != nil {
 		helper.Error(helper.LogTypeConfig, "获取配置失败: %v", err)
@@ -136,8 +144,27 @@ func (s *Server) handleSettingsPost(writer http.ResponseWriter, request *http.Re
 		helper.ReturnError(writer, "DDNS 强制同步次数需在 1 – 1000 之间")
 		return
 	}
+	account, ok := currentAccount(request)
+	if !ok {
+		helper.ReturnErrorWithStatus(writer, http.StatusUnauthorized, "未登录或登录已过期")
+		return
+	}
+	// 主账号可修改用户名，其他账号只能修改自己的密码
+	isPrimary := account.Username == conf.Username
+	if !isPrimary && settingsReq.Username != account.Username {
+		helper.ReturnError(writer, "只有主账号可以修改用户名")
+		return
+	}
+	if isPrimary && settingsReq.Username != conf.Username {
+		if _, exists := conf.FindAccount(settingsReq.Username); exists {
+			helper.ReturnError(writer, "用户名已存在")
+			return
+		}
+	}
 	conf.NotAllowWanAccess = settingsReq.NotAllowWanAccess
-	conf.Username = settingsReq.Username
+	if isPrimary {
+		conf.Username = settingsReq.Username
+	}
 	// CLI 锁定的字段不接受 Web UI 更新，保留用户已有的 config 值
 	// （用户后续移除 CLI 参数重启后，仍能拿回之前的配置）
 	if everyLocked, _ := cliOverride(config.CLIEveryENV); !everyLocked {
@@ -155,7 +182,7 @@ func (s *Server) handleSettingsPost(writer http.ResponseWriter, request *http.Re
 			helper.ReturnError(writer, "密码加密失败")
 			return
 		}
-		conf.Password = hashedPwd
+		conf.SetAccountPassword(settingsReq.Username, hashedPwd)
 	}
 	// 保存配置
 	if err := s.configRepo.Save(&conf); err != nil {
@@ -164,5 +191,8 @@ func (s *Server) handleSettingsPost(writer http.ResponseWriter, request *http.Re
 		return
 	}
 
+	if isPrimary && account.Username != conf.Username {
+		globalSessions.RenameUser(account.Username, conf.Username)
+	}
 	helper.ReturnSuccess(writer, "配置保存成功", nil)
 }
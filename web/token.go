@@ -0,0 +1,173 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+// bearerToken 从 Authorization: Bearer 头中读取 API 令牌
+func bearerToken(r *http.Request) (string, bool) {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(auth[7:])
+	return token, token != ""
+}
+
+// requiredScope 返回访问该接口所需的令牌权限，为空表示不允许令牌访问（仅限登录会话）
+func requiredScope(r *http.Request) string {
+	path := r.URL.Path
+	switch {
+	case !strings.HasPrefix(path, "/api/"),
+		strings.HasPrefix(path, "/api/tokens"),
+		strings.HasPrefix(path, "/api/users"),
+		strings.HasPrefix(path, "/api/2fa"):
+		return ""
+	case path == "/api/v1/sync":
+		return config.ScopeTriggerSync
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return config.ScopeReadOnly
+	default:
+		return config.ScopeManageConfig
+	}
+}
+
+// authAPIToken 校验 API 令牌及其权限，每次使用都记录认证日志
+func (s *Server) authAPIToken(w http.ResponseWriter, r *http.Request, plain string, f ViewFunc) {
+	clientIP := helper.GetClientIP(r)
+	scope := requiredScope(r)
+	if scope == "" {
+		helper.Warn(helper.LogTypeAuth, "API 令牌不能访问 %s %s [IP=%s]", r.Method, r.URL.Path, clientIP)
+		helper.ReturnErrorWithStatus(w, http.StatusForbidden, "该接口不支持 API 令牌访问")
+		return
+	}
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnErrorWithStatus(w, http.StatusInternalServerError, "获取配置失败")
+		return
+	}
+	token, ok := conf.FindAPIToken(plain)
+	if !ok {
+		helper.Warn(helper.LogTypeAuth, "无效的 API 令牌 [%s %s, IP=%s]", r.Method, r.URL.Path, clientIP)
+		helper.ReturnErrorWithStatus(w, http.StatusUnauthorized, "无效的 API 令牌")
+		return
+	}
+	if !token.HasScope(scope) {
+		helper.Warn(helper.LogTypeAuth, "API 令牌 [%s] 缺少 %s 权限 [%s %s, IP=%s]", token.Name, scope, r.Method, r.URL.Path, clientIP)
+		helper.ReturnErrorWithStatus(w, http.StatusForbidden, "API 令牌缺少 "+scope+" 权限")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "API 令牌 [%s] 访问 %s %s [IP=%s]", token.Name, r.Method, r.URL.Path, clientIP)
+	f(w, r)
+}
+
+type createTokenReq struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APITokens 列出或创建 API 令牌（仅限登录会话）
+func (s *Server) APITokens(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		conf, err := s.configRepo.Load()
+		if err != nil {
+			helper.ReturnError(writer, "获取配置失败")
+			return
+		}
+		tokens := conf.APITokens
+		if tokens == nil {
+			tokens = []config.APIToken{}
+		}
+		helper.ReturnSuccess(writer, "", tokens)
+	case http.MethodPost:
+		s.handleTokenCreate(writer, request)
+	default:
+		helper.ReturnError(writer, "不支持的请求方法")
+	}
+}
+
+func (s *Server) handleTokenCreate(writer http.ResponseWriter, request *http.Request) {
+	var req createTokenReq
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		helper.ReturnError(writer, "请求格式错误")
+		return
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnError(writer, "获取配置失败")
+		return
+	}
+	plain, token, err := config.NewAPIToken(req.Name, req.Scopes)
+	if err != nil {
+		helper.ReturnError(writer, err.Error())
+		return
+	}
+	conf.APITokens = append(append([]config.APIToken{}, conf.APITokens...), token)
+	if err := s.configRepo.Save(&conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "保存 API 令牌失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "已创建 API 令牌 [%s, 权限=%s, IP=%s]", token.Name, strings.Join(req.Scopes, ","), helper.GetClientIP(request))
+	// 明文只在创建时返回一次
+	helper.ReturnSuccess(writer, "令牌已创建，请立即复制保存", map[string]interface{}{
+		"token": plain,
+		"info":  token,
+	})
+}
+
+// RevokeAPIToken 吊销 API 令牌（仅限登录会话）
+func (s *Server) RevokeAPIToken(writer http.ResponseWriter, request *http.Request) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnError(writer, "获取配置失败")
+		return
+	}
+	id := request.PathValue("id")
+	if !conf.RevokeAPIToken(id) {
+		helper.ReturnError(writer, "令牌不存在")
+		return
+	}
+	if err := s.configRepo.Save(&conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "吊销 API 令牌失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "已吊销 API 令牌 [ID=%s, IP=%s]", id, helper.GetClientIP(request))
+	helper.ReturnSuccess(writer, "令牌已吊销", nil)
+}
+
+// SyncAPI 立即触发一次同步，kind 可选 ddns / dcdn，为空时两者都触发
+func (s *Server) SyncAPI(writer http.ResponseWriter, request *http.Request) {
+	kind := strings.ToLower(strings.TrimSpace(request.URL.Query().Get("kind")))
+	switch kind {
+	case "ddns":
+		s.syncer.TriggerDDNSSyncAsync()
+	case "dcdn":
+		s.syncer.TriggerDCDNSyncAsync()
+	case "":
+		s.syncer.TriggerDDNSSyncAsync()
+		s.syncer.TriggerDCDNSyncAsync()
+	default:
+		helper.ReturnErrorWithStatus(writer, http.StatusBadRequest, "kind 只能为 ddns 或 dcdn")
+		return
+	}
+	helper.ReturnSuccess(writer, "已触发同步", nil)
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+func doBearerRequest(mux *http.ServeMux, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func newTokenTestServer(t *testing.T, scopes ...string) (*http.ServeMux, *stubRepository, *stubSyncer, string) {
+	t.Helper()
+	plain, token, err := config.NewAPIToken("script", scopes)
+	if err != nil {
+		t.Fatalf("NewAPIToken returned error: %v", err)
+	}
+	mux, repo, syncer := newAPITestServer(t, config.Config{
+		DDNSConfig: config.DDNSConfig{DDNS: []config.DNSGroup{{ID: "1", Domain: "a.example.com", Service: "cloudflare", AccessKey: "token"}}},
+		APITokens:  []config.APIToken{token},
+	})
+	return mux, repo, syncer, plain
+}
+
+func TestAPIToken_ReadOnlyScope(t *testing.T) {
+	mux, _, _, plain := newTokenTestServer(t, config.ScopeReadOnly)
+
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", plain); rec.Code != http.StatusOK {
+		t.Fatalf("expected GET with read-only token to succeed, got %d", rec.Code)
+	}
+	if rec := doBearerRequest(mux, http.MethodPut, "/api/v1/ddns/groups/1", `{"domain":"b.example.com"}`, plain); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected PUT with read-only token to be forbidden, got %d", rec.Code)
+	}
+	if rec := doBearerRequest(mux, http.MethodPost, "/api/v1/sync", "", plain); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected sync with read-only token to be forbidden, got %d", rec.Code)
+	}
+}
+
+func TestAPIToken_InvalidToken(t *testing.T) {
+	mux, _, _, _ := newTokenTestServer(t, config.ScopeReadOnly)
+
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", "dnet_invalid"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected invalid token to be unauthorized, got %d", rec.Code)
+	}
+}
+
+func TestAPIToken_TriggerSync(t *testing.T) {
+	mux, _, syncer, plain := newTokenTestServer(t, config.ScopeTriggerSync)
+
+	if rec := doBearerRequest(mux, http.MethodPost, "/api/v1/sync?kind=ddns", "", plain); rec.Code != http.StatusOK {
+		t.Fatalf("expected sync to succeed, got %d", rec.Code)
+	}
+	if syncer.ddnsTriggered != 1 || syncer.dcdnTriggered != 0 {
+		t.Fatalf("expected only DDNS sync triggered, got %+v", syncer)
+	}
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", plain); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected GET with trigger-sync token to be forbidden, got %d", rec.Code)
+	}
+}
+
+func TestAPIToken_CannotManageTokensOrPages(t *testing.T) {
+	mux, _, _, plain := newTokenTestServer(t)
+
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/tokens", "", plain); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected token listing via bearer to be forbidden, got %d", rec.Code)
+	}
+	if rec := doBearerRequest(mux, http.MethodGet, "/settings", "", plain); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected page access via bearer to be forbidden, got %d", rec.Code)
+	}
+}
+
+func TestAPIToken_CreateAndRevokeWithSession(t *testing.T) {
+	mux, repo, _ := newAPITestServer(t, config.Config{})
+
+	rec := doAPIRequest(mux, http.MethodPost, "/api/tokens", `{"name":"ci","scopes":["read-only"]}`, true)
+	result := decodeResult(t, rec)
+	if !result.Status {
+		t.Fatalf("expected token creation to succeed, got %+v", result)
+	}
+	var created struct {
+		Token string          `json:"token"`
+		Info  config.APIToken `json:"info"`
+	}
+	raw, _ := json.Marshal(result.Data)
+	json.Unmarshal(raw, &created)
+	if len(repo.conf.APITokens) != 1 || created.Token == "" || repo.conf.APITokens[0].Hash == "" {
+		t.Fatalf("expected token persisted with hash, got %+v", repo.conf.APITokens)
+	}
+
+	// 新令牌可立即使用
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", created.Token); rec.Code != http.StatusOK {
+		t.Fatalf("expected new token to work, got %d", rec.Code)
+	}
+
+	rec = doAPIRequest(mux, http.MethodDelete, "/api/tokens/"+created.Info.ID, "", true)
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("expected revoke to succeed, got %+v", result)
+	}
+	if len(repo.conf.APITokens) != 0 {
+		t.Fatalf("expected token removed, got %+v", repo.conf.APITokens)
+	}
+	if rec := doBearerRequest(mux, http.MethodGet, "/api/v1/ddns/groups", "", created.Token); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token to be rejected, got %d", rec.Code)
+	}
+}
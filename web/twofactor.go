@@ -0,0 +1,172 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+type twoFactorReq struct {
+	Secret string `json:"secret"`
+	Code   string `json:"code"`
+}
+
+// loadTwoFactorAccount 读取当前登录账号的最新配置
+func (s *Server) loadTwoFactorAccount(writer http.ResponseWriter, request *http.Request) (config.Config, config.Account, bool) {
+	account, ok := currentAccount(request)
+	if !ok {
+		helper.ReturnErrorWithStatus(writer, http.StatusUnauthorized, "未登录或登录已过期")
+		return config.Config{}, config.Account{}, false
+	}
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnError(writer, "获取配置失败")
+		return config.Config{}, config.Account{}, false
+	}
+	account, ok = conf.FindAccount(account.Username)
+	if !ok {
+		helper.ReturnError(writer, "账号不存在")
+		return config.Config{}, config.Account{}, false
+	}
+	return conf, account, true
+}
+
+// TwoFactorStatus 查询当前账号的两步验证状态
+func (s *Server) TwoFactorStatus(writer http.ResponseWriter, request *http.Request) {
+	_, account, ok := s.loadTwoFactorAccount(writer, request)
+	if !ok {
+		return
+	}
+	helper.ReturnSuccess(writer, "", map[string]interface{}{
+		"enabled":             account.TwoFactor.Enabled(),
+		"recovery_codes_left": len(account.TwoFactor.RecoveryCodes),
+	})
+}
+
+// TwoFactorSetup 生成新的 TOTP 密钥，需调用 enable 并验证一次验证码后才会保存
+func (s *Server) TwoFactorSetup(writer http.ResponseWriter, request *http.Request) {
+	_, account, ok := s.loadTwoFactorAccount(writer, request)
+	if !ok {
+		return
+	}
+	if account.TwoFactor.Enabled() {
+		helper.ReturnError(writer, "两步验证已启用，请先关闭")
+		return
+	}
+	secret, err := config.GenerateTOTPSecret()
+	if err != nil {
+		helper.ReturnError(writer, "生成密钥失败")
+		return
+	}
+	helper.ReturnSuccess(writer, "", map[string]string{
+		"secret": secret,
+		"uri":    config.TOTPURI(account.Username, secret),
+	})
+}
+
+// TwoFactorEnable 验证验证码后启用两步验证，返回一次性恢复码
+func (s *Server) TwoFactorEnable(writer http.ResponseWriter, request *http.Request) {
+	var req twoFactorReq
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		helper.ReturnError(writer, "请求格式错误")
+		return
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, account, ok := s.loadTwoFactorAccount(writer, request)
+	if !ok {
+		return
+	}
+	if account.TwoFactor.Enabled() {
+		helper.ReturnError(writer, "两步验证已启用，请先关闭")
+		return
+	}
+	tf, verified := config.TwoFactor{Secret: strings.TrimSpace(req.Secret)}.VerifyTOTP(req.Code, time.Now())
+	if !verified {
+		helper.ReturnError(writer, "验证码错误，请确认验证器时间准确")
+		return
+	}
+	s.saveTwoFactor(writer, request, &conf, account.Username, tf, "已启用两步验证")
+}
+
+// TwoFactorRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+func (s *Server) TwoFactorRecoveryCodes(writer http.ResponseWriter, request *http.Request) {
+	var req twoFactorReq
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		helper.ReturnError(writer, "请求格式错误")
+		return
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, account, ok := s.loadTwoFactorAccount(writer, request)
+	if !ok {
+		return
+	}
+	if !account.TwoFactor.Enabled() {
+		helper.ReturnError(writer, "验证码错误")
+		return
+	}
+	tf, verified := account.TwoFactor.VerifyTOTP(req.Code, time.Now())
+	if !verified {
+		helper.ReturnError(writer, "验证码错误")
+		return
+	}
+	s.saveTwoFactor(writer, request, &conf, account.Username, tf, "已重新生成恢复码")
+}
+
+// TwoFactorDisable 使用验证码或恢复码关闭两步验证
+func (s *Server) TwoFactorDisable(writer http.ResponseWriter, request *http.Request) {
+	var req twoFactorReq
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		helper.ReturnError(writer, "请求格式错误")
+		return
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, account, ok := s.loadTwoFactorAccount(writer, request)
+	if !ok {
+		return
+	}
+	if _, verified := account.TwoFactor.Verify(req.Code, time.Now()); !verified {
+		helper.ReturnError(writer, "验证码错误")
+		return
+	}
+	conf.SetAccountTwoFactor(account.Username, config.TwoFactor{})
+	if err := s.configRepo.Save(&conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "保存两步验证配置失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "用户 %s 已关闭两步验证 [IP=%s]", account.Username, helper.GetClientIP(request))
+	helper.ReturnSuccess(writer, "已关闭两步验证", nil)
+}
+
+// saveTwoFactor 保存两步验证配置和新生成的恢复码，恢复码明文只返回这一次
+func (s *Server) saveTwoFactor(writer http.ResponseWriter, request *http.Request, conf *config.Config, username string, tf config.TwoFactor, msg string) {
+	plain, hashed, err := config.GenerateRecoveryCodes()
+	if err != nil {
+		helper.ReturnError(writer, "生成恢复码失败")
+		return
+	}
+	tf.RecoveryCodes = hashed
+	conf.SetAccountTwoFactor(username, tf)
+	if err = s.configRepo.Save(conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "保存两步验证配置失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "用户 %s %s [IP=%s]", username, msg, helper.GetClientIP(request))
+	helper.ReturnSuccess(writer, msg, map[string]interface{}{"recovery_codes": plain})
+}
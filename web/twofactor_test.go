@@ -0,0 +1,107 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+func TestTwoFactor_EnableAndLogin(t *testing.T) {
+	conf := config.Config{User: config.User{Username: "admin"}}
+	hashedPassword, err := conf.GeneratePassword("admin-password")
+	if err != nil {
+		t.Fatalf("GeneratePassword() error = %v", err)
+	}
+	conf.Password = hashedPassword
+	mux, repo, _ := newAPITestServer(t, conf)
+
+	// 生成密钥后需验证一次验证码才会保存
+	rec := doAPIRequest(mux, http.MethodPost, "/api/2fa/setup", "", true)
+	setup := decodeResult(t, rec)
+	secret := setup.Data.(map[string]interface{})["secret"].(string)
+	if repo.conf.User.TwoFactor.Enabled() {
+		t.Fatal("expected secret not saved before verification")
+	}
+
+	rec = doAPIRequest(mux, http.MethodPost, "/api/2fa/enable", `{"secret":"`+secret+`","code":"000000x"}`, true)
+	if result := decodeResult(t, rec); result.Status {
+		t.Fatal("expected wrong code to be rejected")
+	}
+	code, _ := config.TOTPCode(secret, time.Now())
+	rec = doAPIRequest(mux, http.MethodPost, "/api/2fa/enable", `{"secret":"`+secret+`","code":"`+code+`"}`, true)
+	enabled := decodeResult(t, rec)
+	if !enabled.Status || repo.conf.User.TwoFactor.Secret != secret {
+		t.Fatalf("expected 2FA enabled, got %+v", enabled)
+	}
+	var data struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	raw, _ := json.Marshal(enabled.Data)
+	json.Unmarshal(raw, &data)
+	if len(data.RecoveryCodes) != config.RecoveryCodeSize {
+		t.Fatalf("expected recovery codes, got %+v", data)
+	}
+
+	server := &Server{configRepo: repo}
+	login := func(body string) (bool, bool) {
+		recorder := httptest.NewRecorder()
+		server.handleLoginPost(recorder, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+		result := decodeResult(t, recorder)
+		needCode := false
+		if m, ok := result.Data.(map[string]interface{}); ok {
+			needCode, _ = m["need_code"].(bool)
+		}
+		return result.Status, needCode
+	}
+
+	if ok, needCode := login(`{"username":"admin","password":"admin-password"}`); ok || !needCode {
+		t.Fatalf("expected login to ask for code, got ok=%v needCode=%v", ok, needCode)
+	}
+	if ok, _ := login(`{"username":"admin","password":"admin-password","code":"123456x"}`); ok {
+		t.Fatal("expected wrong code to be rejected")
+	}
+	// 启用时已用掉当前时间窗口的验证码，登录使用下一个窗口的验证码（在允许误差内）
+	code, _ = config.TOTPCode(secret, time.Now().Add(config.TOTPPeriod))
+	if ok, _ := login(`{"username":"admin","password":"admin-password","code":"` + code + `"}`); !ok {
+		t.Fatal("expected login with TOTP code to succeed")
+	}
+	if ok, _ := login(`{"username":"admin","password":"admin-password","code":"` + code + `"}`); ok {
+		t.Fatal("expected replayed TOTP code to be rejected")
+	}
+
+	// 恢复码只能使用一次
+	body := `{"username":"admin","password":"admin-password","code":"` + data.RecoveryCodes[0] + `"}`
+	if ok, _ := login(body); !ok {
+		t.Fatal("expected login with recovery code to succeed")
+	}
+	if len(repo.conf.User.TwoFactor.RecoveryCodes) != config.RecoveryCodeSize-1 {
+		t.Fatalf("expected recovery code consumed, left %d", len(repo.conf.User.TwoFactor.RecoveryCodes))
+	}
+	if ok, _ := login(body); ok {
+		t.Fatal("expected used recovery code to be rejected")
+	}
+
+	rec = doAPIRequest(mux, http.MethodPost, "/api/2fa/disable", `{"code":"`+data.RecoveryCodes[1]+`"}`, true)
+	if result := decodeResult(t, rec); !result.Status || repo.conf.User.TwoFactor.Enabled() {
+		t.Fatalf("expected 2FA disabled, got %+v", result)
+	}
+}
+
+func TestTwoFactor_ViewerCanManageOwnAccount(t *testing.T) {
+	mux, _ := newRoleTestServer(t)
+
+	if code := doViewerRequest(mux, http.MethodPost, "/api/2fa/setup", ""); code != http.StatusOK {
+		t.Fatalf("expected viewer to set up own 2FA, got %d", code)
+	}
+	if code := doViewerRequest(mux, http.MethodGet, "/settings", ""); code != http.StatusOK {
+		t.Fatalf("expected viewer to open settings page, got %d", code)
+	}
+	if rec := doAPIRequest(mux, http.MethodGet, "/settings", "", true); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "账号管理") {
+		t.Fatalf("expected admin settings page with account management, got %d", rec.Code)
+	}
+}
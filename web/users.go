@@ -0,0 +1,96 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cxbdasheng/dnet/config"
+	"github.com/cxbdasheng/dnet/helper"
+)
+
+type createUserReq struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// Users 列出或新增控制台账号（仅限管理员会话）
+func (s *Server) Users(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		conf, err := s.configRepo.Load()
+		if err != nil {
+			helper.ReturnError(writer, "获取配置失败")
+			return
+		}
+		accounts := []config.Account{}
+		if conf.Username != "" {
+			accounts = append(accounts, config.Account{Username: conf.Username, Role: config.RoleAdmin})
+		}
+		accounts = append(accounts, conf.Accounts...)
+		helper.ReturnSuccess(writer, "", accounts)
+	case http.MethodPost:
+		s.handleUserCreate(writer, request)
+	default:
+		helper.ReturnError(writer, "不支持的请求方法")
+	}
+}
+
+func (s *Server) handleUserCreate(writer http.ResponseWriter, request *http.Request) {
+	var req createUserReq
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		helper.ReturnError(writer, "请求格式错误")
+		return
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnError(writer, "获取配置失败")
+		return
+	}
+	if err = conf.AddAccount(req.Username, req.Password, req.Role); err != nil {
+		helper.ReturnError(writer, err.Error())
+		return
+	}
+	if err = s.configRepo.Save(&conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "保存账号失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	helper.Info(helper.LogTypeAuth, "已新增账号 %s [角色=%s, IP=%s]", req.Username, req.Role, helper.GetClientIP(request))
+	helper.ReturnSuccess(writer, "账号已创建", nil)
+}
+
+// DeleteUser 删除控制台账号并使其会话失效（仅限管理员会话，主账号不能删除）
+func (s *Server) DeleteUser(writer http.ResponseWriter, request *http.Request) {
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	conf, err := s.configRepo.Load()
+	if err != nil {
+		helper.ReturnError(writer, "获取配置失败")
+		return
+	}
+	username := request.PathValue("username")
+	if username == conf.Username {
+		helper.ReturnError(writer, "主账号不能删除")
+		return
+	}
+	if !conf.RemoveAccount(username) {
+		helper.ReturnError(writer, "账号不存在")
+		return
+	}
+	if err = s.configRepo.Save(&conf); err != nil {
+		helper.Error(helper.LogTypeAuth, "删除账号失败: %v", err)
+		helper.ReturnError(writer, "保存配置失败")
+		return
+	}
+
+	globalSessions.DeleteUser(username)
+	helper.Info(helper.LogTypeAuth, "已删除账号 %s [IP=%s]", username, helper.GetClientIP(request))
+	helper.ReturnSuccess(writer, "账号已删除", nil)
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cxbdasheng/dnet/config"
+)
+
+// newRoleTestServer 构造主账号 admin 和只读账号 viewer，viewer 以 Cookie viewer-token 登录
+func newRoleTestServer(t *testing.T) (*http.ServeMux, *stubRepository) {
+	t.Helper()
+	conf := config.Config{User: config.User{Username: "admin"}}
+	if err := conf.AddAccount("viewer", "viewer-password", config.RoleViewer); err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+	mux, repo, _ := newAPITestServer(t, conf)
+	globalSessions.put(Session{Token: "viewer-token", Username: "viewer", Expires: time.Now().Add(time.Hour)})
+	return mux, repo
+}
+
+func doViewerRequest(mux *http.ServeMux, method, path, body string) int {
+	req := newRequestWithCookie(method, path, body, "viewer-token")
+	recorder := serve(mux, req)
+	return recorder.Code
+}
+
+func TestViewerForbiddenOnMutatingHandlers(t *testing.T) {
+	mux, repo := newRoleTestServer(t)
+
+	for _, path := range []string{"/ddns", "/dcdn", "/settings", "/webhook"} {
+		if code := doViewerRequest(mux, http.MethodPost, path, `{}`); code != http.StatusForbidden {
+			t.Errorf("POST %s as viewer: status = %d, want 403", path, code)
+		}
+	}
+	for _, path := range []string{"/api/users", "/webhook"} {
+		if code := doViewerRequest(mux, http.MethodGet, path, ""); code != http.StatusForbidden {
+			t.Errorf("GET %s as viewer: status = %d, want 403", path, code)
+		}
+	}
+	if code := doViewerRequest(mux, http.MethodGet, "/logs", ""); code != http.StatusOK {
+		t.Errorf("GET /logs as viewer: status = %d, want 200", code)
+	}
+	if code := doViewerRequest(mux, http.MethodGet, "/", ""); code != http.StatusOK {
+		t.Errorf("GET / as viewer: status = %d, want 200", code)
+	}
+	if len(repo.conf.Accounts) != 1 || repo.conf.Username != "admin" {
+		t.Fatalf("expected config untouched, got %+v", repo.conf)
+	}
+}
+
+func TestUsersAPI_CreateAndDelete(t *testing.T) {
+	mux, repo := newRoleTestServer(t)
+
+	rec := doAPIRequest(mux, http.MethodPost, "/api/users", `{"username":"ops","password":"ops-password","role":"admin"}`, true)
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("expected account creation to succeed, got %+v", result)
+	}
+	if account, ok := repo.conf.FindAccount("ops"); !ok || !account.IsAdmin() || account.Password == "ops-password" {
+		t.Fatalf("expected hashed admin account, got %+v", account)
+	}
+
+	rec = doAPIRequest(mux, http.MethodPost, "/api/users", `{"username":"admin","password":"whatever","role":"viewer"}`, true)
+	if result := decodeResult(t, rec); result.Status {
+		t.Fatal("expected duplicate username to be rejected")
+	}
+	rec = doAPIRequest(mux, http.MethodDelete, "/api/users/admin", "", true)
+	if result := decodeResult(t, rec); result.Status {
+		t.Fatal("expected primary account deletion to be rejected")
+	}
+
+	// 删除账号后其会话立即失效
+	rec = doAPIRequest(mux, http.MethodDelete, "/api/users/viewer", "", true)
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("expected account deletion to succeed, got %+v", result)
+	}
+	if IsValidToken("viewer-token") {
+		t.Fatal("expected deleted account session to be invalidated")
+	}
+	if code := doViewerRequest(mux, http.MethodGet, "/api/history", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected deleted account to be unauthorized, got %d", code)
+	}
+}
+
+func TestSettingsPost_SecondaryAdminChangesOwnPassword(t *testing.T) {
+	mux, repo := newRoleTestServer(t)
+	conf := repo.conf
+	if err := conf.AddAccount("ops", "ops-password", config.RoleAdmin); err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+	repo.conf = conf
+	globalSessions.put(Session{Token: "ops-token", Username: "ops", Expires: time.Now().Add(time.Hour)})
+
+	rec := serve(mux, newRequestWithCookie(http.MethodPost, "/settings", `{"username":"renamed","password":"new-password"}`, "ops-token"))
+	if result := decodeResult(t, rec); result.Status {
+		t.Fatal("expected secondary admin rename to be rejected")
+	}
+
+	rec = serve(mux, newRequestWithCookie(http.MethodPost, "/settings", `{"username":"ops","password":"new-password"}`, "ops-token"))
+	if result := decodeResult(t, rec); !result.Status {
+		t.Fatalf("expected password change to succeed, got %+v", result)
+	}
+	if _, ok := repo.conf.Authenticate("ops", "new-password"); !ok {
+		t.Fatal("expected ops to authenticate with new password")
+	}
+	if repo.conf.Username != "admin" {
+		t.Fatalf("expected primary account untouched, got %q", repo.conf.Username)
+	}
+}
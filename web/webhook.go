@@ -57,6 +57,8 @@ func (s *Server) handleWebhookPost(writer http.ResponseWriter, request *http.Req
 		helper.ReturnError(writer, "请求格式错误")
 		return
 	}
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
 	conf, err := s.configRepo.Load()
 	if err != nil {
 		helper.Error(helper.LogTypeWebhook, "获取配置失败: %v", err)